@@ -14,29 +14,46 @@ import (
 
 // agent defaults.
 const (
-	defaultAgentConfigPath             = "agent.yml"
-	defaultAgentExporter               = "timescale"
-	defaultAgentInterval               = 2 * time.Minute
-	defaultAgentPort            uint16 = 9009
-	defaultAgentStandaloneMode         = false
-	defaultAgentMetricsHost            = "127.0.0.1"
-	defaultAgentMetricsPort     uint16 = 0
-	defaultAgentMetricsUsername        = ""
-	defaultAgentMetricsPassword        = ""
-	defaultAgentMetricsOrgName         = ""
-	defaultAgentMetricsDBName          = ""
-	defaultAgentMetricsSSLMode         = true
-	defaultAgentPageDeploy             = false
-	defaultAgentPagePort        uint16 = 9010
-	defaultAgentPageName               = "Status Page"
-	defaultAgentPageMedia              = ""
-	defaultAgentPageLogo               = ""
-	defaultAgentPageFavicon            = ""
-	defaultAgentPageWebsite            = "/"
+	defaultAgentConfigPath              = "agent.yml"
+	defaultAgentExporter                = "timescale"
+	defaultAgentInterval                = 2 * time.Minute
+	defaultAgentPort             uint16 = 9009
+	defaultAgentStandaloneMode          = false
+	defaultAgentMetricsHost             = "127.0.0.1"
+	defaultAgentMetricsPort      uint16 = 0
+	defaultAgentMetricsUsername         = ""
+	defaultAgentMetricsPassword         = ""
+	defaultAgentMetricsOrgName          = ""
+	defaultAgentMetricsDBName           = ""
+	defaultAgentMetricsSSLMode          = true
+	defaultAgentPageDeploy              = false
+	defaultAgentPagePort         uint16 = 9010
+	defaultAgentPageName                = "Status Page"
+	defaultAgentPageMedia               = ""
+	defaultAgentPageLogo                = ""
+	defaultAgentPageFavicon             = ""
+	defaultAgentPageWebsite             = "/"
+	defaultAgentPageSLAPrecision        = 3
+	defaultAgentPageSLAMissing          = "ignore"
+	defaultAgentStateBackend            = "file"
+	defaultAgentStatePath               = "pinger-state.json"
+	defaultAgentStatsCapacity           = 1024
+	defaultAgentStatsOverflow           = "summarize"
+	defaultAgentBufferPath              = "pinger-buffer"
+	defaultAgentBufferMaxBackoff        = time.Minute
+	defaultAgentBufferMaxSize    int64  = 256 << 20 // 256 MiB
+	defaultAgentBufferMaxAge            = 24 * time.Hour
+	defaultAgentLocation                = ""
+	defaultAgentConsensusQuorum         = 1
+	defaultAgentConsensusWindow         = 5 * time.Minute
 )
 
 // non-const agent defaults.
-var defaultAgentPageAllowedOrigins []string = nil
+var (
+	defaultAgentPageAllowedOrigins []string = nil
+	defaultAgentPageSLAWindows     []string = nil
+	defaultAgentConsensusLocations []string = nil
+)
 
 // config keys and flags for agent.
 const (
@@ -78,6 +95,36 @@ const (
 	flagAgentConfigPageFavicon        = "page-favicon"
 	keyAgentConfigPageWebsite         = "page.website"
 	flagAgentConfigPageWebsite        = "page-website"
+	keyAgentConfigPageSLAWindows      = "page.sla.windows"
+	flagAgentConfigPageSLAWindows     = "page-sla-windows"
+	keyAgentConfigPageSLAPrecision    = "page.sla.precision"
+	flagAgentConfigPageSLAPrecision   = "page-sla-precision"
+	keyAgentConfigPageSLAMissing      = "page.sla.missing"
+	flagAgentConfigPageSLAMissing     = "page-sla-missing"
+	keyAgentConfigStateBackend        = "state.backend"
+	flagAgentConfigStateBackend       = "state-backend"
+	keyAgentConfigStatePath           = "state.path"
+	flagAgentConfigStatePath          = "state-path"
+	keyAgentConfigStatsCapacity       = "stats.capacity"
+	flagAgentConfigStatsCapacity      = "stats-capacity"
+	keyAgentConfigStatsOverflow       = "stats.overflow"
+	flagAgentConfigStatsOverflow      = "stats-overflow"
+	keyAgentConfigBufferPath          = "buffer.path"
+	flagAgentConfigBufferPath         = "buffer-path"
+	keyAgentConfigBufferMaxBackoff    = "buffer.max_backoff"
+	flagAgentConfigBufferMaxBackoff   = "buffer-max-backoff"
+	keyAgentConfigBufferMaxSize       = "buffer.max_size"
+	flagAgentConfigBufferMaxSize      = "buffer-max-size"
+	keyAgentConfigBufferMaxAge        = "buffer.max_age"
+	flagAgentConfigBufferMaxAge       = "buffer-max-age"
+	keyAgentConfigLocation            = "location"
+	flagAgentConfigLocation           = "location"
+	keyAgentConfigConsensusQuorum     = "consensus.quorum"
+	flagAgentConfigConsensusQuorum    = "consensus-quorum"
+	keyAgentConfigConsensusWindow     = "consensus.window"
+	flagAgentConfigConsensusWindow    = "consensus-window"
+	keyAgentConfigConsensusLocations  = "consensus.locations"
+	flagAgentConfigConsensusLocations = "consensus-locations"
 )
 
 func newAgentCmd(ctx *appcontext.Context, v *viper.Viper) (*cobra.Command, error) {
@@ -141,6 +188,32 @@ to run in a standalone mode where it does not run any GRPC server.`,
 	cmd.Flags().String(flagAgentConfigPageLogo, defaultAgentPageLogo, "filename for logo in media directory")
 	cmd.Flags().String(flagAgentConfigPageFavicon, defaultAgentPageFavicon, "filename for favicon in media directory")
 	cmd.Flags().String(flagAgentConfigPageWebsite, defaultAgentPageWebsite, "website url for the page")
+	cmd.Flags().StringSlice(
+		flagAgentConfigPageSLAWindows, defaultAgentPageSLAWindows, "windows to compute the SLA of checks over")
+	cmd.Flags().Int(
+		flagAgentConfigPageSLAPrecision, defaultAgentPageSLAPrecision, "decimal places of the uptime and SLA")
+	cmd.Flags().String(
+		flagAgentConfigPageSLAMissing, defaultAgentPageSLAMissing, "time without metrics counts as: ignore, up or down")
+	cmd.Flags().String(flagAgentConfigStateBackend, defaultAgentStateBackend, "backend to persist alert state")
+	cmd.Flags().String(flagAgentConfigStatePath, defaultAgentStatePath, "file path for file based state backend")
+	cmd.Flags().Int(flagAgentConfigStatsCapacity, defaultAgentStatsCapacity, "maximum stats kept per check until export")
+	cmd.Flags().String(
+		flagAgentConfigStatsOverflow, defaultAgentStatsOverflow, "policy when stats overflow: drop-oldest or summarize")
+	cmd.Flags().String(
+		flagAgentConfigBufferPath, defaultAgentBufferPath, "directory to buffer metrics until exported, empty for memory")
+	cmd.Flags().Duration(
+		flagAgentConfigBufferMaxBackoff, defaultAgentBufferMaxBackoff, "maximum time to wait before retrying export")
+	cmd.Flags().Int64(
+		flagAgentConfigBufferMaxSize, defaultAgentBufferMaxSize, "maximum bytes buffered, oldest metrics are dropped")
+	cmd.Flags().Duration(
+		flagAgentConfigBufferMaxAge, defaultAgentBufferMaxAge, "age after which metrics yet to be exported are dropped")
+	cmd.Flags().String(flagAgentConfigLocation, defaultAgentLocation, "location of the agent the checks run from")
+	cmd.Flags().Int(
+		flagAgentConfigConsensusQuorum, defaultAgentConsensusQuorum, "locations that should agree for a check to be down")
+	cmd.Flags().Duration(
+		flagAgentConfigConsensusWindow, defaultAgentConsensusWindow, "age after which metrics of a location do not vote")
+	cmd.Flags().StringSlice(
+		flagAgentConfigConsensusLocations, defaultAgentConsensusLocations, "locations that vote, in the order they lead")
 
 	mapKeysToFlags := map[string]string{
 		keyAgentConfigPort:               flagAgentConfigPort,
@@ -162,6 +235,21 @@ to run in a standalone mode where it does not run any GRPC server.`,
 		keyAgentConfigPageLogo:           flagAgentConfigPageLogo,
 		keyAgentConfigPageFavicon:        flagAgentConfigPageFavicon,
 		keyAgentConfigPageWebsite:        flagAgentConfigPageWebsite,
+		keyAgentConfigPageSLAWindows:     flagAgentConfigPageSLAWindows,
+		keyAgentConfigPageSLAPrecision:   flagAgentConfigPageSLAPrecision,
+		keyAgentConfigPageSLAMissing:     flagAgentConfigPageSLAMissing,
+		keyAgentConfigStateBackend:       flagAgentConfigStateBackend,
+		keyAgentConfigStatePath:          flagAgentConfigStatePath,
+		keyAgentConfigStatsCapacity:      flagAgentConfigStatsCapacity,
+		keyAgentConfigStatsOverflow:      flagAgentConfigStatsOverflow,
+		keyAgentConfigBufferPath:         flagAgentConfigBufferPath,
+		keyAgentConfigBufferMaxBackoff:   flagAgentConfigBufferMaxBackoff,
+		keyAgentConfigBufferMaxSize:      flagAgentConfigBufferMaxSize,
+		keyAgentConfigBufferMaxAge:       flagAgentConfigBufferMaxAge,
+		keyAgentConfigLocation:           flagAgentConfigLocation,
+		keyAgentConfigConsensusQuorum:    flagAgentConfigConsensusQuorum,
+		keyAgentConfigConsensusWindow:    flagAgentConfigConsensusWindow,
+		keyAgentConfigConsensusLocations: flagAgentConfigConsensusLocations,
 	}
 
 	if err := bindFlagsToViper(v, cmd, mapKeysToFlags); err != nil {
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sdslabs/pinger/pkg/components/app"
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// app defaults.
+const (
+	defaultAppConfigPath        = "app.yml"
+	defaultAppPort       uint16 = 9000
+	defaultAppSecret            = ""
+	defaultAppDBHost            = "127.0.0.1"
+	defaultAppDBPort     uint16 = 5432
+	defaultAppDBName            = "pinger"
+	defaultAppDBUsername        = "postgres"
+	defaultAppDBPassword        = ""
+	defaultAppDBSSLMode         = true
+)
+
+// non-const app defaults.
+var defaultAppAllowedOrigins []string = nil
+
+// config keys and flags for app.
+const (
+	keyAppConfigPort            = "port"
+	flagAppConfigPort           = "port"
+	keyAppConfigSecret          = "secret"
+	flagAppConfigSecret         = "secret"
+	keyAppConfigAllowedOrigins  = "allowed_origins"
+	flagAppConfigAllowedOrigins = "allowed-origins"
+	keyAppConfigDBHost          = "database.host"
+	flagAppConfigDBHost         = "db-host"
+	keyAppConfigDBPort          = "database.port"
+	flagAppConfigDBPort         = "db-port"
+	keyAppConfigDBName          = "database.name"
+	flagAppConfigDBName         = "db-name"
+	keyAppConfigDBUsername      = "database.username"
+	flagAppConfigDBUsername     = "db-username"
+	keyAppConfigDBPassword      = "database.password"
+	flagAppConfigDBPassword     = "db-password"
+	keyAppConfigDBSSLMode       = "database.sslmode"
+	flagAppConfigDBSSLMode      = "db-ssl-mode"
+)
+
+func newAppCmd(ctx *appcontext.Context, _ *viper.Viper) (*cobra.Command, error) {
+	// keys like "port" are also used by the agent, so the app server has a
+	// viper of its own for the flags of one not to override the other.
+	v := viper.New()
+
+	conf := configfile.App{}
+	var confPath string
+
+	cmd := &cobra.Command{
+		Use:   "app",
+		Short: "Run pinger app server.",
+		Long: `
+Run pinger app server which exposes the REST API for the users to manage
+their checks and status pages. Users log in through the OAuth providers
+in the config.`,
+		PreRun: func(*cobra.Command, []string) {
+			if err := initConfig(ctx, v, confPath, defaultAppConfigPath, &conf); err != nil {
+				// OAuth providers can only be configured through the config file.
+				ctx.Logger().
+					WithError(err).
+					Fatalln("invalid config")
+				return
+			}
+		},
+		Run: func(*cobra.Command, []string) {
+			if err := app.Run(ctx, &conf); err != nil {
+				ctx.Logger().
+					WithError(err).
+					Fatalln("cannot run app server")
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&confPath, "config", "c", defaultAppConfigPath, "config file path for app server")
+
+	cmd.Flags().Uint16P(flagAppConfigPort, "p", defaultAppPort, "port to run app server on")
+	cmd.Flags().String(flagAppConfigSecret, defaultAppSecret, "secret for signing tokens")
+	cmd.Flags().StringSlice(
+		flagAppConfigAllowedOrigins, defaultAppAllowedOrigins, "allowed origins which can request app server")
+	cmd.Flags().String(flagAppConfigDBHost, defaultAppDBHost, "host of the database")
+	cmd.Flags().Uint16(flagAppConfigDBPort, defaultAppDBPort, "port of the database")
+	cmd.Flags().String(flagAppConfigDBName, defaultAppDBName, "name of the database")
+	cmd.Flags().String(flagAppConfigDBUsername, defaultAppDBUsername, "username credential for database")
+	cmd.Flags().String(flagAppConfigDBPassword, defaultAppDBPassword, "password credential for database")
+	cmd.Flags().Bool(flagAppConfigDBSSLMode, defaultAppDBSSLMode, "whether to connect to database with SSL")
+
+	mapKeysToFlags := map[string]string{
+		keyAppConfigPort:           flagAppConfigPort,
+		keyAppConfigSecret:         flagAppConfigSecret,
+		keyAppConfigAllowedOrigins: flagAppConfigAllowedOrigins,
+		keyAppConfigDBHost:         flagAppConfigDBHost,
+		keyAppConfigDBPort:         flagAppConfigDBPort,
+		keyAppConfigDBName:         flagAppConfigDBName,
+		keyAppConfigDBUsername:     flagAppConfigDBUsername,
+		keyAppConfigDBPassword:     flagAppConfigDBPassword,
+		keyAppConfigDBSSLMode:      flagAppConfigDBSSLMode,
+	}
+
+	if err := bindFlagsToViper(v, cmd, mapKeysToFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
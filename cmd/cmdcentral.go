@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sdslabs/pinger/pkg/components/central"
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// central defaults.
+const (
+	defaultCentralConfigPath            = "central.yml"
+	defaultCentralPort           uint16 = 9012
+	defaultCentralInterval              = 30 * time.Second
+	defaultCentralTimeout               = 10 * time.Second
+	defaultCentralUnhealthyAfter        = 3
+	defaultCentralLocations             = 1
+	defaultCentralDBHost                = "127.0.0.1"
+	defaultCentralDBPort         uint16 = 5432
+	defaultCentralDBName                = "pinger"
+	defaultCentralDBUsername            = "postgres"
+	defaultCentralDBPassword            = ""
+	defaultCentralDBSSLMode             = true
+)
+
+// config keys and flags for central.
+const (
+	keyCentralConfigPort            = "port"
+	flagCentralConfigPort           = "port"
+	keyCentralConfigInterval        = "interval"
+	flagCentralConfigInterval       = "interval"
+	keyCentralConfigTimeout         = "timeout"
+	flagCentralConfigTimeout        = "timeout"
+	keyCentralConfigUnhealthyAfter  = "unhealthy_after"
+	flagCentralConfigUnhealthyAfter = "unhealthy-after"
+	keyCentralConfigLocations       = "locations"
+	flagCentralConfigLocations      = "locations"
+	keyCentralConfigDBHost          = "database.host"
+	flagCentralConfigDBHost         = "db-host"
+	keyCentralConfigDBPort          = "database.port"
+	flagCentralConfigDBPort         = "db-port"
+	keyCentralConfigDBName          = "database.name"
+	flagCentralConfigDBName         = "db-name"
+	keyCentralConfigDBUsername      = "database.username"
+	flagCentralConfigDBUsername     = "db-username"
+	keyCentralConfigDBPassword      = "database.password"
+	flagCentralConfigDBPassword     = "db-password"
+	keyCentralConfigDBSSLMode       = "database.sslmode"
+	flagCentralConfigDBSSLMode      = "db-ssl-mode"
+)
+
+func newCentralCmd(ctx *appcontext.Context, _ *viper.Viper) (*cobra.Command, error) {
+	// keys like "port" are also used by the agent, so the central server has
+	// a viper of its own for the flags of one not to override the other.
+	v := viper.New()
+
+	conf := configfile.Central{}
+	var confPath string
+
+	cmd := &cobra.Command{
+		Use:   "central",
+		Short: "Run pinger central server.",
+		Long: `
+Run pinger central server which schedules the checks in the database on the
+agents registered with it. The central server keeps track of the health of
+the agents and moves the checks of an agent that goes down to the others.`,
+		PreRun: func(*cobra.Command, []string) {
+			if err := initConfig(ctx, v, confPath, defaultCentralConfigPath, &conf); err != nil {
+				// agents can only be registered through the config file.
+				ctx.Logger().
+					WithError(err).
+					Fatalln("invalid config")
+				return
+			}
+		},
+		Run: func(*cobra.Command, []string) {
+			if err := central.Run(ctx, &conf); err != nil {
+				ctx.Logger().
+					WithError(err).
+					Fatalln("cannot run central server")
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&confPath, "config", "c", defaultCentralConfigPath, "config file path for central server")
+
+	cmd.Flags().Uint16P(flagCentralConfigPort, "p", defaultCentralPort, "port to serve status of agents on")
+	cmd.Flags().Duration(
+		flagCentralConfigInterval, defaultCentralInterval, "interval after which checks on agents are reconciled")
+	cmd.Flags().Duration(flagCentralConfigTimeout, defaultCentralTimeout, "timeout of each request to an agent")
+	cmd.Flags().Int(
+		flagCentralConfigUnhealthyAfter, defaultCentralUnhealthyAfter, "failed requests after which agent is unhealthy")
+	cmd.Flags().Int(flagCentralConfigLocations, defaultCentralLocations, "distinct locations each check runs from")
+	cmd.Flags().String(flagCentralConfigDBHost, defaultCentralDBHost, "host of the database")
+	cmd.Flags().Uint16(flagCentralConfigDBPort, defaultCentralDBPort, "port of the database")
+	cmd.Flags().String(flagCentralConfigDBName, defaultCentralDBName, "name of the database")
+	cmd.Flags().String(flagCentralConfigDBUsername, defaultCentralDBUsername, "username credential for database")
+	cmd.Flags().String(flagCentralConfigDBPassword, defaultCentralDBPassword, "password credential for database")
+	cmd.Flags().Bool(flagCentralConfigDBSSLMode, defaultCentralDBSSLMode, "whether to connect to database with SSL")
+
+	mapKeysToFlags := map[string]string{
+		keyCentralConfigPort:           flagCentralConfigPort,
+		keyCentralConfigInterval:       flagCentralConfigInterval,
+		keyCentralConfigTimeout:        flagCentralConfigTimeout,
+		keyCentralConfigUnhealthyAfter: flagCentralConfigUnhealthyAfter,
+		keyCentralConfigLocations:      flagCentralConfigLocations,
+		keyCentralConfigDBHost:         flagCentralConfigDBHost,
+		keyCentralConfigDBPort:         flagCentralConfigDBPort,
+		keyCentralConfigDBName:         flagCentralConfigDBName,
+		keyCentralConfigDBUsername:     flagCentralConfigDBUsername,
+		keyCentralConfigDBPassword:     flagCentralConfigDBPassword,
+		keyCentralConfigDBSSLMode:      flagCentralConfigDBSSLMode,
+	}
+
+	if err := bindFlagsToViper(v, cmd, mapKeysToFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
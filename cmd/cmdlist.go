@@ -8,6 +8,7 @@ import (
 	"github.com/sdslabs/pinger/pkg/checker"
 	"github.com/sdslabs/pinger/pkg/exporter"
 	"github.com/sdslabs/pinger/pkg/oauther"
+	"github.com/sdslabs/pinger/pkg/statestore"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,6 +22,7 @@ const (
 	keyPluginChckers   = "checkers"
 	keyPluginExporters = "exporters"
 	keyPluginOauthers  = "oauthers"
+	keyPluginStores    = "statestores"
 )
 
 func newListCommand(ctx *appcontext.Context, v *viper.Viper) (*cobra.Command, error) {
@@ -29,6 +31,7 @@ func newListCommand(ctx *appcontext.Context, v *viper.Viper) (*cobra.Command, er
 		checkers  = checker.List()
 		exporters = exporter.List()
 		oauthers  = oauther.List()
+		stores    = statestore.List()
 	)
 
 	printPlugins := func(plugin string, list []string) {
@@ -47,6 +50,7 @@ func newListCommand(ctx *appcontext.Context, v *viper.Viper) (*cobra.Command, er
 			printPlugins(keyPluginChckers, checkers)
 			printPlugins(keyPluginExporters, exporters)
 			printPlugins(keyPluginOauthers, oauthers)
+			printPlugins(keyPluginStores, stores)
 		},
 	}
 
@@ -56,6 +60,7 @@ func newListCommand(ctx *appcontext.Context, v *viper.Viper) (*cobra.Command, er
 		newListSubCommandCreator(keyPluginChckers, checkers),
 		newListSubCommandCreator(keyPluginExporters, exporters),
 		newListSubCommandCreator(keyPluginOauthers, oauthers),
+		newListSubCommandCreator(keyPluginStores, stores),
 	); err != nil {
 		return nil, err
 	}
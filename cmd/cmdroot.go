@@ -31,6 +31,8 @@ of the application.`,
 	if err := addCommands(ctx, v, cmd,
 		// Add commands here
 		newAgentCmd,
+		newCentralCmd,
+		newAppCmd,
 		newVersionCmd,
 		newListCommand,
 	); err != nil {
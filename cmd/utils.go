@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -31,7 +33,14 @@ func initConfig(_ *appcontext.Context, v *viper.Viper, confPath, defaultPath str
 		return fmt.Errorf("%w: %v", errReadConfig, err)
 	}
 
-	if err := v.Unmarshal(resolveTo); err != nil {
+	// times, like the start of a maintenance, are written in RFC 3339.
+	hook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	))
+
+	if err := v.Unmarshal(resolveTo, hook); err != nil {
 		return fmt.Errorf("%w: %v", errUnmarshalConfig, err)
 	}
 
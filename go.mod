@@ -10,6 +10,8 @@ require (
 	github.com/gorilla/websocket v1.4.1
 	github.com/influxdata/influxdb-client-go/v2 v2.2.1
 	github.com/jackc/pgx/v4 v4.8.1
+	github.com/mattn/go-sqlite3 v1.14.6
+	github.com/mitchellh/mapstructure v1.1.2
 	github.com/pelletier/go-toml v1.6.0 // indirect
 	github.com/sdslabs/kiwi v1.0.0
 	github.com/sirupsen/logrus v1.4.2
@@ -29,5 +31,6 @@ require (
 	gopkg.in/ini.v1 v1.52.0 // indirect
 	gopkg.in/mail.v2 v2.3.1
 	gorm.io/driver/postgres v1.0.0
+	gorm.io/driver/sqlite v1.1.3
 	gorm.io/gorm v1.20.1
 )
@@ -70,3 +70,30 @@ func Initialize(ctx *appcontext.Context, provider Provider) (AlertFunc, error) {
 
 	return alerter.Alert, nil
 }
+
+// Message creates the text of the alert for the metric. For a failed check
+// it includes the reason of failure, if any.
+func Message(metric checker.Metric) string {
+	if metric.IsDegraded() {
+		return fmt.Sprintf("%s is degraded: took %s", metric.GetCheckName(), metric.GetDuration())
+	}
+
+	if metric.IsSuccessful() {
+		return fmt.Sprintf("%s is back up", metric.GetCheckName())
+	}
+
+	if metric.IsTimeout() {
+		return fmt.Sprintf("%s: timeout", metric.GetCheckName())
+	}
+
+	msg := fmt.Sprintf("%s is down", metric.GetCheckName())
+	if metric.GetFailureCode() != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, metric.GetFailureCode())
+	}
+
+	if metric.GetFailureMessage() != "" {
+		msg = fmt.Sprintf("%s: %s", msg, metric.GetFailureMessage())
+	}
+
+	return msg
+}
@@ -76,15 +76,7 @@ func (a *Alerter) alert(ctx context.Context, metric checker.Metric, alt alerter.
 		defer cancel()
 	}
 
-	var msg string
-	if metric.IsSuccessful() {
-		msg = fmt.Sprintf("%s is back up", metric.GetCheckName())
-	} else {
-		msg = fmt.Sprintf("%s is down", metric.GetCheckName())
-		if metric.IsTimeout() {
-			msg = fmt.Sprintf("%s: timeout", metric.GetCheckName())
-		}
-	}
+	msg := alerter.Message(metric)
 
 	body, err := json.Marshal(reqBody{Text: msg})
 	if err != nil {
@@ -52,6 +52,93 @@ type Result struct {
 	Timeout    bool
 	StartTime  time.Time
 	Duration   time.Duration
+
+	// Degraded tells if the check was successful but took longer than the
+	// latency threshold of the check.
+	Degraded bool
+
+	// FailureCode is the reason why the check failed. It is one of the
+	// `Failure*` constants and is empty for a successful check.
+	FailureCode string
+
+	// FailureMessage describes the failure in detail.
+	FailureMessage string
+
+	// Measurements are the additional values recorded by the checker during
+	// the probe, for example, the status code of an HTTP response. Values
+	// are either numbers, strings or booleans.
+	Measurements map[string]interface{}
+}
+
+// Keys of the measurements recorded by the checkers.
+const (
+	MeasurementStatusCode       = "status_code"
+	MeasurementBytesSent        = "bytes_sent"
+	MeasurementBytesReceived    = "bytes_received"
+	MeasurementMessagesReceived = "messages_received"
+	MeasurementTTL              = "ttl"
+	MeasurementResolvedIPs      = "resolved_ips"
+	MeasurementNumResolved      = "num_resolved"
+	MeasurementTLSVersion       = "tls_version"
+	MeasurementCertExpiresIn    = "cert_expires_in_seconds"
+
+	// MeasurementAttempts is the number of attempts made for the check when
+	// retries are configured.
+	MeasurementAttempts = "attempts"
+
+	// MeasurementMaintenance is the name of the maintenance the check was
+	// under during the run, if any.
+	MeasurementMaintenance = "maintenance"
+)
+
+// Various failure codes that tell why a check failed.
+const (
+	// FailureTimeout is when the check does not complete within the timeout.
+	FailureTimeout = "TIMEOUT"
+
+	// FailureDNS is when the host of the target cannot be resolved.
+	FailureDNS = "DNS"
+
+	// FailureConnectionRefused is when the target refuses the connection.
+	FailureConnectionRefused = "CONNECTION_REFUSED"
+
+	// FailureConnection is when the connection with the target cannot be
+	// established or breaks midway.
+	FailureConnection = "CONNECTION"
+
+	// FailureTLS is when the TLS handshake or certificate verification fails.
+	FailureTLS = "TLS"
+
+	// FailureAssertion is when the check completes but the outputs do not
+	// match the expected outputs.
+	FailureAssertion = "ASSERTION"
+
+	// FailureError is when the check could not be executed due to any other
+	// error.
+	FailureError = "ERROR"
+)
+
+// setFailure sets the failure code and message for an unsuccessful result
+// if the checker has not already set them.
+func (r *Result) setFailure() {
+	if r.Successful {
+		r.FailureCode = ""
+		r.FailureMessage = ""
+		return
+	}
+
+	if r.FailureCode != "" {
+		return
+	}
+
+	if r.Timeout {
+		r.FailureCode = FailureTimeout
+		r.FailureMessage = fmt.Sprintf("timed out after %s", r.Duration)
+		return
+	}
+
+	r.FailureCode = FailureAssertion
+	r.FailureMessage = "outputs did not match"
 }
 
 // Checker is something that probes the provided target and marks it's run
@@ -90,19 +177,96 @@ func newControllerFunc(check Check) (controller.RunnerFunc, error) {
 		return nil, err
 	}
 
-	if err := checker.Validate(check); err != nil {
+	if err := validateRetries(check); err != nil {
+		return nil, fmt.Errorf("%s checker: %w: %v", name, ErrValidation, err)
+	}
+
+	lc, err := newLatencyCheck(check)
+	if err != nil {
+		return nil, fmt.Errorf("%s checker: %w: %v", name, ErrValidation, err)
+	}
+
+	if err := checker.Validate(lc); err != nil {
 		return nil, fmt.Errorf("%s checker: %w: %v", name, ErrValidation, err)
 	}
 
-	if err := checker.Provision(check); err != nil {
+	if err := checker.Provision(lc); err != nil {
 		return nil, fmt.Errorf("%s checker: %w: %v", name, ErrProvisioning, err)
 	}
 
+	execute := func(ctx context.Context) (*Result, error) {
+		startTime := time.Now()
+
+		res, err := checker.Execute(ctx)
+		if err != nil {
+			// The result is still returned along with the error so that the
+			// failure is recorded with the reason and the time it occurred.
+			return &Result{
+				Timeout:        ErrIsTimeout(err),
+				StartTime:      startTime,
+				Duration:       time.Since(startTime),
+				FailureCode:    FailureCodeFromError(err),
+				FailureMessage: err.Error(),
+			}, err
+		}
+
+		res.setFailure()
+		lc.setDegraded(res)
+		return res, nil
+	}
+
+	retries := check.GetRetries()
+	retryInterval := check.GetRetryInterval()
+
 	return func(ctx context.Context) (interface{}, error) {
-		return checker.Execute(ctx)
+		res, err := execute(ctx)
+
+		attempts := 1
+		for ; attempts <= retries && (err != nil || !res.Successful); attempts++ {
+			select {
+			case <-ctx.Done():
+				return res, err
+			case <-time.After(retryInterval):
+			}
+
+			res, err = execute(ctx)
+		}
+
+		if retries > 0 {
+			if res.Measurements == nil {
+				res.Measurements = map[string]interface{}{}
+			}
+			res.Measurements[MeasurementAttempts] = attempts
+		}
+
+		return res, err
 	}, nil
 }
 
+// validateRetries validates the retry configuration of the check.
+func validateRetries(check Check) error {
+	if check.GetRetries() < 0 {
+		return fmt.Errorf("retries should be >= 0")
+	}
+
+	if check.GetRetryInterval() < 0 {
+		return fmt.Errorf("retry interval should be >= 0")
+	}
+
+	// Each attempt can take as long as the timeout, so all the attempts of a
+	// run should finish before the next run starts.
+	retries := time.Duration(check.GetRetries())
+	worst := (retries+1)*check.GetTimeout() + retries*check.GetRetryInterval()
+	if interval := check.GetInterval(); check.GetRetries() > 0 && interval > 0 && worst > interval {
+		return fmt.Errorf(
+			"retries should finish within the interval: %d retries can take %v which is more than %v",
+			check.GetRetries(), worst, interval,
+		)
+	}
+
+	return nil
+}
+
 // NewControllerOpts creates controller options for the check.
 func NewControllerOpts(check Check) (*controller.Opts, error) {
 	interval := check.GetInterval()
@@ -119,13 +283,50 @@ func NewControllerOpts(check Check) (*controller.Opts, error) {
 	}
 
 	return &controller.Opts{
-		ID:       id,
-		Name:     name,
-		Interval: interval,
-		Func:     fn,
+		ID:        id,
+		Name:      name,
+		Interval:  interval,
+		Func:      fn,
+		Overflow:  controller.OverflowSummarize,
+		Summarize: summarizeStats,
 	}, nil
 }
 
+// summarizeStats merges two stats of check runs into one by retaining the
+// one that is more severe. A failed run is prioritized over a degraded run
+// which is prioritized over a successful run. In case both are equally
+// severe, the one that took longer is retained.
+func summarizeStats(older, newer *controller.RunStat) *controller.RunStat {
+	if severity(older) > severity(newer) {
+		return older
+	}
+
+	if severity(older) < severity(newer) {
+		return newer
+	}
+
+	olderRes, _ := older.Res.(*Result)
+	newerRes, _ := newer.Res.(*Result)
+	if olderRes != nil && newerRes != nil && olderRes.Duration > newerRes.Duration {
+		return older
+	}
+
+	return newer
+}
+
+// severity returns how severe the result of a check run is.
+func severity(stat *controller.RunStat) int {
+	res, ok := stat.Res.(*Result)
+	switch {
+	case stat.Err != nil || !ok || res == nil || !res.Successful:
+		return 2
+	case res.Degraded:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Validate validates the check config. This method is meant to be used only
 // when needed to validate if the check conf is correct.
 func Validate(check Check) error {
@@ -134,5 +335,14 @@ func Validate(check Check) error {
 		return err
 	}
 
-	return checker.Validate(check)
+	if err := validateRetries(check); err != nil {
+		return err
+	}
+
+	lc, err := newLatencyCheck(check)
+	if err != nil {
+		return err
+	}
+
+	return checker.Validate(lc)
 }
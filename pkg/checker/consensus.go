@@ -0,0 +1,211 @@
+package checker
+
+import (
+	"sort"
+	"time"
+)
+
+// Consensus decides the status of a check that runs from agents in multiple
+// locations.
+//
+// Each location votes with its latest metric that is not older than the
+// window. The check is down when at least `Quorum` of the locations failed,
+// and degraded when at least as many either failed or were degraded. The
+// quorum is capped at the number of configured locations, so it does not
+// drop just because some of the locations stopped reporting. A quorum of 1
+// or less means that any one location can mark the check down.
+type Consensus struct {
+	Quorum int
+	Window time.Duration
+
+	// Locations are the locations the check runs from, in the order they
+	// are preferred to lead in. If not empty, only these locations vote.
+	Locations []string
+}
+
+// Verdict is the status of a check agreed upon by its locations.
+//
+// It is a metric that takes after the latest metric of a location that
+// agrees with the verdict, but is not of any single location. It starts at
+// the time it was evaluated at.
+type Verdict struct {
+	Metric
+
+	At time.Time
+
+	Successful bool
+	Degraded   bool
+
+	// Locations are the latest metrics of the locations that voted, in the
+	// order of the configured locations, or by the location if none are.
+	// The first of them leads.
+	Locations []Metric
+
+	// Failed is the number of locations that failed.
+	Failed int
+}
+
+// GetLocation returns an empty location since the verdict is of all the
+// locations.
+func (v *Verdict) GetLocation() string {
+	return ""
+}
+
+// GetStartTime returns the time the verdict was evaluated at.
+func (v *Verdict) GetStartTime() time.Time {
+	return v.At
+}
+
+// IsSuccessful tells if the locations agree that the check is up.
+func (v *Verdict) IsSuccessful() bool {
+	return v.Successful
+}
+
+// IsTimeout tells if the check is down and timed-out.
+func (v *Verdict) IsTimeout() bool {
+	return !v.Successful && v.Metric.IsTimeout()
+}
+
+// IsDegraded tells if the locations agree that the check is degraded.
+func (v *Verdict) IsDegraded() bool {
+	return v.Degraded
+}
+
+// GetFailureCode returns the reason of failure if the check is down.
+func (v *Verdict) GetFailureCode() string {
+	if v.Successful {
+		return ""
+	}
+
+	return v.Metric.GetFailureCode()
+}
+
+// GetFailureMessage returns the message of failure if the check is down.
+func (v *Verdict) GetFailureMessage() string {
+	if v.Successful {
+		return ""
+	}
+
+	return v.Metric.GetFailureMessage()
+}
+
+// Needed returns the number of locations that should agree for the check to
+// be down or degraded.
+func (c Consensus) Needed() int {
+	q := c.Quorum
+	if q < 1 {
+		q = 1
+	}
+
+	if len(c.Locations) > 0 && q > len(c.Locations) {
+		q = len(c.Locations)
+	}
+
+	return q
+}
+
+// Votes tells if the location votes, i.e., it is one of the configured
+// locations or none are configured.
+func (c Consensus) Votes(location string) bool {
+	return len(c.Locations) == 0 || c.rank(location) >= 0
+}
+
+// rank returns the position of the location in the configured locations.
+// It is -1 if the location is not one of them.
+func (c Consensus) rank(location string) int {
+	for i, l := range c.Locations {
+		if l == location {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stale tells if the metric is too old to vote at the time.
+func (c Consensus) stale(m Metric, at time.Time) bool {
+	return c.Window > 0 && !m.GetStartTime().After(at.Add(-1*c.Window))
+}
+
+// Evaluate returns the verdict at the time from the metrics of a check, in
+// any order, from all its locations. It is nil if no location voted.
+func (c Consensus) Evaluate(metrics []Metric, at time.Time) *Verdict {
+	latest := map[string]Metric{}
+	for _, m := range metrics {
+		if m.GetStartTime().After(at) || c.stale(m, at) {
+			continue
+		}
+
+		if !c.Votes(m.GetLocation()) {
+			continue
+		}
+
+		l, ok := latest[m.GetLocation()]
+		if !ok || m.GetStartTime().After(l.GetStartTime()) {
+			latest[m.GetLocation()] = m
+		}
+	}
+
+	return c.verdict(latest, at)
+}
+
+// verdict returns the verdict at the time from the latest metric of each
+// location.
+func (c Consensus) verdict(latest map[string]Metric, at time.Time) *Verdict {
+	if len(latest) == 0 {
+		return nil
+	}
+
+	v := &Verdict{At: at, Locations: make([]Metric, 0, len(latest))}
+	var degraded int
+	for _, m := range latest {
+		v.Locations = append(v.Locations, m)
+
+		switch {
+		case !m.IsSuccessful():
+			v.Failed++
+		case m.IsDegraded():
+			degraded++
+		}
+	}
+
+	sort.Slice(v.Locations, func(i, j int) bool {
+		if len(c.Locations) > 0 {
+			return c.rank(v.Locations[i].GetLocation()) < c.rank(v.Locations[j].GetLocation())
+		}
+
+		return v.Locations[i].GetLocation() < v.Locations[j].GetLocation()
+	})
+
+	q := c.Needed()
+	v.Successful = v.Failed < q
+	v.Degraded = v.Successful && v.Failed+degraded >= q
+
+	// the verdict takes after the latest metric that agrees with it, or the
+	// latest metric if none does, for example, when the only location that
+	// voted failed but the quorum is not reached.
+	var latestVote Metric
+	for _, m := range v.Locations {
+		if latestVote == nil || m.GetStartTime().After(latestVote.GetStartTime()) {
+			latestVote = m
+		}
+
+		agrees := !m.IsSuccessful()
+		if v.Successful {
+			agrees = m.IsSuccessful() && m.IsDegraded() == v.Degraded
+		}
+
+		if agrees && (v.Metric == nil || m.GetStartTime().After(v.Metric.GetStartTime())) {
+			v.Metric = m
+		}
+	}
+
+	if v.Metric == nil {
+		v.Metric = latestVote
+	}
+
+	return v
+}
+
+// Interface guard.
+var _ Metric = (*Verdict)(nil)
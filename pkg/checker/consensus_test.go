@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"testing"
+	"time"
+)
+
+// vote is a metric of a location for the tests.
+type vote struct {
+	location  string
+	failed    bool
+	degraded  bool
+	startTime time.Time
+}
+
+func (v *vote) GetCheckID() string                      { return "check" }
+func (v *vote) GetCheckName() string                    { return "check" }
+func (v *vote) GetLocation() string                     { return v.location }
+func (v *vote) IsSuccessful() bool                      { return !v.failed }
+func (v *vote) IsTimeout() bool                         { return false }
+func (v *vote) IsDegraded() bool                        { return v.degraded }
+func (v *vote) GetStartTime() time.Time                 { return v.startTime }
+func (v *vote) GetDuration() time.Duration              { return time.Second }
+func (v *vote) GetFailureCode() string                  { return "" }
+func (v *vote) GetFailureMessage() string               { return "" }
+func (v *vote) GetMeasurements() map[string]interface{} { return nil }
+
+func TestConsensusEvaluate(t *testing.T) {
+	at := time.Now()
+	up := func(l string) Metric { return &vote{location: l, startTime: at.Add(-time.Second)} }
+	down := func(l string) Metric { return &vote{location: l, failed: true, startTime: at.Add(-2 * time.Second)} }
+	slow := func(l string) Metric { return &vote{location: l, degraded: true, startTime: at.Add(-3 * time.Second)} }
+
+	tests := []struct {
+		name       string
+		quorum     int
+		metrics    []Metric
+		successful bool
+		degraded   bool
+	}{
+		{name: "only failed location below quorum", quorum: 2, metrics: []Metric{down("a")}, successful: true},
+		{name: "failed and degraded below quorum", quorum: 3, metrics: []Metric{down("a"), slow("b")}, successful: true},
+		{name: "only degraded location below quorum", quorum: 2, metrics: []Metric{slow("a")}, successful: true},
+		{name: "quorum failed", quorum: 2, metrics: []Metric{down("a"), down("b"), up("c")}},
+		{
+			name:       "quorum failed or degraded",
+			quorum:     2,
+			metrics:    []Metric{down("a"), slow("b"), up("c")},
+			successful: true,
+			degraded:   true,
+		},
+		{name: "all up", quorum: 2, metrics: []Metric{up("a"), up("b"), up("c")}, successful: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Consensus{Quorum: tt.quorum, Window: time.Minute, Locations: []string{"a", "b", "c"}}
+
+			v := c.Evaluate(tt.metrics, at)
+			if v == nil {
+				t.Fatal("expected a verdict")
+			}
+
+			if v.Metric == nil {
+				t.Fatal("verdict has no metric")
+			}
+
+			if v.GetCheckID() != "check" || !v.GetStartTime().Equal(at) {
+				t.Errorf("unexpected metric of verdict: %q at %v", v.GetCheckID(), v.GetStartTime())
+			}
+
+			if v.IsSuccessful() != tt.successful || v.IsDegraded() != tt.degraded {
+				t.Errorf("expected successful=%v degraded=%v; got successful=%v degraded=%v",
+					tt.successful, tt.degraded, v.IsSuccessful(), v.IsDegraded())
+			}
+
+			if v.IsSuccessful() && (v.IsTimeout() || v.GetFailureCode() != "") {
+				t.Error("successful verdict should not have a failure")
+			}
+		})
+	}
+}
+
+func TestConsensusEvaluateNoVotes(t *testing.T) {
+	at := time.Now()
+	c := Consensus{Quorum: 2, Window: time.Minute, Locations: []string{"a", "b"}}
+
+	stale := &vote{location: "a", failed: true, startTime: at.Add(-time.Hour)}
+	other := &vote{location: "z", failed: true, startTime: at}
+
+	if v := c.Evaluate([]Metric{stale, other}, at); v != nil {
+		t.Errorf("expected no verdict; got %+v", v)
+	}
+}
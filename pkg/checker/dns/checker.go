@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/sdslabs/pinger/pkg/checker"
 )
@@ -19,8 +20,8 @@ func init() {
 type Checker struct {
 	prober *Prober
 
-	outputType  string
-	outputValue string
+	operator string
+	outputs  []output
 }
 
 // Validate validates the check configuration.
@@ -39,7 +40,7 @@ func (c *Checker) Validate(check checker.Check) error {
 		"IP":      validateOutputAddress,
 		"ADDRESS": validateOutputAddress,
 	}
-	if err := checker.ValidateComponent(check.GetOutput(), validateOutputMap); err != nil {
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
 		return fmt.Errorf("output: %w", err)
 	}
 
@@ -65,8 +66,12 @@ func (c *Checker) Provision(check checker.Check) (err error) {
 		return
 	}
 
-	c.outputType = check.GetOutput().GetType()
-	c.outputValue = check.GetOutput().GetValue()
+	c.operator = check.GetOperator()
+	c.outputs = make([]output, 0, len(check.GetOutputs()))
+	for _, o := range check.GetOutputs() {
+		c.outputs = append(c.outputs, output{typ: o.GetType(), value: o.GetValue()})
+	}
+
 	return nil
 }
 
@@ -88,25 +93,42 @@ func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
 	}
 
 	result.Timeout = false
+	result.Measurements = map[string]interface{}{
+		checker.MeasurementResolvedIPs: strings.Join(probeResult.ResolvedTo, ","),
+		checker.MeasurementNumResolved: len(probeResult.ResolvedTo),
+	}
 
-	if c.outputType == "TIMEOUT" {
-		// we need to check if the address did resolve to atleast one of IP address.
-		if len(probeResult.ResolvedTo) > 0 {
-			result.Successful = true
-		}
+	result.Successful, err = checker.Match(c.operator, len(c.outputs), func(i int) (bool, error) {
+		return c.outputs[i].match(probeResult), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		return result, nil
+	return result, nil
+}
+
+// output is a provisioned output of the check.
+type output struct {
+	typ   string
+	value string
+}
+
+// match tells if the probe result matches the output.
+func (o output) match(probeResult *ProbeResult) bool {
+	if o.typ == "TIMEOUT" {
+		// we need to check if the address did resolve to atleast one of IP address.
+		return len(probeResult.ResolvedTo) > 0
 	}
 
 	// the only other type of output type is "IP" (or "ADDRESS")
 	for _, addr := range probeResult.ResolvedTo {
-		if addr == c.outputValue {
-			result.Successful = true
-			return result, nil
+		if addr == o.value {
+			return true
 		}
 	}
 
-	return result, nil
+	return false
 }
 
 // validationMap is an alias of map used for validating components.
@@ -29,4 +29,7 @@
 //
 // Payload is not required and hence not validated.
 //
+// Measurements recorded for each check are "resolved_ips" (comma separated)
+// and "num_resolved".
+//
 package dns
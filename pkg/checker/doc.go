@@ -15,6 +15,28 @@
 // check config which can be paired with a controller which executes checker
 // at regular intervals of time.
 //
+// A check can have multiple outputs which are combined using the operator
+// of the check. With the "AND" operator (default) all the outputs should
+// match for the check to be successful whereas with "OR" any one of them
+// should match.
+//
+// Apart from the outputs supported by a checker, every check can have a
+// "MAX_LATENCY" output with a duration, say, "2s" as the value. It does not
+// take part in matching the outputs, rather a successful check that takes
+// longer than this is marked as degraded.
+//
+// When a check fails, the result carries a failure code, such as, "DNS" or
+// "ASSERTION" along with a message describing the failure. Checkers may set
+// these themselves, otherwise they are derived from the execution error or
+// the timeout.
+//
+// Checkers also record measurements along with the result, such as, the
+// status code of the response, which are exported with the metrics.
+//
+// The same check can run from agents in multiple locations. Consensus
+// decides the status of such a check from the latest metric of each
+// location, so that the check is down only when a quorum of locations agree.
+//
 // This package also contains some helpers which are common to use among
 // checkers, such as, regex for checking if the address is valid or not or
 // if the err is timeout.
@@ -1,9 +1,13 @@
 package checker
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
 	"regexp"
+	"syscall"
 )
 
 // addressRegexPattern is the regex pattern for AddressRegex.
@@ -27,6 +31,46 @@ func ErrIsTimeout(err error) bool {
 	return ok && netErr.Timeout()
 }
 
+// FailureCodeFromError classifies the error returned while executing a check
+// into one of the failure codes.
+func FailureCodeFromError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		dnsErr      *net.DNSError
+		recordErr   tls.RecordHeaderError
+		unknownErr  x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+		opErr       *net.OpError
+	)
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+
+	case ErrIsTimeout(err):
+		return FailureTimeout
+
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureConnectionRefused
+
+	case errors.As(err, &recordErr),
+		errors.As(err, &unknownErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr):
+		return FailureTLS
+
+	case errors.As(err, &opErr), errors.Is(err, syscall.ECONNRESET):
+		return FailureConnection
+
+	default:
+		return FailureError
+	}
+}
+
 type validateComponentFunc = func(string) error
 
 // ValidateComponent takes a map of types and validation of their values
@@ -42,3 +86,70 @@ func ValidateComponent(c Component, m map[string]validateComponentFunc) error {
 
 	return validateFunc(val)
 }
+
+// Operators that combine the outputs of a check.
+const (
+	// OperatorAnd requires all the outputs to match. This is the default
+	// operator when none is specified.
+	OperatorAnd = "AND"
+
+	// OperatorOr requires at least one of the outputs to match.
+	OperatorOr = "OR"
+)
+
+// ValidateOperator validates if the operator is one of the supported ones.
+func ValidateOperator(operator string) error {
+	switch operator {
+	case "", OperatorAnd, OperatorOr:
+	default:
+		return fmt.Errorf("invalid operator: %s", operator)
+	}
+
+	return nil
+}
+
+// ValidateOutputs validates the operator and each of the outputs of the check
+// using the map of types and validation of their values.
+func ValidateOutputs(check Check, m map[string]validateComponentFunc) error {
+	if err := ValidateOperator(check.GetOperator()); err != nil {
+		return err
+	}
+
+	outputs := check.GetOutputs()
+	if len(outputs) == 0 {
+		return fmt.Errorf("at least one output is required")
+	}
+
+	for i, o := range outputs {
+		if err := ValidateComponent(o, m); err != nil {
+			return fmt.Errorf("%d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Match evaluates `n` outputs using the match function and combines the
+// results using the operator. The match function tells if the i-th output
+// matched. In case it returns an error, the evaluation is stopped and the
+// error is returned.
+func Match(operator string, n int, match func(i int) (bool, error)) (bool, error) {
+	for i := 0; i < n; i++ {
+		matched, err := match(i)
+		if err != nil {
+			return false, err
+		}
+
+		if operator == OperatorOr && matched {
+			return true, nil
+		}
+
+		if operator != OperatorOr && !matched {
+			return false, nil
+		}
+	}
+
+	// For "AND", reaching here means all the outputs matched whereas for "OR"
+	// none of them did.
+	return operator != OperatorOr && n > 0, nil
+}
@@ -28,6 +28,9 @@ const (
 	// bodyType is the type of output for response body.
 	bodyType = "BODY"
 
+	// bodyContainsType is the type of output for a sub-string of response body.
+	bodyContainsType = "BODY_CONTAINS"
+
 	// parameterType is the type of payload for HTTP parameter.
 	parameterType = "PARAMETER"
 
@@ -44,8 +47,8 @@ func init() {
 type Checker struct {
 	prober *Prober
 
-	outputType  string
-	outputValue interface{}
+	operator string
+	outputs  []output
 }
 
 // Validate validates the check configuration.
@@ -60,13 +63,14 @@ func (c *Checker) Validate(check checker.Check) error {
 	}
 
 	validateOutputMap := validationMap{
-		timeoutType:    validateNil,
-		statusCodeType: validateOutputStatusCode,
-		bodyType:       validateNil,
-		headerType:     validatePayloadHeader,
+		timeoutType:      validateNil,
+		statusCodeType:   validateOutputStatusCode,
+		bodyType:         validateNil,
+		bodyContainsType: validateNil,
+		headerType:       validatePayloadHeader,
 	}
-	if err := checker.ValidateComponent(check.GetOutput(), validateOutputMap); err != nil {
-		return fmt.Errorf("input: %w", err)
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
+		return fmt.Errorf("output: %w", err)
 	}
 
 	validateTargetMap := validationMap{"URL": validateTarget}
@@ -126,33 +130,37 @@ func (c *Checker) Provision(check checker.Check) error {
 		return err
 	}
 
-	var outputValue interface{}
-	outputType := check.GetOutput().GetType()
-	switch outputType {
-	case headerType:
-		k, v, err := extractsKVPair(check.GetOutput().GetValue())
-		if err != nil {
-			return err
-		}
+	outputs := make([]output, 0, len(check.GetOutputs()))
+	for _, o := range check.GetOutputs() {
+		var value interface{}
+		switch o.GetType() {
+		case headerType:
+			k, v, err := extractsKVPair(o.GetValue())
+			if err != nil {
+				return err
+			}
 
-		outputValue = kvPair{k: k, v: v}
+			value = kvPair{k: k, v: v}
 
-	case statusCodeType:
-		st, err := extractStatusCode(check.GetOutput().GetValue())
-		if err != nil {
-			return err
-		}
+		case statusCodeType:
+			st, err := extractStatusCode(o.GetValue())
+			if err != nil {
+				return err
+			}
 
-		outputValue = st
+			value = st
 
-	case bodyType:
-		outputValue = check.GetOutput().GetValue()
+		case bodyType, bodyContainsType:
+			value = o.GetValue()
 
-	default:
+		default:
+		}
+
+		outputs = append(outputs, output{typ: o.GetType(), value: value})
 	}
 
-	c.outputType = outputType
-	c.outputValue = outputValue
+	c.operator = check.GetOperator()
+	c.outputs = outputs
 	c.prober = prober
 	return nil
 }
@@ -175,45 +183,78 @@ func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
 	}
 
 	result.Timeout = false
+	result.Measurements = map[string]interface{}{
+		checker.MeasurementStatusCode:    probeResult.StatusCode,
+		checker.MeasurementBytesReceived: len(probeResult.Body),
+	}
+	probeResult.Timings.AddMeasurements(result.Measurements)
 
-	switch c.outputType {
+	successful, err := checker.Match(c.operator, len(c.outputs), func(i int) (bool, error) {
+		return c.outputs[i].match(probeResult)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Successful = successful
+	if !successful {
+		result.FailureCode = checker.FailureAssertion
+		result.FailureMessage = fmt.Sprintf(
+			"outputs did not match: received status code %d",
+			probeResult.StatusCode,
+		)
+	}
+
+	return result, nil
+}
+
+// output is a provisioned output of the check.
+type output struct {
+	typ   string
+	value interface{}
+}
+
+// match tells if the probe result matches the output.
+func (o output) match(probeResult *ProbeResult) (bool, error) {
+	switch o.typ {
 	case timeoutType:
-		result.Successful = true
+		return true, nil
 
 	case bodyType:
-		body, ok := c.outputValue.(string)
+		body, ok := o.value.(string)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of body not a string")
+			return false, fmt.Errorf("internal error: outputValue of body not a string")
 		}
 
-		if probeResult.Body == body {
-			result.Successful = true
+		return probeResult.Body == body, nil
+
+	case bodyContainsType:
+		substr, ok := o.value.(string)
+		if !ok {
+			return false, fmt.Errorf("internal error: outputValue of body not a string")
 		}
 
+		return strings.Contains(probeResult.Body, substr), nil
+
 	case statusCodeType:
-		st, ok := c.outputValue.(int)
+		st, ok := o.value.(int)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of status code not an int")
+			return false, fmt.Errorf("internal error: outputValue of status code not an int")
 		}
 
-		if st == probeResult.StatusCode {
-			result.Successful = true
-		}
+		return st == probeResult.StatusCode, nil
 
 	case headerType:
-		header, ok := c.outputValue.(kvPair)
+		header, ok := o.value.(kvPair)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of header not a kvPair")
+			return false, fmt.Errorf("internal error: outputValue of header not a kvPair")
 		}
 
-		if probeResult.Headers.Get(header.k) == header.v {
-			result.Successful = true
-		}
+		return probeResult.Headers.Get(header.k) == header.v, nil
 
 	default:
+		return false, nil
 	}
-
-	return result, nil
 }
 
 // kvPair is a string-string key value pair.
@@ -16,12 +16,13 @@
 //
 // Output:
 //
-// 	     Type                   Value                               Description
-// 	-------------- -------------------------------- -------------------------------------------
-// 	 "TIMEOUT"      <not validated>                  Success is not-timeout
-// 	 "STATUSCODE"   <valid status code>              Response status code
-// 	 "BODY"         <not validated>                  Response body should match this
-// 	 "HEADER"       <valid header of format "K=V">   Header with key 'K' should have value 'V'
+// 	      Type                    Value                               Description
+// 	----------------- -------------------------------- -------------------------------------------
+// 	 "TIMEOUT"         <not validated>                  Success is not-timeout
+// 	 "STATUSCODE"      <valid status code>              Response status code
+// 	 "BODY"            <not validated>                  Response body should match this
+// 	 "BODY_CONTAINS"   <not validated>                  Response body should contain this
+// 	 "HEADER"          <valid header of format "K=V">   Header with key 'K' should have value 'V'
 //
 // Target:
 //
@@ -38,4 +39,12 @@
 // 	               'V' is a valid JSON object, i.e,   or form-data/json body (depending on
 // 	               string, bool, number or null>      value of "Content-Type" header)
 //
+// Measurements recorded for each check are "status_code" and "bytes_received"
+// (size of the body).
+//
+// Along with these, the duration of each phase of the request is recorded in
+// seconds as "dns_lookup_seconds", "tcp_connect_seconds",
+// "tls_handshake_seconds", "time_to_first_byte_seconds" and
+// "content_transfer_seconds".
+//
 package http
@@ -72,6 +72,9 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 	probeCtx, cancel := context.WithTimeout(baseCtx, p.timeout)
 	defer cancel()
 
+	tracer := new(checker.PhaseTracer)
+	probeCtx = tracer.WithContext(probeCtx)
+
 	timeoutResult := &ProbeResult{
 		Timeout:   true,
 		StartTime: startTime,
@@ -112,6 +115,7 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	tracer.Done()
 
 	return &ProbeResult{
 		Timeout:    false,
@@ -120,6 +124,7 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
 		Body:       buf.String(),
+		Timings:    tracer.Timings(),
 	}, nil
 }
 
@@ -188,4 +193,7 @@ type ProbeResult struct {
 	Headers    http.Header
 	StartTime  time.Time
 	Duration   time.Duration
+
+	// Timings are the durations of the phases of the request.
+	Timings checker.PhaseTimings
 }
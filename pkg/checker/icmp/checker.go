@@ -32,7 +32,7 @@ func (c *Checker) Validate(check checker.Check) error {
 	}
 
 	validateOutputMap := validationMap{"TIMEOUT": validateNil}
-	if err := checker.ValidateComponent(check.GetOutput(), validateOutputMap); err != nil {
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
 		return fmt.Errorf("output: %w", err)
 	}
 
@@ -69,6 +69,11 @@ func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
 	if !probeResult.Timeout {
 		result.Successful = true
 		result.Timeout = false
+		result.Measurements = map[string]interface{}{
+			checker.MeasurementTTL:           probeResult.TTL,
+			checker.MeasurementBytesSent:     probeResult.NumBytesSent,
+			checker.MeasurementBytesReceived: probeResult.NumBytesReceived,
+		}
 	}
 
 	return result, nil
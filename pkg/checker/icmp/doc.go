@@ -27,4 +27,7 @@
 //
 // Payload is not required and hence not validated.
 //
+// Measurements recorded for each check are "ttl", "bytes_sent" and
+// "bytes_received".
+//
 package icmp
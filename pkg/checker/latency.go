@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// maxLatencyType is the type of output for the latency threshold. It is
+	// supported by every checker since it only depends on the duration of
+	// the check.
+	maxLatencyType = "MAX_LATENCY"
+
+	// timeoutType is the type of output that every checker supports which
+	// marks the check successful if it does not time out.
+	timeoutType = "TIMEOUT"
+)
+
+// component is a simple implementation of the Component interface.
+type component struct {
+	typ   string
+	value string
+}
+
+// GetType returns the type of the component.
+func (c component) GetType() string { return c.typ }
+
+// GetValue returns the value of the component.
+func (c component) GetValue() string { return c.value }
+
+// latencyCheck is the check with the latency threshold separated from the
+// rest of the outputs so the checkers only receive the outputs they support.
+type latencyCheck struct {
+	Check
+
+	outputs    []Component
+	maxLatency time.Duration
+}
+
+// newLatencyCheck separates the latency threshold from the outputs of the
+// check. If the latency threshold is the only output, the check is only
+// required to not time out.
+func newLatencyCheck(check Check) (*latencyCheck, error) {
+	lc := &latencyCheck{Check: check}
+
+	for _, o := range check.GetOutputs() {
+		if o.GetType() != maxLatencyType {
+			lc.outputs = append(lc.outputs, o)
+			continue
+		}
+
+		if lc.maxLatency > 0 {
+			return nil, fmt.Errorf("output: only one %s output is allowed", maxLatencyType)
+		}
+
+		maxLatency, err := time.ParseDuration(o.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("output: invalid %s: %w", maxLatencyType, err)
+		}
+
+		if maxLatency <= 0 {
+			return nil, fmt.Errorf("output: %s should be > 0", maxLatencyType)
+		}
+
+		if maxLatency >= check.GetTimeout() {
+			return nil, fmt.Errorf("output: %s should be less than timeout", maxLatencyType)
+		}
+
+		lc.maxLatency = maxLatency
+	}
+
+	if lc.maxLatency > 0 && len(lc.outputs) == 0 {
+		lc.outputs = []Component{component{typ: timeoutType}}
+	}
+
+	return lc, nil
+}
+
+// GetOutputs returns the outputs of the check except the latency threshold.
+func (c *latencyCheck) GetOutputs() []Component {
+	return c.outputs
+}
+
+// setDegraded marks a successful result as degraded if it took longer than
+// the latency threshold.
+func (c *latencyCheck) setDegraded(r *Result) {
+	r.Degraded = r.Successful && c.maxLatency > 0 && r.Duration > c.maxLatency
+}
@@ -30,9 +30,9 @@ func init() {
 
 // Checker sends an TCP ECHO request and checks if a reply is returned.
 type Checker struct {
-	prober      *Prober
-	outputType  string
-	outputValue []string
+	prober   *Prober
+	operator string
+	outputs  []output
 }
 
 // Validate validates the check configuration.
@@ -50,7 +50,7 @@ func (c *Checker) Validate(check checker.Check) error {
 		timeoutType: validateNil,
 		messageType: validateOutputMessages,
 	}
-	if err := checker.ValidateComponent(check.GetOutput(), validateOutputMap); err != nil {
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
 		return fmt.Errorf("output: %w", err)
 	}
 
@@ -83,22 +83,31 @@ func (c *Checker) Provision(check checker.Check) (err error) {
 	}
 
 	c.prober, err = NewProber(address, messages, timeout)
-	c.outputType = check.GetOutput().GetType()
-	switch c.outputType {
-	case messageType:
-		// messages are split via "\n---\n", so multiple messages which are to be
-		// verified should be separated via the same.
-		// For example, if the response should have two messages -- "hello" and say
-		// "world", output should be:
-		// 	"hello\n---\nworld"
-		// OR
-		// 	hello
-		// 	---
-		// 	world
-		c.outputValue = strings.Split(check.GetOutput().GetValue(), splitDelim)
-	default:
+	if err != nil {
+		return
+	}
+
+	c.operator = check.GetOperator()
+	c.outputs = make([]output, 0, len(check.GetOutputs()))
+	for _, o := range check.GetOutputs() {
+		out := output{typ: o.GetType()}
+		if out.typ == messageType {
+			// messages are split via "\n---\n", so multiple messages which are to be
+			// verified should be separated via the same.
+			// For example, if the response should have two messages -- "hello" and say
+			// "world", output should be:
+			// 	"hello\n---\nworld"
+			// OR
+			// 	hello
+			// 	---
+			// 	world
+			out.messages = strings.Split(o.GetValue(), splitDelim)
+		}
+
+		c.outputs = append(c.outputs, out)
 	}
-	return
+
+	return nil
 }
 
 // Execute executes the check.
@@ -119,29 +128,59 @@ func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
 	}
 
 	result.Timeout = false
+	result.Measurements = map[string]interface{}{
+		checker.MeasurementMessagesReceived: len(probeResult.Response),
+		checker.MeasurementBytesReceived:    numBytes(probeResult.Response),
+	}
+
+	result.Successful, err = checker.Match(c.operator, len(c.outputs), func(i int) (bool, error) {
+		return c.outputs[i].match(probeResult), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// output is a provisioned output of the check.
+type output struct {
+	typ      string
+	messages []string
+}
 
-	switch c.outputType {
+// match tells if the probe result matches the output.
+func (o output) match(probeResult *ProbeResult) bool {
+	switch o.typ {
 	case timeoutType:
-		result.Successful = true
+		return true
 
 	case messageType:
+		if len(o.messages) != len(probeResult.Response) {
+			return false
+		}
 
-		if len(c.outputValue) == len(probeResult.Response) {
-			allEq := true
-			for i := range c.outputValue {
-				if c.outputValue[i] != probeResult.Response[i] {
-					allEq = false
-					break
-				}
-			}
-
-			if allEq {
-				result.Successful = true
+		for i := range o.messages {
+			if o.messages[i] != probeResult.Response[i] {
+				return false
 			}
 		}
+
+		return true
+
+	default:
+		return false
 	}
+}
 
-	return result, nil
+// numBytes returns the total number of bytes in the messages.
+func numBytes(msgs []string) int {
+	n := 0
+	for _, msg := range msgs {
+		n += len(msg)
+	}
+
+	return n
 }
 
 // validationMap is an alias of map used for validating components.
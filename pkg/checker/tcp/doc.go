@@ -38,4 +38,7 @@
 //	----------- -------------------- ---------------------------
 //	 "MESSAGE"   <non empty string>   Sent in order as in array
 //
+// Measurements recorded for each check are "messages_received" and
+// "bytes_received".
+//
 package tcp
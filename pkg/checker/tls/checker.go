@@ -0,0 +1,353 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+)
+
+const (
+	// checkerName is the name of the checker.
+	checkerName = "TLS"
+
+	// timeoutType is the type of output for checking timeout.
+	timeoutType = "TIMEOUT"
+
+	// expiresAfterType is the type of output for checking certificate expiry.
+	expiresAfterType = "EXPIRES_AFTER"
+
+	// issuerType is the type of output for checking certificate issuer.
+	issuerType = "ISSUER"
+
+	// minVersionType is the type of output for checking the TLS version.
+	minVersionType = "MIN_VERSION"
+
+	// serverNameType is the type of payload for the SNI server name.
+	serverNameType = "SERVERNAME"
+
+	// daysSuffix is the suffix for expiry values specified in days.
+	daysSuffix = "d"
+)
+
+// versions maps the version string to the TLS version.
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func init() {
+	checker.Register(checkerName, func() checker.Checker { return new(Checker) })
+}
+
+// Checker completes a TLS handshake with the target and tests if the
+// certificates presented match the requirements.
+type Checker struct {
+	prober *Prober
+
+	operator string
+	outputs  []output
+}
+
+// Validate validates the check configuration.
+func (c *Checker) Validate(check checker.Check) error {
+	if check.GetTimeout() <= 0 {
+		return fmt.Errorf("timeout should be > 0")
+	}
+
+	validateInputMap := validationMap{checkerName: validateInput}
+	if err := checker.ValidateComponent(check.GetInput(), validateInputMap); err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+
+	validateOutputMap := validationMap{
+		timeoutType:      validateNil,
+		expiresAfterType: validateOutputExpiresAfter,
+		issuerType:       validateNonEmpty,
+		minVersionType:   validateOutputMinVersion,
+	}
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+
+	validateTargetMap := validationMap{"ADDRESS": validateTarget}
+	if err := checker.ValidateComponent(check.GetTarget(), validateTargetMap); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	validatePayloadMap := validationMap{
+		serverNameType: validateNonEmpty,
+	}
+	for i, p := range check.GetPayloads() {
+		if err := checker.ValidateComponent(p, validatePayloadMap); err != nil {
+			return fmt.Errorf("payload %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Provision initializes required fields for c's execution.
+func (c *Checker) Provision(check checker.Check) error {
+	address := check.GetTarget().GetValue()
+	timeout := check.GetTimeout()
+
+	var serverName string
+	for _, p := range check.GetPayloads() {
+		if p.GetType() == serverNameType {
+			serverName = p.GetValue()
+		}
+	}
+
+	prober, err := NewProber(address, serverName, timeout)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]output, 0, len(check.GetOutputs()))
+	for _, o := range check.GetOutputs() {
+		var value interface{}
+		switch o.GetType() {
+		case expiresAfterType:
+			d, err := extractExpiresAfter(o.GetValue())
+			if err != nil {
+				return err
+			}
+
+			value = d
+
+		case issuerType:
+			value = o.GetValue()
+
+		case minVersionType:
+			value = versions[o.GetValue()]
+
+		default:
+		}
+
+		outputs = append(outputs, output{typ: o.GetType(), value: value})
+	}
+
+	c.operator = check.GetOperator()
+	c.outputs = outputs
+	c.prober = prober
+	return nil
+}
+
+// Execute executes the check.
+func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
+	probeResult, err := c.prober.Probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &checker.Result{
+		Timeout:   true,
+		StartTime: probeResult.StartTime,
+		Duration:  probeResult.Duration,
+	}
+
+	if probeResult.Timeout {
+		return result, nil
+	}
+
+	result.Timeout = false
+	result.Measurements = map[string]interface{}{
+		checker.MeasurementTLSVersion:    versionString(probeResult.Version),
+		checker.MeasurementCertExpiresIn: probeResult.NotAfter.Sub(probeResult.StartTime).Seconds(),
+	}
+
+	// irrespective of the output, an invalid chain or hostname fails the check.
+	if probeResult.ChainError != nil {
+		result.FailureCode = checker.FailureTLS
+		result.FailureMessage = probeResult.ChainError.Error()
+		return result, nil
+	}
+
+	if probeResult.HostnameError != nil {
+		result.FailureCode = checker.FailureTLS
+		result.FailureMessage = probeResult.HostnameError.Error()
+		return result, nil
+	}
+
+	result.Successful, err = checker.Match(c.operator, len(c.outputs), func(i int) (bool, error) {
+		return c.outputs[i].match(probeResult)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// output is a provisioned output of the check.
+type output struct {
+	typ   string
+	value interface{}
+}
+
+// match tells if the probe result matches the output.
+func (o output) match(probeResult *ProbeResult) (bool, error) {
+	switch o.typ {
+	case timeoutType:
+		return true, nil
+
+	case expiresAfterType:
+		d, ok := o.value.(time.Duration)
+		if !ok {
+			return false, fmt.Errorf("internal error: outputValue of expiry not a time.Duration")
+		}
+
+		return probeResult.NotAfter.Sub(probeResult.StartTime) >= d, nil
+
+	case issuerType:
+		issuer, ok := o.value.(string)
+		if !ok {
+			return false, fmt.Errorf("internal error: outputValue of issuer not a string")
+		}
+
+		return matchesIssuer(probeResult, issuer), nil
+
+	case minVersionType:
+		version, ok := o.value.(uint16)
+		if !ok {
+			return false, fmt.Errorf("internal error: outputValue of version not a uint16")
+		}
+
+		return probeResult.Version >= version, nil
+
+	default:
+		return false, nil
+	}
+}
+
+// matchesIssuer tells if the issuer of the leaf certificate has the given
+// common name or organization.
+func matchesIssuer(probeResult *ProbeResult, issuer string) bool {
+	leafIssuer := probeResult.Certificates[0].Issuer
+	if leafIssuer.CommonName == issuer {
+		return true
+	}
+
+	for _, org := range leafIssuer.Organization {
+		if org == issuer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionString returns the version string for the TLS version.
+func versionString(version uint16) string {
+	for str, v := range versions {
+		if v == version {
+			return str
+		}
+	}
+
+	return ""
+}
+
+// validationMap is an alias of map used for validating components.
+type validationMap = map[string]func(string) error
+
+// validateNil doesn't validate anything.
+func validateNil(string) error { return nil }
+
+// validateNonEmpty validates that the value is not empty.
+func validateNonEmpty(val string) error {
+	if val == "" {
+		return fmt.Errorf("value cannot be empty")
+	}
+
+	return nil
+}
+
+// validateInput validates the check input.
+func validateInput(val string) error {
+	switch val {
+	case "", "HANDSHAKE":
+	default:
+		return fmt.Errorf("invalid value: %s", val)
+	}
+
+	return nil
+}
+
+// validateOutputExpiresAfter validates the expiry output value.
+func validateOutputExpiresAfter(val string) error {
+	_, err := extractExpiresAfter(val)
+	return err
+}
+
+// validateOutputMinVersion validates the minimum TLS version.
+func validateOutputMinVersion(val string) error {
+	if _, ok := versions[val]; !ok {
+		return fmt.Errorf("invalid TLS version: %s", val)
+	}
+
+	return nil
+}
+
+// validateTarget validates if the target value is a valid address.
+func validateTarget(val string) error {
+	if !isValidTLSAddr(val) {
+		return fmt.Errorf("target is not a valid address: %s", val)
+	}
+
+	return nil
+}
+
+// extractExpiresAfter extracts the duration from the value which can either
+// be a number of days, say, "14d" or a duration like "336h".
+func extractExpiresAfter(val string) (time.Duration, error) {
+	if strings.HasSuffix(val, daysSuffix) {
+		days, err := strconv.ParseUint(strings.TrimSuffix(val, daysSuffix), 10, 0)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %v", err)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %v", err)
+	}
+
+	if d < 0 {
+		return 0, fmt.Errorf("duration should be >= 0")
+	}
+
+	return d, nil
+}
+
+// isValidTLSAddr tells if s is a valid address or not.
+func isValidTLSAddr(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return false
+	}
+
+	if net.ParseIP(host) == nil && !checker.AddressRegex.MatchString(host) {
+		return false
+	}
+
+	portNo, err := strconv.ParseUint(port, 10, 0)
+	if err != nil {
+		return false
+	}
+
+	return portNo <= 65535
+}
+
+// Interface guard.
+var _ checker.Checker = (*Checker)(nil)
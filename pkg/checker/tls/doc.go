@@ -0,0 +1,48 @@
+// Package tls implements the TLS checker and prober.
+//
+// The TLS prober dials the address, completes a TLS handshake and verifies
+// the certificate chain presented by the server along with the hostname.
+// The checker then tests the handshake result against the expected output.
+//
+// Valid check format is described as following:
+//
+// Interval and Timeout should be greater than 0.
+//
+// Input:
+//
+// 	  Type          Value                Description
+// 	-------- ------------------- ---------------------------
+// 	 "TLS"    "", "HANDSHAKE"     Completes a TLS handshake
+//
+// Output:
+//
+// 	      Type                  Value                             Description
+// 	----------------- ------------------------------ ------------------------------------------
+// 	 "TIMEOUT"         <not validated>                Success is a verified handshake
+// 	 "EXPIRES_AFTER"   <days ("14d") or duration>     Certificate chain should not expire
+// 	                                                  before the given time
+// 	 "ISSUER"          <non empty string>             Common name or organization of the
+// 	                                                  leaf certificate's issuer
+// 	 "MIN_VERSION"     "1.0", "1.1", "1.2", "1.3"     Negotiated version should be at least
+// 	                                                  the given version
+//
+// Every output also requires the certificate chain and the hostname to be
+// valid for the check to be successful.
+//
+// Target:
+//
+// 	   Type             Value                          Description
+// 	----------- -------------------------- --------------------------------
+// 	 "ADDRESS"   <valid HOST:PORT address>   Address to send the request to
+//
+// Payload:
+//
+// 	     Type              Value                     Description
+// 	-------------- -------------------- ---------------------------------
+// 	 "SERVERNAME"   <non empty string>   Server name sent through SNI and
+// 	                                     verified against the certificate
+//
+// Measurements recorded for each check are "tls_version" and
+// "cert_expires_in_seconds".
+//
+package tls
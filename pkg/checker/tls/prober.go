@@ -0,0 +1,155 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+)
+
+// Prober completes a TLS handshake with the address and verifies the
+// certificates presented by the server.
+type Prober struct {
+	address    string
+	serverName string
+	timeout    time.Duration
+}
+
+// NewProber creates a prober that completes a TLS handshake. If the server
+// name is empty, the host from the address is used as server name.
+func NewProber(address, serverName string, timeout time.Duration) (*Prober, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("timeout should be > 0")
+	}
+
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		serverName = host
+	}
+
+	return &Prober{
+		address:    address,
+		serverName: serverName,
+		timeout:    timeout,
+	}, nil
+}
+
+// Probe dials the address and completes the TLS handshake.
+func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
+	startTime := time.Now()
+
+	baseCtx := ctx
+	if ctx == nil {
+		baseCtx = context.Background()
+	}
+
+	probeCtx, cancel := context.WithTimeout(baseCtx, p.timeout)
+	defer cancel()
+
+	timeoutResult := &ProbeResult{
+		Timeout:   true,
+		StartTime: startTime,
+		Duration:  p.timeout,
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: p.timeout},
+		Config: &tls.Config{
+			ServerName: p.serverName,
+			// The chain and hostname are verified after the handshake so that the
+			// exact reason for an invalid certificate can be reported.
+			InsecureSkipVerify: true, // nolint:gosec
+		},
+	}
+
+	conn, err := dialer.DialContext(probeCtx, "tcp", p.address)
+	if err != nil {
+		if checker.ErrIsTimeout(err) {
+			return timeoutResult, nil
+		}
+
+		return nil, err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil, fmt.Errorf("not a valid TLS connection")
+	}
+
+	state := tlsConn.ConnectionState()
+	duration := time.Since(startTime)
+
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no certificates presented by the server")
+	}
+
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	result := &ProbeResult{
+		Timeout:      false,
+		StartTime:    startTime,
+		Duration:     duration,
+		Version:      state.Version,
+		Certificates: state.PeerCertificates,
+		NotAfter:     leaf.NotAfter,
+	}
+
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		CurrentTime:   startTime,
+	})
+	if err != nil {
+		result.ChainError = err
+	} else if len(chains) > 0 {
+		// the chain is only as good as the certificate that expires first.
+		for _, cert := range chains[0] {
+			if cert.NotAfter.Before(result.NotAfter) {
+				result.NotAfter = cert.NotAfter
+			}
+		}
+	}
+
+	if err := leaf.VerifyHostname(p.serverName); err != nil {
+		result.HostnameError = err
+	}
+
+	return result, nil
+}
+
+// ProbeResult is the result of a TLS probe.
+type ProbeResult struct {
+	Timeout   bool
+	StartTime time.Time
+	Duration  time.Duration
+
+	// Version is the negotiated TLS version.
+	Version uint16
+
+	// Certificates are the certificates presented by the server, the first
+	// one being the leaf certificate.
+	Certificates []*x509.Certificate
+
+	// NotAfter is the earliest expiry time among the certificates of the
+	// verified chain.
+	NotAfter time.Time
+
+	// ChainError is the error in verifying the certificate chain.
+	ChainError error
+
+	// HostnameError is the error in verifying the hostname against the leaf
+	// certificate.
+	HostnameError error
+}
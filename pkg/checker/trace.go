@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Keys of the measurements for the phases of an HTTP request. Each of the
+// values is the duration of the phase in seconds.
+const (
+	MeasurementDNSLookup       = "dns_lookup_seconds"
+	MeasurementTCPConnect      = "tcp_connect_seconds"
+	MeasurementTLSHandshake    = "tls_handshake_seconds"
+	MeasurementTimeToFirstByte = "time_to_first_byte_seconds"
+	MeasurementContentTransfer = "content_transfer_seconds"
+)
+
+// PhaseTimings are the durations of the various phases of an HTTP request.
+// A phase that did not occur, for example, the TLS handshake for a plain
+// HTTP request, has a zero duration.
+type PhaseTimings struct {
+	// DNSLookup is the time taken to resolve the host.
+	DNSLookup time.Duration
+
+	// TCPConnect is the time taken to establish the TCP connection.
+	TCPConnect time.Duration
+
+	// TLSHandshake is the time taken to complete the TLS handshake.
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time from when the connection was ready till
+	// the first byte of the response was received.
+	TimeToFirstByte time.Duration
+
+	// ContentTransfer is the time from when the first byte of the response
+	// was received till the response was completely read.
+	ContentTransfer time.Duration
+}
+
+// AddMeasurements adds the duration of each phase in seconds to the
+// measurements.
+func (t PhaseTimings) AddMeasurements(measurements map[string]interface{}) {
+	measurements[MeasurementDNSLookup] = t.DNSLookup.Seconds()
+	measurements[MeasurementTCPConnect] = t.TCPConnect.Seconds()
+	measurements[MeasurementTLSHandshake] = t.TLSHandshake.Seconds()
+	measurements[MeasurementTimeToFirstByte] = t.TimeToFirstByte.Seconds()
+	measurements[MeasurementContentTransfer] = t.ContentTransfer.Seconds()
+}
+
+// PhaseTracer records the time of each phase of an HTTP request using the
+// hooks from `httptrace`.
+//
+// It is safe to use the tracer concurrently since the hooks can be called
+// from different goroutines.
+type PhaseTracer struct {
+	mu sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, firstByte, done  time.Time
+}
+
+// WithContext returns a context which traces the request made with it.
+func (t *PhaseTracer) WithContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.record(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.record(&t.dnsDone) },
+
+		// in case of multiple addresses, only the first attempt is recorded.
+		ConnectStart: func(string, string) { t.recordOnce(&t.connectStart) },
+		ConnectDone:  func(string, string, error) { t.recordOnce(&t.connectDone) },
+
+		TLSHandshakeStart: func() { t.record(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.record(&t.tlsDone) },
+
+		GotConn:              func(httptrace.GotConnInfo) { t.record(&t.gotConn) },
+		GotFirstResponseByte: func() { t.record(&t.firstByte) },
+	})
+}
+
+// Done marks the response as completely read.
+func (t *PhaseTracer) Done() {
+	t.record(&t.done)
+}
+
+// Timings returns the durations of the phases recorded so far.
+func (t *PhaseTracer) Timings() PhaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Connection is ready to send the request once it's established and the
+	// TLS handshake is completed, if any.
+	ready := t.gotConn
+	if t.tlsDone.After(ready) {
+		ready = t.tlsDone
+	}
+
+	return PhaseTimings{
+		DNSLookup:       since(t.dnsStart, t.dnsDone),
+		TCPConnect:      since(t.connectStart, t.connectDone),
+		TLSHandshake:    since(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: since(ready, t.firstByte),
+		ContentTransfer: since(t.firstByte, t.done),
+	}
+}
+
+// record sets the current time into the field.
+func (t *PhaseTracer) record(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+// recordOnce sets the current time into the field only if it's not set.
+func (t *PhaseTracer) recordOnce(field *time.Time) {
+	t.mu.Lock()
+	if field.IsZero() {
+		*field = time.Now()
+	}
+	t.mu.Unlock()
+}
+
+// since returns the duration between start and end. If either of them is
+// not recorded, it returns 0.
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
@@ -11,8 +11,12 @@ type Check interface {
 	GetInterval() time.Duration // Returns the interval after which check is run.
 	GetTimeout() time.Duration  // Returns the timeout.
 
+	GetRetries() int                 // Returns the number of retries on failure.
+	GetRetryInterval() time.Duration // Returns the interval between retries.
+
 	GetInput() Component      // Returns the input.
-	GetOutput() Component     // Returns the output.
+	GetOutputs() []Component  // Returns the outputs.
+	GetOperator() string      // Returns the operator combining the outputs.
 	GetTarget() Component     // Returns the target.
 	GetPayloads() []Component // Returns the payloads.
 }
@@ -25,14 +29,23 @@ type Component interface {
 }
 
 // Metric is anything that tells if the check is successful or not. It also
-// tells if the check timed out, the start time and duration of check.
+// tells if the check timed out or was degraded, the start time and duration
+// of check, the reason of failure in case the check failed, the measurements
+// recorded during the check and the location of the agent it ran from.
 type Metric interface {
 	GetCheckID() string
 	GetCheckName() string
+	GetLocation() string
 
 	IsSuccessful() bool
 	IsTimeout() bool
+	IsDegraded() bool
 
 	GetStartTime() time.Time
 	GetDuration() time.Duration
+
+	GetFailureCode() string
+	GetFailureMessage() string
+
+	GetMeasurements() map[string]interface{}
 }
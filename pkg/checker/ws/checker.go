@@ -40,9 +40,9 @@ func init() {
 
 // Checker sends an WS ECHO request and checks if a reply is returned.
 type Checker struct {
-	prober      *Prober
-	outputType  string
-	outputValue interface{}
+	prober   *Prober
+	operator string
+	outputs  []output
 }
 
 // Validate validates the check configuration.
@@ -63,8 +63,8 @@ func (c *Checker) Validate(check checker.Check) error {
 		headerType:     validatePayloadHeader,
 		messageType:    validateNil,
 	}
-	if err := checker.ValidateComponent(check.GetOutput(), validateOutputMap); err != nil {
-		return fmt.Errorf("input: %w", err)
+	if err := checker.ValidateOutputs(check, validateOutputMap); err != nil {
+		return fmt.Errorf("output: %w", err)
 	}
 
 	validateTargetMap := validationMap{"URL": validateTarget}
@@ -111,46 +111,49 @@ func (c *Checker) Provision(check checker.Check) error {
 		return err
 	}
 
-	var outputValue interface{}
-	outputType := check.GetOutput().GetType()
-	switch outputType {
-	case headerType:
-		k, v, err := extractKVPair(check.GetOutput().GetValue())
-		if err != nil {
-			return err
-		}
+	outputs := make([]output, 0, len(check.GetOutputs()))
+	for _, o := range check.GetOutputs() {
+		var value interface{}
+		switch o.GetType() {
+		case headerType:
+			k, v, err := extractKVPair(o.GetValue())
+			if err != nil {
+				return err
+			}
 
-		outputValue = kvPair{k: k, v: v}
+			value = kvPair{k: k, v: v}
 
-	case statusCodeType:
-		st, err := extractStatusCode(check.GetOutput().GetValue())
-		if err != nil {
-			return err
-		}
+		case statusCodeType:
+			st, err := extractStatusCode(o.GetValue())
+			if err != nil {
+				return err
+			}
 
-		outputValue = st
+			value = st
 
-	case bodyType:
-		outputValue = check.GetOutput().GetValue()
+		case bodyType:
+			value = o.GetValue()
 
-	case messageType:
-		// messages are split via "\n---\n", so multiple messages which are to be
-		// verified should be separated via the same.
-		// For example, if the response should have two messages -- "hello" and say
-		// "world", output should be:
-		// 	"hello\n---\nworld"
-		// OR
-		// 	hello
-		// 	---
-		// 	world
-		messages := strings.Split(check.GetOutput().GetValue(), splitDelim)
-		outputValue = messages
+		case messageType:
+			// messages are split via "\n---\n", so multiple messages which are to be
+			// verified should be separated via the same.
+			// For example, if the response should have two messages -- "hello" and say
+			// "world", output should be:
+			// 	"hello\n---\nworld"
+			// OR
+			// 	hello
+			// 	---
+			// 	world
+			value = strings.Split(o.GetValue(), splitDelim)
 
-	default:
+		default:
+		}
+
+		outputs = append(outputs, output{typ: o.GetType(), value: value})
 	}
 
-	c.outputType = outputType
-	c.outputValue = outputValue
+	c.operator = check.GetOperator()
+	c.outputs = outputs
 	c.prober = prober
 	return nil
 }
@@ -173,73 +176,104 @@ func (c *Checker) Execute(ctx context.Context) (*checker.Result, error) {
 	}
 
 	result.Timeout = false
-	switch c.outputType {
-	case timeoutType:
-		result.Successful = true
-
-	case bodyType:
-		body, ok := c.outputValue.(string)
-		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of body not a string")
+	result.Measurements = map[string]interface{}{
+		checker.MeasurementStatusCode:       probeResult.StatusCode,
+		checker.MeasurementMessagesReceived: len(probeResult.Response),
+	}
+	probeResult.Timings.AddMeasurements(result.Measurements)
+
+	// the body can only be read once, so it is read here before matching if
+	// any of the outputs require it.
+	var body string
+	for _, o := range c.outputs {
+		if o.typ != bodyType {
+			continue
 		}
+
 		buf := new(strings.Builder)
-		_, err := io.Copy(buf, probeResult.Body)
-		if err != nil {
+		if _, err := io.Copy(buf, probeResult.Body); err != nil {
 			return nil, fmt.Errorf("invalid buffer :%s", err)
 		}
-		resultBody := buf.String()
-		if resultBody == body {
-			result.Successful = true
+
+		body = buf.String()
+		break
+	}
+
+	result.Successful, err = checker.Match(c.operator, len(c.outputs), func(i int) (bool, error) {
+		return c.outputs[i].match(probeResult, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validationMap is an alias of map used for validating components.
+type validationMap = map[string]func(string) error
+
+// output is a provisioned output of the check.
+type output struct {
+	typ   string
+	value interface{}
+}
+
+// match tells if the probe result matches the output.
+func (o output) match(probeResult *ProbeResult, body string) (bool, error) {
+	switch o.typ {
+	case timeoutType:
+		return true, nil
+
+	case bodyType:
+		expected, ok := o.value.(string)
+		if !ok {
+			return false, fmt.Errorf("internal error: outputValue of body not a string")
 		}
 
+		return body == expected, nil
+
 	case statusCodeType:
-		st, ok := c.outputValue.(int)
+		st, ok := o.value.(int)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of status code not an int")
+			return false, fmt.Errorf("internal error: outputValue of status code not an int")
 		}
 
-		if st == probeResult.StatusCode {
-			result.Successful = true
-		}
+		return st == probeResult.StatusCode, nil
+
 	case messageType:
-		msgs, ok := c.outputValue.([]string)
+		msgs, ok := o.value.([]string)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of messages not []string")
+			return false, fmt.Errorf("internal error: outputValue of messages not []string")
 		}
 
-		if len(msgs) == len(probeResult.Response) {
-			validateMessages(msgs, probeResult, result)
-		}
+		return validateMessages(msgs, probeResult), nil
+
 	case headerType:
-		header, ok := c.outputValue.(kvPair)
+		header, ok := o.value.(kvPair)
 		if !ok {
-			return nil, fmt.Errorf("internal error: outputValue of header not a kvPair")
+			return false, fmt.Errorf("internal error: outputValue of header not a kvPair")
 		}
 
-		if probeResult.Headers.Get(header.k) == header.v {
-			result.Successful = true
-		}
+		return probeResult.Headers.Get(header.k) == header.v, nil
+
 	default:
+		return false, nil
 	}
-
-	return result, nil
 }
 
-// validationMap is an alias of map used for validating components.
-type validationMap = map[string]func(string) error
-
 // validateMessages validates if prober response is same as desired output
-func validateMessages(msgs []string, probeResult *ProbeResult, result *checker.Result) {
-	allEq := true
+func validateMessages(msgs []string, probeResult *ProbeResult) bool {
+	if len(msgs) != len(probeResult.Response) {
+		return false
+	}
+
 	for i := range msgs {
 		if msgs[i] != probeResult.Response[i] {
-			allEq = false
-			break
+			return false
 		}
 	}
-	if allEq {
-		result.Successful = true
-	}
+
+	return true
 }
 
 // validateTarget validates if the target value is a URL.
@@ -42,4 +42,12 @@
 // 	 "MESSAGE"   <non empty string>         Sent in order as in array
 // 	 "HEADER"    <"K=V" formatted header>   Header with key='K' and value='V'
 //
+// Measurements recorded for each check are "status_code" (of the upgrade
+// response) and "messages_received".
+//
+// Along with these, the duration of each phase of the request is recorded in
+// seconds as "dns_lookup_seconds", "tcp_connect_seconds",
+// "tls_handshake_seconds", "time_to_first_byte_seconds" and
+// "content_transfer_seconds".
+//
 package ws
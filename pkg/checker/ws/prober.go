@@ -59,13 +59,15 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 	probeCtx, cancel := context.WithTimeout(baseCtx, p.timeout)
 	defer cancel()
 
+	tracer := new(checker.PhaseTracer)
+
 	timeoutResult := &ProbeResult{
 		Timeout:   true,
 		StartTime: startTime,
 		Duration:  p.timeout,
 	}
 
-	conn, resp, err := p.dialWS(probeCtx)
+	conn, resp, err := p.dialWS(tracer.WithContext(probeCtx))
 	if err != nil {
 		if checker.ErrIsTimeout(err) {
 			return timeoutResult, nil
@@ -73,6 +75,7 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 
 		return nil, err
 	}
+	tracer.Done()
 
 	defer conn.Close()      //nolint:errcheck
 	defer resp.Body.Close() //nolint:errcheck
@@ -85,6 +88,7 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 			StatusCode: resp.StatusCode,
 			Body:       resp.Body,
 			Headers:    resp.Header,
+			Timings:    tracer.Timings(),
 		}, nil
 	}
 
@@ -132,6 +136,7 @@ func (p *Prober) Probe(ctx context.Context) (*ProbeResult, error) {
 		Body:       resp.Body,
 		Headers:    resp.Header,
 		Response:   response,
+		Timings:    tracer.Timings(),
 	}, nil
 }
 
@@ -222,4 +227,7 @@ type ProbeResult struct {
 	StatusCode int
 	Body       io.ReadCloser
 	Headers    http.Header
+
+	// Timings are the durations of the phases of the upgrade request.
+	Timings checker.PhaseTimings
 }
@@ -4,28 +4,28 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/sdslabs/kiwi"
-	"github.com/sdslabs/kiwi/values/hash"
-
 	"google.golang.org/grpc"
 
-	"github.com/sdslabs/kiwi/stdkiwi"
-
 	"github.com/sdslabs/pinger/pkg/alerter"
 	"github.com/sdslabs/pinger/pkg/checker"
 	"github.com/sdslabs/pinger/pkg/components/agent/proto"
 	"github.com/sdslabs/pinger/pkg/config"
 	"github.com/sdslabs/pinger/pkg/config/configfile"
 	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/exporter/wal"
+	"github.com/sdslabs/pinger/pkg/maintenance"
+	"github.com/sdslabs/pinger/pkg/statestore"
 	"github.com/sdslabs/pinger/pkg/util/appcontext"
 	"github.com/sdslabs/pinger/pkg/util/controller"
 )
 
 type alertMap struct {
 	a  map[string]map[string]alerter.Alert
+	t  map[string]threshold // thresholds for each check
 	mu sync.RWMutex
 }
 
@@ -39,24 +39,46 @@ func Run(ctx *appcontext.Context, conf *configfile.Agent) error {
 		return fmt.Errorf("interval should be > 0")
 	}
 
+	if conf.Stats.Capacity < 0 {
+		return fmt.Errorf("stats capacity should be >= 0")
+	}
+
+	if err := controller.ValidateOverflowPolicy(controller.OverflowPolicy(conf.Stats.Overflow)); err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+
+	configSchedules := make(maintenance.Schedules, 0, len(conf.Maintenance))
+	for i := range conf.Maintenance {
+		schedule, err := maintenance.NewSchedule(&conf.Maintenance[i])
+		if err != nil {
+			return fmt.Errorf("maintenance %d: %w", i, err)
+		}
+
+		configSchedules = append(configSchedules, schedule)
+	}
+
+	// maintenance windows of the checks pushed by the central server are
+	// added to the ones in the config.
+	schedules := newSchedules(configSchedules)
+
 	manager := controller.NewManager(ctx)
 
-	export, getMetrics, err := exporter.Initialize(ctx, &conf.Metrics)
+	buffers, getMetrics, getAggregates, retention, forget, err := initExporters(ctx, conf)
 	if err != nil {
-		return fmt.Errorf("cannot initialize exporter: %w", err)
+		return err
 	}
 
-	// alertPrevState stores the last state of the check for a particular CheckID in a has map.
-	const alertPrevStateKey = "alertPrevState"
-	store, err := stdkiwi.NewStoreFromSchema(kiwi.Schema{
-		alertPrevStateKey: hash.Type,
-	})
+	// alertPrevState stores the alert state of the check for a particular
+	// CheckID so that the alerts are not sent again on restarts.
+	alertPrevState, err := statestore.Initialize(ctx, &conf.State)
 	if err != nil {
-		return fmt.Errorf("cannot initialize store: %w", err)
+		return fmt.Errorf("cannot initialize state store: %w", err)
 	}
-	alertPrevState := store.Hash(alertPrevStateKey)
 
-	aMap := alertMap{a: map[string]map[string]alerter.Alert{}}
+	aMap := alertMap{
+		a: map[string]map[string]alerter.Alert{},
+		t: map[string]threshold{},
+	}
 	alertFuncs := map[string]alerter.AlertFunc{}
 	for i := range conf.Alerts {
 		ap := conf.Alerts[i]
@@ -74,7 +96,43 @@ func Run(ctx *appcontext.Context, conf *configfile.Agent) error {
 		aMap.a[ap.Service] = map[string]alerter.Alert{}
 	}
 
-	err = initExportAndAlerts(ctx, conf.Interval, manager, export, alertFuncs, &aMap, alertPrevState)
+	if conf.Consensus.Quorum > 1 {
+		if conf.Location == "" {
+			return fmt.Errorf("consensus: location cannot be empty")
+		}
+
+		if getMetrics == nil {
+			return fmt.Errorf("consensus: metrics of other locations need an exporter to be read from")
+		}
+
+		if len(conf.Consensus.Locations) < conf.Consensus.Quorum {
+			return fmt.Errorf("consensus: quorum cannot be more than the number of locations")
+		}
+
+		found := false
+		for _, l := range conf.Consensus.Locations {
+			found = found || l == conf.Location
+		}
+
+		if !found {
+			return fmt.Errorf("consensus: location %q is not one of the locations", conf.Location)
+		}
+	}
+
+	cons := newConsensus(conf, getMetrics)
+
+	err = initExportAndAlerts(
+		ctx,
+		conf.Interval,
+		conf.Location,
+		manager,
+		buffers,
+		alertFuncs,
+		&aMap,
+		alertPrevState,
+		schedules,
+		cons,
+	)
 	if err != nil {
 		return fmt.Errorf("cannot initialize exporter: %w", err)
 	}
@@ -83,13 +141,14 @@ func Run(ctx *appcontext.Context, conf *configfile.Agent) error {
 	// that the checks will be run always irrespective of the fact that agent
 	// running in standalone mode or not.
 	for i := range conf.Checks {
-		if err := addCheckToManager(manager, &aMap, &conf.Checks[i]); err != nil {
+		if err := addCheckToManager(manager, &aMap, &conf.Stats, &conf.Checks[i]); err != nil {
 			return fmt.Errorf("check %d: cannot add to manager: %w", i, err)
 		}
 	}
 
 	if conf.Page.Deploy {
-		if err := serveStatusPage(ctx, &conf.Page, manager, getMetrics); err != nil {
+		err = serveStatusPage(ctx, &conf.Page, manager, schedules, getMetrics, getAggregates, retention, cons)
+		if err != nil {
 			return fmt.Errorf("cannot serve status page: %w", err)
 		}
 	}
@@ -100,26 +159,152 @@ func Run(ctx *appcontext.Context, conf *configfile.Agent) error {
 		return nil
 	}
 
-	return runGRPCServer(manager, &aMap, conf.Port)
+	return runGRPCServer(manager, &aMap, alertPrevState, &conf.Stats, schedules, forget, conf.Port)
+}
+
+// initExporters initializes the exporters each with its own buffer so that
+// an exporter failing does not affect the others. It returns the buffers,
+// and the getter, the aggregator and the retention for the exporter the
+// metrics are read from, which is the first exporter unless one is marked to
+// be read from. The returned function drops the metrics the exporters keep
+// in memory for a removed check.
+func initExporters(
+	ctx *appcontext.Context,
+	conf *configfile.Agent,
+) ([]*wal.Buffer, exporter.GetterFunc, exporter.AggregatorFunc, time.Duration, func(string), error) {
+	providers := conf.Exporters
+	if len(providers) == 0 {
+		providers = []config.MetricsProvider{conf.Metrics}
+	}
+
+	var (
+		buffers       []*wal.Buffer
+		exports       []exporter.ExportFunc
+		exporters     []exporter.Exporter
+		getMetrics    exporter.GetterFunc
+		getAggregates exporter.AggregatorFunc
+		retention     time.Duration
+		// getter, aggregator and retention of the first exporter in case
+		// none is marked to be read.
+		firstGetMetrics    exporter.GetterFunc
+		firstGetAggregates exporter.AggregatorFunc
+		firstRetention     time.Duration
+		names              = map[string]struct{}{}
+	)
+
+	for i := range providers {
+		provider := &providers[i]
+
+		name := provider.GetName()
+		if _, ok := names[name]; ok {
+			return nil, nil, nil, 0, nil, fmt.Errorf("exporter %q already configured", name)
+		}
+		names[name] = struct{}{}
+
+		e, err := exporter.New(ctx, provider)
+		if err != nil {
+			return nil, nil, nil, 0, nil, fmt.Errorf("exporter %q: cannot initialize: %w", name, err)
+		}
+
+		// buffer holds the metrics until they are exported so that they are
+		// not lost when the metrics backend is unavailable.
+		var dir string
+		if conf.Buffer.Path != "" {
+			dir = filepath.Join(conf.Buffer.Path, name)
+		}
+
+		buffer, err := wal.Open(ctx, &wal.Options{
+			Name:       name,
+			Dir:        dir,
+			MaxBackoff: conf.Buffer.MaxBackoff,
+			MaxSize:    conf.Buffer.MaxSize,
+			MaxAge:     conf.Buffer.MaxAge,
+		})
+		if err != nil {
+			return nil, nil, nil, 0, nil, fmt.Errorf("exporter %q: cannot initialize buffer: %w", name, err)
+		}
+
+		if provider.IsRead() {
+			if getMetrics != nil {
+				return nil, nil, nil, 0, nil, fmt.Errorf("exporter %q: only one exporter can be read from", name)
+			}
+
+			getMetrics = buffer.Getter(e.GetMetrics)
+			getAggregates = buffer.Aggregator(e.GetAggregates)
+			retention = exporter.GetRetention(e)
+		}
+
+		if i == 0 {
+			firstGetMetrics = buffer.Getter(e.GetMetrics)
+			firstGetAggregates = buffer.Aggregator(e.GetAggregates)
+			firstRetention = exporter.GetRetention(e)
+		}
+
+		buffers = append(buffers, buffer)
+		exports = append(exports, e.Export)
+		exporters = append(exporters, e)
+	}
+
+	if getMetrics == nil {
+		getMetrics = firstGetMetrics
+		getAggregates = firstGetAggregates
+		retention = firstRetention
+	}
+
+	for i := range buffers {
+		go buffers[i].Run(exports[i])
+	}
+
+	forget := func(checkID string) {
+		for _, e := range exporters {
+			exporter.Forget(e, checkID)
+		}
+	}
+
+	return buffers, getMetrics, getAggregates, retention, forget, nil
 }
 
 // initExportAndAlerts initializes the controller for exporting and alerting
-// the metrics.
+// the metrics. Metrics of the runs during a maintenance are tagged with the
+// name of the maintenance.
+//
+// With consensus, the alerts are decided from the metrics of all the
+// locations of the check rather than of the agent alone.
 func initExportAndAlerts(
 	ctx *appcontext.Context,
 	interval time.Duration,
+	location string,
 	manager *controller.Manager,
-	exportFunc exporter.ExportFunc,
+	buffers []*wal.Buffer,
 	alertFuncs map[string]alerter.AlertFunc,
 	aMap *alertMap,
-	alertPrevState *stdkiwi.Hash,
+	alertPrevState statestore.Store,
+	schedules *schedules,
+	cons *consensus,
 ) error {
+	// overflowed keeps the overflow counters of checks as of the last export
+	// so that only the new overflows are reported.
+	overflowed := map[string]controller.OverflowStats{}
+
 	ctrl, err := controller.NewController(ctx, &controller.Opts{
 		Name:     "metrics_export_and_alert",
 		Interval: interval,
 		Func: func(c context.Context) (interface{}, error) {
 			ctx.Logger().Infoln("exporting metrics")
 			stats := manager.PullAllStats()
+			reportOverflows(ctx, manager, overflowed)
+
+			checkIDs := make([]string, 0, len(stats))
+			for _, stat := range stats {
+				for _, s := range stat {
+					if s != nil {
+						checkIDs = append(checkIDs, s.ID)
+						break
+					}
+				}
+			}
+			others := cons.fetch(ctx, checkIDs)
+
 			var (
 				exportMetrics []checker.Metric
 				alertMetrics  []checker.Metric
@@ -132,35 +317,56 @@ func initExportAndAlerts(
 						continue
 					}
 
-					var metric config.Metric
-					if s.Err != nil {
-						// on error record the failed metric.
-						metric = config.Metric{
-							CheckID:   s.ID,
-							CheckName: s.Name,
-						}
-					} else {
-						res, ok := s.Res.(*checker.Result)
-						if !ok {
+					res, ok := s.Res.(*checker.Result)
+					if !ok || res == nil {
+						if s.Err == nil {
 							ctx.Logger().
 								WithField("check_id", s.ID).
 								Warnln("unexpected error: check result not checker.Result")
 							continue
 						}
 
-						metric = config.Metric{
-							CheckID:    s.ID,
-							CheckName:  s.Name,
-							Successful: res.Successful,
-							Timeout:    res.Timeout,
-							StartTime:  res.StartTime,
-							Duration:   res.Duration,
+						// on error without a result, record the failed metric with
+						// the error as the reason.
+						res = &checker.Result{
+							FailureCode:    checker.FailureError,
+							FailureMessage: s.Err.Error(),
 						}
 					}
 
+					metric := config.Metric{
+						CheckID:        s.ID,
+						CheckName:      s.Name,
+						Location:       location,
+						Successful:     res.Successful,
+						Timeout:        res.Timeout,
+						Degraded:       res.Degraded,
+						StartTime:      res.StartTime,
+						Duration:       res.Duration,
+						FailureCode:    res.FailureCode,
+						FailureMessage: res.FailureMessage,
+						Measurements:   res.Measurements,
+					}
+
+					// metrics during a maintenance are not alerted, so there is
+					// nothing to agree upon.
+					alertMetric, leader := checker.Metric(&metric), true
+					if o, ok := schedules.active(s.ID, res.StartTime); ok {
+						if metric.Measurements == nil {
+							metric.Measurements = map[string]interface{}{}
+						}
+						metric.Measurements[checker.MeasurementMaintenance] = o.Name()
+					} else {
+						alertMetric, leader = cons.evaluate(&metric, others[s.ID])
+					}
+
 					exportMetrics = append(exportMetrics, &metric)
 
-					shouldAlert, err := shouldUpdateAlert(alertPrevState, &lastTimestamp, &metric)
+					aMap.mu.RLock()
+					th := aMap.t[s.ID]
+					aMap.mu.RUnlock()
+
+					shouldAlert, err := shouldUpdateAlert(c, alertPrevState, &lastTimestamp, alertMetric, th)
 					if err != nil {
 						ctx.Logger().
 							WithField("check_id", s.ID).WithError(err).
@@ -168,24 +374,36 @@ func initExportAndAlerts(
 						continue
 					}
 
-					if shouldAlert {
-						alertMetrics = append(alertMetrics, &metric)
+					if shouldAlert && leader {
+						alertMetrics = append(alertMetrics, alertMetric)
 					}
 				}
 			}
 
-			// Export metrics into the database
-			if er := exportFunc(ctx, exportMetrics); er != nil {
-				ctx.Logger().
-					WithError(er).
-					Errorln("error exporting metrics")
-				return nil, er
+			// Buffer metrics to be exported into each of the databases. An
+			// exporter failing should not stop the metrics from reaching the
+			// rest of them.
+			var exportErr error
+			for _, buffer := range buffers {
+				if er := buffer.Append(exportMetrics); er != nil {
+					ctx.Logger().
+						WithError(er).
+						WithField("buffer", buffer.Name()).
+						Errorln("error buffering metrics")
+					exportErr = er
+				}
 			}
 
 			// Alert metrics from the corresponding services
 			for alertService, alertFunc := range alertFuncs {
+				// copy the alerts of the service since checks can be added or
+				// removed while alerting.
 				aMap.mu.RLock()
-				serviceAlertMap, ok := (aMap.a)[alertService]
+				alerts, ok := (aMap.a)[alertService]
+				serviceAlertMap := make(map[string]alerter.Alert, len(alerts))
+				for id, alt := range alerts {
+					serviceAlertMap[id] = alt
+				}
 				aMap.mu.RUnlock()
 				if !ok {
 					er := fmt.Errorf("could not find alerts for service %q", alertService)
@@ -201,7 +419,7 @@ func initExportAndAlerts(
 				}
 			}
 
-			return nil, nil
+			return nil, exportErr
 		},
 	})
 	if err != nil {
@@ -213,10 +431,19 @@ func initExportAndAlerts(
 }
 
 // shouldUpdateAlert tells if an alert should be sent for the particular metric.
+//
+// An alert is sent only when the state of the check has changed for the
+// threshold number of consecutive metrics.
+//
+// Metrics of the runs during a maintenance are not alerted and leave the
+// state as it is, so that an alert is sent after the maintenance only if the
+// state of the check is different from before it.
 func shouldUpdateAlert(
-	alertPrevState *stdkiwi.Hash,
+	ctx context.Context,
+	alertPrevState statestore.Store,
 	lastTimestamp *time.Time,
 	metric checker.Metric,
+	th threshold,
 ) (update bool, _ error) {
 	if lastTimestamp.After(metric.GetStartTime()) {
 		// If the last time stamp is after the metric, we have a stale metric
@@ -224,26 +451,27 @@ func shouldUpdateAlert(
 		return false, nil
 	}
 
-	prevState := boolNil // if nothing is fetched, first alert will be sent
-	newState := newBoolean(metric.IsSuccessful())
+	if _, ok := metric.GetMeasurements()[checker.MeasurementMaintenance]; ok {
+		*lastTimestamp = metric.GetStartTime()
+		return false, nil
+	}
+
+	state := newAlertState() // if nothing is fetched, first alert will be sent
 
-	has, err := alertPrevState.Has(metric.GetCheckID())
+	v, has, err := alertPrevState.Get(ctx, metric.GetCheckID())
 	if err != nil {
 		return false, err
 	}
 	if has {
-		v, er := alertPrevState.Get(metric.GetCheckID())
-		if er != nil {
-			return false, er
+		state, err = parseAlertState(v)
+		if err != nil {
+			return false, err
 		}
-		prevState = boolean(v[0])
 	}
 
-	if newState == prevState {
-		return false, nil
-	}
+	update = state.observe(newCheckState(metric), th)
 
-	err = alertPrevState.Insert(metric.GetCheckID(), string(newState))
+	err = alertPrevState.Set(ctx, metric.GetCheckID(), state.String())
 	if err != nil {
 		return false, err
 	}
@@ -251,12 +479,53 @@ func shouldUpdateAlert(
 	// update the last time stamp only when there is no error or else let the
 	// last metric to be the same as before.
 	*lastTimestamp = metric.GetStartTime()
-	return true, nil
+	return update, nil
+}
+
+// reportOverflows logs the checks whose stats overflowed the capacity since
+// the last report.
+func reportOverflows(
+	ctx *appcontext.Context,
+	manager *controller.Manager,
+	overflowed map[string]controller.OverflowStats,
+) {
+	current := manager.OverflowStats()
+	for id, ov := range current {
+		prev := overflowed[id]
+		if ov.Dropped == prev.Dropped && ov.Summarized == prev.Summarized {
+			continue
+		}
+
+		ctx.Logger().
+			WithField("check_id", id).
+			WithField("dropped", ov.Dropped-prev.Dropped).
+			WithField("summarized", ov.Summarized-prev.Summarized).
+			Warnln("stats overflowed the capacity, consider reducing the interval")
+	}
+
+	// forget the checks that were removed.
+	for id := range overflowed {
+		if _, ok := current[id]; !ok {
+			delete(overflowed, id)
+		}
+	}
+
+	for id, ov := range current {
+		overflowed[id] = ov
+	}
 }
 
 // runGRPCServer starts the GRPC server that exposes an API for the central
 // to contact the agent.
-func runGRPCServer(manager *controller.Manager, aMap *alertMap, port uint16) error {
+func runGRPCServer(
+	manager *controller.Manager,
+	aMap *alertMap,
+	alertPrevState statestore.Store,
+	stats *configfile.AgentStats,
+	schedules *schedules,
+	forget func(checkID string),
+	port uint16,
+) error {
 	addr := net.JoinHostPort("0.0.0.0", fmt.Sprint(port))
 
 	lst, err := net.Listen("tcp", addr)
@@ -266,8 +535,12 @@ func runGRPCServer(manager *controller.Manager, aMap *alertMap, port uint16) err
 
 	grpcServer := grpc.NewServer()
 	proto.RegisterAgentServer(grpcServer, &server{
-		m: manager,
-		a: aMap,
+		m:  manager,
+		a:  aMap,
+		st: alertPrevState,
+		s:  stats,
+		sc: schedules,
+		f:  forget,
 	})
 
 	err = grpcServer.Serve(lst)
@@ -282,6 +555,7 @@ func runGRPCServer(manager *controller.Manager, aMap *alertMap, port uint16) err
 func addCheckToManager(
 	manager *controller.Manager,
 	aMap *alertMap,
+	stats *configfile.AgentStats,
 	check *config.Check,
 ) error {
 	ctrlOpts, err := checker.NewControllerOpts(check)
@@ -289,6 +563,18 @@ func addCheckToManager(
 		return err
 	}
 
+	ctrlOpts.Capacity = stats.Capacity
+	if stats.Overflow != "" {
+		ctrlOpts.Overflow = controller.OverflowPolicy(stats.Overflow)
+	}
+
+	aMap.mu.Lock()
+	aMap.t[check.ID] = threshold{
+		failure: check.GetFailureThreshold(),
+		success: check.GetSuccessThreshold(),
+	}
+	aMap.mu.Unlock()
+
 	for i := range check.Alerts {
 		alt := check.Alerts[i]
 
@@ -306,3 +592,32 @@ func addCheckToManager(
 
 	return manager.UpdateController(ctrlOpts)
 }
+
+// removeCheckFromManager removes the check from the manager along with its
+// thresholds, alerts, alert state and the metrics kept in memory by the
+// exporters, so that they are not reused if a check with the same ID is added
+// again.
+func removeCheckFromManager(
+	ctx context.Context,
+	manager *controller.Manager,
+	aMap *alertMap,
+	alertPrevState statestore.Store,
+	forget func(checkID string),
+	checkID string,
+) error {
+	manager.RemoveController(checkID)
+	forget(checkID)
+
+	aMap.mu.Lock()
+	delete(aMap.t, checkID)
+	for service := range aMap.a {
+		delete(aMap.a[service], checkID)
+	}
+	aMap.mu.Unlock()
+
+	if err := alertPrevState.Delete(ctx, checkID); err != nil {
+		return fmt.Errorf("cannot delete alert state: %w", err)
+	}
+
+	return nil
+}
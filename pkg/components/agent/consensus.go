@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// consensus decides the status of the checks to alert from the metrics of
+// all the locations they run from, which are read back from the exporter.
+//
+// A nil consensus decides the status from the metrics of the agent alone.
+type consensus struct {
+	checker.Consensus
+
+	location   string
+	getMetrics exporter.GetterFunc
+}
+
+// newConsensus creates the consensus for the agent. It is nil unless more
+// than one location should agree for a check to be down.
+func newConsensus(conf *configfile.Agent, getMetrics exporter.GetterFunc) *consensus {
+	if conf.Consensus.Quorum <= 1 {
+		return nil
+	}
+
+	return &consensus{
+		Consensus: checker.Consensus{
+			Quorum:    conf.Consensus.Quorum,
+			Window:    conf.Consensus.Window,
+			Locations: conf.Consensus.Locations,
+		},
+		location:   conf.Location,
+		getMetrics: getMetrics,
+	}
+}
+
+// fetch returns the recent metrics of the checks from the other locations.
+//
+// If the metrics cannot be read, the status of the checks is decided by the
+// agent alone rather than not alerting at all.
+func (c *consensus) fetch(ctx *appcontext.Context, checkIDs []string) map[string][]checker.Metric {
+	if c == nil || len(checkIDs) == 0 {
+		return nil
+	}
+
+	metrics, err := c.getMetrics(ctx, c.Window, checkIDs...)
+	if err != nil {
+		ctx.Logger().
+			WithError(err).
+			Warnln("cannot get metrics of other locations, alerting without consensus")
+		return nil
+	}
+
+	for id, ms := range metrics {
+		others := make([]checker.Metric, 0, len(ms))
+		for _, m := range ms {
+			if m.GetLocation() != c.location {
+				others = append(others, m)
+			}
+		}
+
+		metrics[id] = others
+	}
+
+	return metrics
+}
+
+// evaluate returns the metric to alert for the metric of the agent, along
+// with whether the agent sends the alerts for the check.
+//
+// Every location keeps track of the status of the check but only the first
+// of the configured locations that voted sends the alerts so that the same
+// alert is not sent from each of them. Since the locations are in the same
+// order for all the agents, they agree on the leader as long as they see
+// the same votes.
+func (c *consensus) evaluate(metric checker.Metric, others []checker.Metric) (checker.Metric, bool) {
+	if c == nil {
+		return metric, true
+	}
+
+	verdict := c.Evaluate(append([]checker.Metric{metric}, others...), metric.GetStartTime())
+	if verdict == nil {
+		return metric, true
+	}
+
+	return verdict, verdict.Locations[0].GetLocation() == c.location
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/maintenance"
+)
+
+// schedules are the maintenance schedules of the agent, which are the ones
+// in its config along with the ones pushed with each of the checks by the
+// central server.
+type schedules struct {
+	config maintenance.Schedules
+
+	mu     sync.RWMutex
+	pushed map[string]maintenance.Schedules
+}
+
+// newSchedules creates the schedules with the ones in the config.
+func newSchedules(config maintenance.Schedules) *schedules {
+	return &schedules{
+		config: config,
+		pushed: map[string]maintenance.Schedules{},
+	}
+}
+
+// set replaces the schedules pushed with the check. The check is removed
+// when there are none.
+func (s *schedules) set(checkID string, pushed maintenance.Schedules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(pushed) == 0 {
+		delete(s.pushed, checkID)
+		return
+	}
+
+	s.pushed[checkID] = pushed
+}
+
+// all returns all the schedules of the agent.
+func (s *schedules) all() maintenance.Schedules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make(maintenance.Schedules, 0, len(s.config)+len(s.pushed))
+	all = append(all, s.config...)
+	for _, pushed := range s.pushed {
+		all = append(all, pushed...)
+	}
+
+	return all
+}
+
+// active returns the occurrence of a maintenance the check is under at the
+// time, if any.
+func (s *schedules) active(checkID string, t time.Time) (maintenance.Occurrence, bool) {
+	if o, ok := s.config.Active(checkID, t); ok {
+		return o, true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.pushed[checkID].Active(checkID, t)
+}
@@ -6,7 +6,9 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sdslabs/pinger/pkg/components/agent/ui/static"
@@ -15,8 +17,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/sdslabs/pinger/pkg/checker"
 	"github.com/sdslabs/pinger/pkg/config/configfile"
 	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/maintenance"
 	"github.com/sdslabs/pinger/pkg/util/appcontext"
 	"github.com/sdslabs/pinger/pkg/util/controller"
 	"github.com/sdslabs/pinger/pkg/util/httpserver"
@@ -38,6 +42,18 @@ const (
 	// minMetricsBatches are the minimum possible number of batches.
 	minMetricsBatches = 2
 
+	// maxSLAPrecision is the maximum number of decimal places of the uptime
+	// and the SLA.
+	maxSLAPrecision = 6
+
+	// slaBuckets is the number of buckets the window of an SLA is divided
+	// into. Time is missing only if there is no run in the whole bucket.
+	slaBuckets = 1440
+
+	// maintenanceLookahead is how far ahead the maintenance windows are
+	// shown on the status page.
+	maintenanceLookahead = 7 * (24 * time.Hour) // 1 week
+
 	// routeStatic is the route for all static content.
 	routeStatic = "/static"
 
@@ -63,12 +79,24 @@ func serveStatusPage(
 	ctx *appcontext.Context,
 	conf *configfile.AgentPage,
 	manager *controller.Manager,
+	schedules *schedules,
 	getMetrics exporter.GetterFunc,
+	getAggregates exporter.AggregatorFunc,
+	retention time.Duration,
+	cons *consensus,
 ) error {
 	if !conf.Deploy {
 		return errors.New("cannot deploy agent with AgentPage.Deploy=false")
 	}
 
+	if err := validateSLA(&conf.SLA); err != nil {
+		return err
+	}
+
+	if err := validateRetention(&conf.SLA, retention); err != nil {
+		return err
+	}
+
 	router := httpserver.NewRouter(ctx, httpserver.RouterOpts{
 		AllowedOrigins: conf.AllowedOrigins,
 		AllowedMethods: []string{http.MethodGet},
@@ -78,7 +106,7 @@ func serveStatusPage(
 		return err
 	}
 
-	addMetricsRoute(ctx, router, manager, getMetrics)
+	addMetricsRoute(ctx, router, manager, conf, schedules, getMetrics, getAggregates, retention, cons)
 
 	router.StaticFS(routeStatic, http.FS(static.FS))
 
@@ -142,15 +170,93 @@ func addBaseRoute(
 	return nil
 }
 
+// validateSLA validates the SLA configuration of the page.
+func validateSLA(conf *configfile.AgentSLA) error {
+	if conf.Precision < 0 || conf.Precision > maxSLAPrecision {
+		return fmt.Errorf("SLA precision should be between 0 and %d", maxSLAPrecision)
+	}
+
+	if err := metricsutil.ValidateMissingPolicy(metricsutil.MissingPolicy(conf.Missing)); err != nil {
+		return err
+	}
+
+	for _, w := range conf.Windows {
+		if _, err := metricsutil.ParseWindow(w, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRetention validates that the metrics are retained for the longest
+// of the windows shown on the page, since the aggregates are computed from the
+// raw metrics and the time after they are dropped would be missing. A
+// retention of 0 keeps the metrics forever.
+func validateRetention(conf *configfile.AgentSLA, retention time.Duration) error {
+	if retention == 0 {
+		return nil
+	}
+
+	if retention < maxMetricsDuration {
+		return fmt.Errorf("retention of metrics should be >= %s shown on the page", maxMetricsDuration)
+	}
+
+	for _, w := range conf.Windows {
+		if err := validateWindowRetention(w, retention); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateWindowRetention validates that the metrics are retained for the
+// longest the window of the period can be.
+func validateWindowRetention(period string, retention time.Duration) error {
+	longest, err := metricsutil.LongestWindow(period)
+	if err != nil {
+		return err
+	}
+
+	if retention != 0 && retention < longest {
+		return fmt.Errorf("SLA window %q can be longer than the retention of metrics %s", period, retention)
+	}
+
+	return nil
+}
+
 // addMetricsRoute adds the route that fetches metrics for all the checks
 // running on the agent.
+//
+// The metrics are aggregated by the exporter into a batch each, except the
+// first batch which is the latest metric of the check. Only the metrics of
+// the latest batch are fetched to find the latest metric. When the check
+// runs from multiple locations, its latest metric is the verdict of their
+// latest metrics by consensus.
+//
+// The SLA of each check is computed over the windows in the config, unless
+// other windows are requested as a comma separated list, for example,
+// `sla=30d,month`, which cannot be longer than the retention of the metrics.
+// Maintenance of the checks is left out of their uptime and SLA.
 func addMetricsRoute(
 	ctx *appcontext.Context,
 	router *gin.Engine,
 	manager *controller.Manager,
+	conf *configfile.AgentPage,
+	schedules *schedules,
 	getMetrics exporter.GetterFunc,
+	getAggregates exporter.AggregatorFunc,
+	retention time.Duration,
+	cons *consensus,
 ) {
-	// `/metrics?duration=1000000000?batches=30`
+	// without consensus, the check is down if any of its locations is down.
+	var verdicts checker.Consensus
+	if cons != nil {
+		verdicts = cons.Consensus
+	}
+
+	// `/metrics?duration=1000000000?batches=30?sla=30d,month?precision=3`
 	router.GET(routeMetrics, func(c *gin.Context) {
 		durationStr := c.Query("duration")
 		durationInt, err := strconv.Atoi(durationStr)
@@ -170,12 +276,205 @@ func addMetricsRoute(
 		for checkID := range checksMap {
 			checkIDs = append(checkIDs, checkID)
 		}
-		metrics, err := getMetrics(ctx, duration, checkIDs...)
+		if batches < minMetricsBatches {
+			batches = minMetricsBatches
+		}
+
+		opts := &metricsutil.SLAOptions{
+			Missing:   metricsutil.MissingPolicy(conf.SLA.Missing),
+			Precision: conf.SLA.Precision,
+		}
+
+		if precisionStr := c.Query("precision"); precisionStr != "" {
+			precision, er := strconv.Atoi(precisionStr)
+			if er != nil || precision < 0 || precision > maxSLAPrecision {
+				httpserver.RespondError(ctx, c, http.StatusBadRequest,
+					fmt.Errorf("precision should be between 0 and %d", maxSLAPrecision))
+				return
+			}
+			opts.Precision = precision
+		}
+
+		periods := conf.SLA.Windows
+		if slaStr := c.Query("sla"); slaStr != "" {
+			periods = strings.Split(slaStr, ",")
+			for _, period := range periods {
+				if er := validateWindowRetention(period, retention); er != nil {
+					httpserver.RespondError(ctx, c, http.StatusBadRequest, er)
+					return
+				}
+			}
+		}
+
+		now := time.Now()
+		windows := make([]metricsutil.Window, 0, len(periods))
+		for _, period := range periods {
+			window, er := metricsutil.ParseWindow(period, now)
+			if er != nil {
+				httpserver.RespondError(ctx, c, http.StatusBadRequest, er)
+				return
+			}
+			windows = append(windows, window)
+		}
+
+		// first batch is the latest metric.
+		start, width := exporter.BucketStart(duration, batches-1)
+		aggregates, err := getAggregates(ctx, duration, batches-1, checkIDs...)
+		if err != nil {
+			httpserver.RespondErrorInternalServer(ctx, c, err)
+			return
+		}
+
+		latestWidth := width
+		if verdicts.Window > latestWidth {
+			latestWidth = verdicts.Window
+		}
+
+		latest, err := getMetrics(ctx, latestWidth, checkIDs...)
 		if err != nil {
 			httpserver.RespondErrorInternalServer(ctx, c, err)
 			return
 		}
 
-		httpserver.RespondOK(ctx, c, metricsutil.PrepareMetricsResponse(batches, metrics))
+		// the latest metric of the check is the verdict of its locations.
+		for cid, ms := range latest {
+			if v := verdicts.Evaluate(ms, now); v != nil {
+				latest[cid] = []checker.Metric{v}
+			}
+		}
+
+		// maintenance windows pushed with the checks change as they are
+		// pushed again, so the same of them are used for the whole response.
+		maintenances := schedules.all()
+		window := metricsutil.Window{Start: start, End: now}
+		excluded := maintenanceWindows(maintenances, window, checkIDs)
+		resp := metricsutil.PrepareAggregatesResponse(latest, aggregates, verdicts, window, width, opts, excluded)
+
+		for i, period := range periods {
+			excluded := maintenanceWindows(maintenances, windows[i], checkIDs)
+			slas, er := getSLA(ctx, getAggregates, verdicts, windows[i], period, opts, excluded, checkIDs)
+			if er != nil {
+				httpserver.RespondErrorInternalServer(ctx, c, er)
+				return
+			}
+
+			for cid, check := range resp.Checks {
+				check.SLA = append(check.SLA, slas[cid])
+				resp.Checks[cid] = check
+			}
+		}
+
+		addMaintenance(&resp, maintenances, now)
+		httpserver.RespondOK(ctx, c, resp)
 	})
 }
+
+// getSLA computes the SLA of the checks over the window from the aggregates
+// of their metrics.
+//
+// The window is divided into buckets of the same width, with one bucket more
+// than required so that the buckets, which are aligned to their width, cover
+// the start of the window.
+func getSLA(
+	ctx *appcontext.Context,
+	getAggregates exporter.AggregatorFunc,
+	verdicts checker.Consensus,
+	window metricsutil.Window,
+	period string,
+	opts *metricsutil.SLAOptions,
+	excluded map[string][]metricsutil.Window,
+	checkIDs []string,
+) (map[string]httpserver.SLAResponse, error) {
+	width := exporter.BucketWidth(window.Duration(), slaBuckets)
+	aggregates, err := getAggregates(ctx, width*(slaBuckets+1), slaBuckets+1, checkIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return metricsutil.PrepareSLAResponse(period, window, width, aggregates, verdicts, opts, excluded, checkIDs...), nil
+}
+
+// maintenanceWindows returns the windows of maintenance of each of the checks
+// within the window.
+func maintenanceWindows(
+	schedules maintenance.Schedules,
+	window metricsutil.Window,
+	checkIDs []string,
+) map[string][]metricsutil.Window {
+	windows := make(map[string][]metricsutil.Window, len(checkIDs))
+	for _, cid := range checkIDs {
+		for _, o := range schedules.Occurrences(cid, window.Start, window.End) {
+			windows[cid] = append(windows[cid], metricsutil.Window{Start: o.Start, End: o.End})
+		}
+	}
+
+	return windows
+}
+
+// addMaintenance adds the ongoing or the next occurrence of each maintenance
+// to the response and marks the checks under maintenance. A check under
+// maintenance is not counted as down or degraded.
+//
+// A maintenance pushed with multiple checks has a schedule for each of them,
+// so the occurrences of the same name and time are shown once for all of
+// their checks.
+func addMaintenance(resp *httpserver.PageMetricsResponse, schedules maintenance.Schedules, now time.Time) {
+	for cid, check := range resp.Checks {
+		if _, ok := schedules.Active(cid, now); !ok {
+			continue
+		}
+
+		check.Maintenance = true
+		resp.Checks[cid] = check
+
+		if !check.Operational {
+			resp.ChecksDown--
+		} else if check.Degraded {
+			resp.ChecksDegraded--
+		}
+	}
+
+	for _, schedule := range schedules {
+		occurrences := schedule.Occurrences(now, now.Add(maintenanceLookahead))
+		if len(occurrences) == 0 {
+			continue
+		}
+
+		o := occurrences[0]
+		if i, ok := sameMaintenance(resp.Maintenance, &o); ok {
+			// a maintenance of all the checks stays so.
+			if len(resp.Maintenance[i].Checks) > 0 && len(o.Checks()) > 0 {
+				resp.Maintenance[i].Checks = append(resp.Maintenance[i].Checks, o.Checks()...)
+			} else {
+				resp.Maintenance[i].Checks = nil
+			}
+			continue
+		}
+
+		resp.Maintenance = append(resp.Maintenance, httpserver.MaintenanceResponse{
+			Name:        o.Name(),
+			Description: o.Description(),
+			Start:       o.Start,
+			End:         o.End,
+			Checks:      o.Checks(),
+			Active:      !o.Start.After(now),
+		})
+	}
+
+	sort.Slice(resp.Maintenance, func(i, j int) bool {
+		return resp.Maintenance[i].Start.Before(resp.Maintenance[j].Start)
+	})
+}
+
+// sameMaintenance returns the index of the maintenance in the response with
+// the same name and time as the occurrence, if any.
+func sameMaintenance(maintenances []httpserver.MaintenanceResponse, o *maintenance.Occurrence) (int, bool) {
+	for i := range maintenances {
+		m := &maintenances[i]
+		if m.Name == o.Name() && m.Start.Equal(o.Start) && m.End.Equal(o.End) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
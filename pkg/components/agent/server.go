@@ -2,17 +2,25 @@ package agent
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/sdslabs/pinger/pkg/components/agent/proto"
 	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/maintenance"
+	"github.com/sdslabs/pinger/pkg/statestore"
 	"github.com/sdslabs/pinger/pkg/util/controller"
 )
 
 // server is the GRPC server that exposes the API so that central server
 // can interact with the agent.
 type server struct {
-	m *controller.Manager
-	a *alertMap
+	m  *controller.Manager
+	a  *alertMap
+	st statestore.Store
+	s  *configfile.AgentStats
+	sc *schedules
+	f  func(checkID string)
 	// Unimplemented agent server for "forward compatibility".
 	proto.UnimplementedAgentServer
 }
@@ -33,22 +41,46 @@ func (s *server) ListChecks(context.Context, *proto.Nil) (*proto.CheckList, erro
 }
 
 // PushCheck creates a new check. If the check already exists it simply
-// updates the check.
+// updates the check along with its maintenance windows.
 func (s *server) PushCheck(_ context.Context, check *proto.Check) (*proto.BoolResponse, error) {
+	maintenances := config.ProtoToMaintenances(check)
+	schedules := make(maintenance.Schedules, 0, len(maintenances))
+	for i := range maintenances {
+		schedule, err := maintenance.NewSchedule(&maintenances[i])
+		if err != nil {
+			return &proto.BoolResponse{
+				Successful: false,
+				Error:      fmt.Sprintf("maintenance %d: %s", i, err),
+			}, nil
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
 	c := config.ProtoToCheck(check)
-	if err := addCheckToManager(s.m, s.a, &c); err != nil {
+	if err := addCheckToManager(s.m, s.a, s.s, &c); err != nil {
 		return &proto.BoolResponse{
 			Successful: false,
 			Error:      err.Error(),
 		}, nil
 	}
 
+	s.sc.set(check.ID, schedules)
 	return &proto.BoolResponse{Successful: true}, nil
 }
 
 // RemoveCheck removes the check.
-func (s *server) RemoveCheck(_ context.Context, cid *proto.CheckID) (*proto.BoolResponse, error) {
-	s.m.RemoveController(cid.ID)
+func (s *server) RemoveCheck(ctx context.Context, cid *proto.CheckID) (*proto.BoolResponse, error) {
+	// the check is stopped even if its alert state cannot be cleaned up, so
+	// its maintenance windows are removed first.
+	s.sc.set(cid.ID, nil)
+	if err := removeCheckFromManager(ctx, s.m, s.a, s.st, s.f, cid.ID); err != nil {
+		return &proto.BoolResponse{
+			Successful: false,
+			Error:      err.Error(),
+		}, nil
+	}
+
 	return &proto.BoolResponse{Successful: true}, nil
 }
 
@@ -1,3 +1,12 @@
+// Phases of an HTTP request and the key of their measurements.
+const PHASES = [
+  ["dns_lookup_seconds", "DNS Lookup"],
+  ["tcp_connect_seconds", "TCP Connect"],
+  ["tls_handshake_seconds", "TLS Handshake"],
+  ["time_to_first_byte_seconds", "Time to First Byte"],
+  ["content_transfer_seconds", "Content Transfer"],
+];
+
 $(document).ready(function () {
   $.ajax({
     url: METRICS_URL,
@@ -6,8 +15,12 @@ $(document).ready(function () {
         const check = result.checks[checkId];
         const checkDiv = $("#check--"+checkId);
         const checkBarDiv = checkDiv.find(".main-check-bars");
-        if (!check.operational) {
+        if (check.maintenance) {
+          checkDiv.addClass("main-check-maintenance");
+        } else if (!check.operational) {
           checkDiv.addClass("main-check-failed");
+        } else if (check.degraded) {
+          checkDiv.addClass("main-check-degraded");
         } else {
           checkDiv.addClass("main-check-success");
         }
@@ -18,6 +31,8 @@ $(document).ready(function () {
           elem.addClass("main-check-bar");
           if (metric.timeout) {
             elem.addClass("main-check-bar-timeout");
+          } else if (metric.degraded) {
+            elem.addClass("main-check-bar-degraded");
           } else if (metric.successful) {
             elem.addClass("main-check-bar-success");
           } else {
@@ -25,9 +40,81 @@ $(document).ready(function () {
           }
           const startTime = (new Date(metric.start_time)).toUTCString();
           const duration = (metric.duration / 1_000_000_000).toFixed(3);
-          elem.attr("title", "Start Time: "+startTime+"\nDuration: "+duration+"s");
+          let title = "Start Time: "+startTime+"\nDuration: "+duration+"s";
+          if (metric.runs) {
+            title += "\nUptime: "+metric.uptime.toFixed(2)+"% of "+metric.runs+" runs";
+            title += "\nLatency (p50/p95/p99): "+
+              [metric.p50, metric.p95, metric.p99]
+                .map((d) => ((d || 0) / 1_000_000_000).toFixed(3)+"s")
+                .join(" / ");
+          }
+          if (metric.failure_code) {
+            title += "\nFailure: "+metric.failure_code;
+          }
+          if (metric.failure_message) {
+            title += "\nReason: "+metric.failure_message;
+          }
+          const measurements = metric.measurements || {};
+          for (const [key, phase] of PHASES) {
+            if (measurements[key]) {
+              title += "\n"+phase+": "+measurements[key].toFixed(3)+"s";
+            }
+          }
+          elem.attr("title", title);
           checkBarDiv.append(elem);
         }
+
+        // the latest status and the uptime of each location is shown only
+        // when the check runs from more than one of them.
+        const locations = check.locations || [];
+        if (locations.length > 1) {
+          const locationsDiv = checkDiv.find(".main-check-locations");
+          for (const location of locations) {
+            const elem = $("<div></div>");
+            elem.addClass("main-check-location");
+            if (location.timeout) {
+              elem.addClass("main-check-location-timeout");
+            } else if (location.degraded) {
+              elem.addClass("main-check-location-degraded");
+            } else if (location.successful) {
+              elem.addClass("main-check-location-success");
+            } else {
+              elem.addClass("main-check-location-failed");
+            }
+            const startTime = (new Date(location.start_time)).toUTCString();
+            let title = "Start Time: "+startTime;
+            if (location.failure_message) {
+              title += "\nReason: "+location.failure_message;
+            }
+            if (location.runs) {
+              title += "\nUptime: "+(location.uptime || 0).toFixed(2)+"% of "+location.runs+" runs";
+            }
+            elem.attr("title", title);
+            elem.text(location.location);
+            locationsDiv.append(elem);
+          }
+        }
+      }
+
+      const maintenanceDiv = $(".main-maintenance");
+      for (const maintenance of result.maintenance || []) {
+        const elem = $("<div></div>");
+        elem.addClass("main-maintenance-item");
+        let title = "Maintenance: "+maintenance.name;
+        if (maintenance.active) {
+          elem.addClass("main-maintenance-item-active");
+          title = "Under maintenance: "+maintenance.name;
+        } else {
+          elem.addClass("main-maintenance-item-upcoming");
+        }
+        const start = (new Date(maintenance.start)).toUTCString();
+        const end = (new Date(maintenance.end)).toUTCString();
+        elem.append($("<div></div>").addClass("main-maintenance-item-title").text(title));
+        elem.append($("<div></div>").addClass("main-maintenance-item-time").text(start+" to "+end));
+        if (maintenance.description) {
+          elem.append($("<div></div>").addClass("main-maintenance-item-desc").text(maintenance.description));
+        }
+        maintenanceDiv.append(elem);
       }
 
       const operationalDiv = $(".main-operational-status");
@@ -1,28 +1,143 @@
 package agent
 
-// boolean represents a bool variable in string format.
-type boolean string
+import (
+	"fmt"
+	"strconv"
+	"strings"
 
-// boolean constants.
+	"github.com/sdslabs/pinger/pkg/checker"
+)
+
+// checkState represents the state of a check in string format.
+type checkState string
+
+// checkState constants.
 const (
-	boolTrue  boolean = "true"
-	boolFalse boolean = "false"
-	boolNil   boolean = "nil"
+	stateUp       checkState = "up"
+	stateDown     checkState = "down"
+	stateDegraded checkState = "degraded"
+	stateNil      checkState = "nil"
 )
 
-// newBoolean returns a string bool.
-func newBoolean(from bool) boolean {
-	if from {
-		return boolTrue
+// newCheckState returns the state of the check from the metric.
+func newCheckState(metric checker.Metric) checkState {
+	switch {
+	case metric.IsDegraded():
+		return stateDegraded
+	case metric.IsSuccessful():
+		return stateUp
+	default:
+		return stateDown
 	}
-	return boolFalse
 }
 
-// True tells if the boolean string is true.
-func (b boolean) True() bool { return b == boolTrue }
+// isFailing tells if the state is one of the failing states, i.e., down or
+// degraded.
+func (s checkState) isFailing() bool {
+	return s == stateDown || s == stateDegraded
+}
 
-// False tells if the boolean string is false.
-func (b boolean) False() bool { return b == boolFalse }
+// worse returns the more severe of the two states. A check being down is
+// more severe than it being degraded.
+func worse(a, b checkState) checkState {
+	if a == stateDown || b == stateDown {
+		return stateDown
+	}
+
+	return stateDegraded
+}
+
+// threshold is the number of consecutive runs required to change the state
+// of a check.
+type threshold struct {
+	failure int // consecutive failed or degraded runs before down or degraded
+	success int // consecutive successful runs before up
+}
+
+// forState returns the threshold to move into the given state.
+func (t threshold) forState(state checkState) int {
+	n := t.failure
+	if state == stateUp {
+		n = t.success
+	}
+
+	if n <= 0 {
+		return 1
+	}
+
+	return n
+}
+
+// alertState is the state of a check stored for alerting. It contains the
+// state which was last alerted and the state being observed currently along
+// with the number of consecutive runs it has been observed for.
+type alertState struct {
+	alerted checkState
+	pending checkState
+	count   int
+}
 
-// True tells if the boolean string is nil.
-func (b boolean) Nil() bool { return b == boolNil }
+// newAlertState returns the initial alert state of a check.
+func newAlertState() alertState {
+	return alertState{alerted: stateNil, pending: stateNil}
+}
+
+// String encodes the alert state into a string.
+func (s alertState) String() string {
+	return fmt.Sprintf("%s:%s:%d", s.alerted, s.pending, s.count)
+}
+
+// parseAlertState decodes the alert state from the string.
+func parseAlertState(str string) (alertState, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) != 3 {
+		return alertState{}, fmt.Errorf("invalid alert state: %s", str)
+	}
+
+	count, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return alertState{}, fmt.Errorf("invalid alert state: %s: %w", str, err)
+	}
+
+	return alertState{
+		alerted: checkState(parts[0]),
+		pending: checkState(parts[1]),
+		count:   count,
+	}, nil
+}
+
+// observe records the new state of the check and tells if the state should
+// be alerted, i.e., the new state has been observed for the threshold number
+// of consecutive runs.
+//
+// The down and degraded runs count together toward the failure threshold, so
+// that a check alternating between them is alerted too. The most severe of
+// the states seen during the streak is alerted.
+func (s *alertState) observe(newState checkState, th threshold) bool {
+	switch {
+	case newState.isFailing() && s.pending.isFailing():
+		s.pending = worse(s.pending, newState)
+		s.count++
+	case newState == s.alerted:
+		s.pending = stateNil
+		s.count = 0
+		return false
+	case newState == s.pending:
+		s.count++
+	default:
+		s.pending = newState
+		s.count = 1
+	}
+
+	if s.count < th.forState(s.pending) {
+		return false
+	}
+
+	// the streak ended up in the state that was already alerted.
+	update := s.pending != s.alerted
+
+	s.alerted = s.pending
+	s.pending = stateNil
+	s.count = 0
+	return update
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+	"github.com/sdslabs/pinger/pkg/util/jwt"
+)
+
+const (
+	// tokenExpiry is the time after which a token expires.
+	tokenExpiry = 24 * time.Hour
+
+	// tokenRefresh is the time after the expiry of a token till which it can
+	// be refreshed without logging in again.
+	tokenRefresh = 7 * (24 * time.Hour) // 1 week
+
+	// authType is the type of the authorization header.
+	authType = "Bearer"
+)
+
+// server is the app server along with the connection to the database it
+// serves.
+type server struct {
+	ctx   *appcontext.Context
+	conn  *database.Conn
+	token *jwt.JWT
+}
+
+// Run starts the app server and blocks till the context is canceled.
+func Run(ctx *appcontext.Context, conf *configfile.App) error {
+	if conf.Secret == "" {
+		return fmt.Errorf("secret cannot be empty")
+	}
+
+	conn, err := database.NewConn(ctx, &conf.Database)
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	s := &server{
+		ctx:  ctx,
+		conn: conn,
+		token: &jwt.JWT{
+			ExpirationInterval: tokenExpiry,
+			RefreshInterval:    tokenRefresh,
+			Secret:             []byte(conf.Secret),
+			AuthType:           authType,
+		},
+	}
+
+	router := httpserver.NewRouter(ctx, httpserver.RouterOpts{
+		AllowedOrigins: conf.AllowedOrigins,
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+
+		// The cookie binding the login to the browser is sent with the
+		// redirect from the client.
+		AllowCredentials: true,
+	})
+
+	if er := s.addAuthRoutes(router.Group(routeAuth), conf); er != nil {
+		return er
+	}
+
+	api := router.Group("/", s.authenticate)
+	s.addUserRoutes(api)
+	s.addCheckRoutes(api)
+	s.addPageRoutes(api)
+
+	ctx.Logger().
+		WithField("address", fmt.Sprintf(":%d", conf.Port)).
+		Infof("serving app")
+	err = httpserver.ListenAndServe(ctx, conf.Port, router)
+	if err != nil && !errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("server exited unexpectedly: %w", err)
+	}
+
+	return nil
+}
+
+// respondDBError responds with not found if the record does not exist, else
+// with an internal server error.
+func (s *server) respondDBError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		httpserver.RespondErrorNotFound(s.ctx, c, err)
+		return
+	}
+
+	httpserver.RespondErrorInternalServer(s.ctx, c, err)
+}
+
+// respondBadRequest responds with the error in the request.
+func (s *server) respondBadRequest(c *gin.Context, err error) {
+	httpserver.RespondError(s.ctx, c, http.StatusBadRequest, err)
+}
+
+// paramID parses the path parameter which is the ID of a record.
+func paramID(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 0)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, c.Param(name))
+	}
+
+	return uint(id), nil
+}
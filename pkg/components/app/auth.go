@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/oauther"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+const (
+	// routeAuth is the route under which the login routes of each of the
+	// OAuth providers, and the route to refresh the token, are added.
+	routeAuth = "/auth"
+
+	// keyUserID is the key of the ID of the authenticated user in the
+	// context of the request.
+	keyUserID = "user_id"
+)
+
+// tokenValues are the values of the user stored in the token.
+type tokenValues struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
+
+// addAuthRoutes adds the login and redirect routes of each of the OAuth
+// providers, and the route to refresh the token.
+func (s *server) addAuthRoutes(router gin.IRouter, conf *configfile.App) error {
+	opts := &oauther.Opts{
+		Router: router,
+		OnUser: s.onUser,
+		LoginResponse: func(url string) interface{} {
+			return httpserver.LoginResponse{URL: url}
+		},
+		RedirectResponse: func(token interface{}) interface{} {
+			return token
+		},
+		ErrorResponse: func(err error) interface{} {
+			return httpserver.ErrorResponse{Error: err.Error()}
+		},
+		SecureCookie: conf.SecureCookies,
+	}
+
+	for i := range conf.Oauth {
+		if err := oauther.Initialize(s.ctx, &conf.Oauth[i], opts); err != nil {
+			return fmt.Errorf("oauth %q: %w", conf.Oauth[i].Provider, err)
+		}
+	}
+
+	router.POST("/refresh", s.refresh)
+	return nil
+}
+
+// onUser creates the user fetched from the OAuth provider, if it does not
+// exist, and responds with a token for the user.
+func (s *server) onUser(info json.RawMessage) (interface{}, int, error) {
+	var u oauther.User
+	if err := json.Unmarshal(info, &u); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("cannot parse user: %w", err)
+	}
+
+	if u.Email == "" {
+		return nil, http.StatusBadRequest, errors.New("user does not have an email")
+	}
+
+	if u.Name == "" {
+		u.Name = u.Email
+	}
+
+	user, err := s.conn.CreateUser(s.ctx, &database.User{Email: u.Email, Name: u.Name})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	token, err := s.token.NewToken(tokenValues{ID: user.ID, Email: user.Email})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return httpserver.TokenResponse{Token: token}, http.StatusOK, nil
+}
+
+// refresh responds with a new token for an expired token in the header.
+func (s *server) refresh(c *gin.Context) {
+	token, err := s.token.GetTokenFromHeader(c)
+	if err != nil {
+		httpserver.RespondError(s.ctx, c, http.StatusUnauthorized, err)
+		return
+	}
+
+	refreshed, sc, err := s.token.RefreshToken(token)
+	if err != nil {
+		httpserver.RespondError(s.ctx, c, sc, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, httpserver.TokenResponse{Token: refreshed})
+}
+
+// authenticate verifies the token in the header and sets the ID of the user
+// in the context, else aborts the request.
+func (s *server) authenticate(c *gin.Context) {
+	token, err := s.token.GetTokenFromHeader(c)
+	if err != nil {
+		httpserver.RespondError(s.ctx, c, http.StatusUnauthorized, err)
+		c.Abort()
+		return
+	}
+
+	values, sc, err := s.token.VerifyToken(token)
+	if err != nil {
+		httpserver.RespondError(s.ctx, c, sc, err)
+		c.Abort()
+		return
+	}
+
+	// values are decoded from JSON as a map, so they are converted back.
+	var v tokenValues
+	b, err := json.Marshal(values)
+	if err == nil {
+		err = json.Unmarshal(b, &v)
+	}
+	if err != nil || v.ID == 0 {
+		httpserver.RespondError(s.ctx, c, http.StatusUnauthorized, errors.New("invalid token"))
+		c.Abort()
+		return
+	}
+
+	c.Set(keyUserID, v.ID)
+	c.Next()
+}
+
+// userID returns the ID of the authenticated user.
+func userID(c *gin.Context) uint {
+	v, ok := c.Get(keyUserID)
+	if !ok {
+		return 0
+	}
+
+	id, isUint := v.(uint)
+	if !isUint {
+		return 0
+	}
+
+	return id
+}
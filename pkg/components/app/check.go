@@ -0,0 +1,437 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// componentRequest is a component of the check in a request.
+type componentRequest struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// checkRequest is the request to create or update a check. When updating,
+// only the fields that are set are updated and the outputs, if set, replace
+// the outputs of the check. Payloads can only be set when creating the check
+// and are otherwise managed through the routes of the payloads.
+type checkRequest struct {
+	Name string `json:"name"`
+
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout"`
+
+	Retries          int           `json:"retries"`
+	RetryInterval    time.Duration `json:"retry_interval"`
+	FailureThreshold int           `json:"failure_threshold"`
+	SuccessThreshold int           `json:"success_threshold"`
+
+	Input    *componentRequest  `json:"input"`
+	Outputs  []componentRequest `json:"outputs"`
+	Operator string             `json:"operator"`
+	Target   *componentRequest  `json:"target"`
+	Payloads []componentRequest `json:"payloads"`
+}
+
+// apply sets the fields of the request that are set on the check, except
+// the outputs and the payloads.
+func (r *checkRequest) apply(check *database.Check) {
+	if r.Name != "" {
+		check.Title = r.Name
+	}
+
+	if r.Interval != 0 {
+		check.Interval = r.Interval
+	}
+
+	if r.Timeout != 0 {
+		check.Timeout = r.Timeout
+	}
+
+	if r.Retries != 0 {
+		check.Retries = r.Retries
+	}
+
+	if r.RetryInterval != 0 {
+		check.RetryInterval = r.RetryInterval
+	}
+
+	if r.FailureThreshold != 0 {
+		check.FailureThreshold = r.FailureThreshold
+	}
+
+	if r.SuccessThreshold != 0 {
+		check.SuccessThreshold = r.SuccessThreshold
+	}
+
+	if r.Input != nil {
+		check.InputType = r.Input.Type
+		check.InputValue = r.Input.Value
+	}
+
+	if r.Operator != "" {
+		check.Operator = r.Operator
+	}
+
+	if r.Target != nil {
+		check.TargetType = r.Target.Type
+		check.TargetValue = r.Target.Value
+	}
+}
+
+// outputs returns the outputs of the request.
+func (r *checkRequest) outputs() []database.Output {
+	outputs := make([]database.Output, len(r.Outputs))
+	for i := range r.Outputs {
+		outputs[i] = database.Output{
+			Type:  r.Outputs[i].Type,
+			Value: r.Outputs[i].Value,
+		}
+	}
+
+	return outputs
+}
+
+// payloadRequest is the request to create or update a payload.
+type payloadRequest struct {
+	Type  string `json:"type" binding:"required"`
+	Value string `json:"value"`
+}
+
+// addCheckRoutes adds the routes for the checks of the authenticated user
+// and their payloads.
+func (s *server) addCheckRoutes(router gin.IRouter) {
+	router.GET("/checks", s.listChecks)
+	router.POST("/checks", s.createCheck)
+	router.GET("/checks/:check", s.getCheck)
+	router.PATCH("/checks/:check", s.updateCheck)
+	router.DELETE("/checks/:check", s.deleteCheck)
+
+	router.POST("/checks/:check/payloads", s.createPayload)
+	router.GET("/checks/:check/payloads/:payload", s.getPayload)
+	router.PATCH("/checks/:check/payloads/:payload", s.updatePayload)
+	router.DELETE("/checks/:check/payloads/:payload", s.deletePayload)
+}
+
+// newCheckID generates a random ID for a new check.
+func newCheckID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// loadCheck gets the check of the authenticated user along with its outputs
+// and payloads. It responds with the error if it fails.
+func (s *server) loadCheck(c *gin.Context, checkID string) (*database.Check, bool) {
+	check, err := s.conn.GetCheck(c.Request.Context(), userID(c), checkID, database.GetCheckOpts{
+		Outputs:  true,
+		Payloads: true,
+	})
+	if err != nil {
+		s.respondDBError(c, err)
+		return nil, false
+	}
+
+	if check.ID == "" {
+		s.respondDBError(c, fmt.Errorf("check %q: %w", checkID, database.ErrRecordNotFound))
+		return nil, false
+	}
+
+	return check, true
+}
+
+// respondCheck responds with the check of the authenticated user.
+func (s *server) respondCheck(c *gin.Context, checkID string) {
+	check, ok := s.loadCheck(c, checkID)
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, checkResponse(check))
+}
+
+// touchCheck marks the check as updated when its payloads change, so that
+// the central server pushes it to the agents again.
+func (s *server) touchCheck(ctx context.Context, ownerID uint, checkID string) error {
+	_, err := s.conn.UpdateCheck(ctx, ownerID, checkID, &database.Check{UpdatedAt: time.Now()})
+	return err
+}
+
+// listChecks responds with the checks of the authenticated user.
+func (s *server) listChecks(c *gin.Context) {
+	user, err := s.conn.GetUserByID(c.Request.Context(), userID(c), database.GetUserOpts{Checks: true})
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, checksResponse(user.Checks))
+}
+
+// createCheck validates and creates the check.
+func (s *server) createCheck(c *gin.Context) {
+	var req checkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if req.Name == "" {
+		s.respondBadRequest(c, errors.New("name cannot be empty"))
+		return
+	}
+
+	id, err := newCheckID()
+	if err != nil {
+		httpserver.RespondErrorInternalServer(s.ctx, c, err)
+		return
+	}
+
+	check := database.Check{ID: id, Outputs: req.outputs()}
+	req.apply(&check)
+	for i := range req.Payloads {
+		check.Payloads = append(check.Payloads, database.Payload{
+			OwnerID: userID(c),
+			Type:    req.Payloads[i].Type,
+			Value:   req.Payloads[i].Value,
+		})
+	}
+
+	if err = checker.Validate(checkToConfig(&check)); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if _, err = s.conn.CreateCheck(c.Request.Context(), userID(c), &check); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondCheck(c, id)
+}
+
+// getCheck responds with the check.
+func (s *server) getCheck(c *gin.Context) {
+	s.respondCheck(c, c.Param("check"))
+}
+
+// updateCheck validates the check with the updates and updates it.
+func (s *server) updateCheck(c *gin.Context) {
+	var req checkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	updated := *check
+	req.apply(&updated)
+	if req.Outputs != nil {
+		updated.Outputs = req.outputs()
+	}
+
+	if err := checker.Validate(checkToConfig(&updated)); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	update := database.Check{UpdatedAt: time.Now()}
+	req.apply(&update)
+
+	ctx := c.Request.Context()
+	if _, err := s.conn.UpdateCheck(ctx, userID(c), check.ID, &update); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if req.Outputs != nil {
+		if err := s.conn.ReplaceCheckOutputs(ctx, userID(c), check.ID, updated.Outputs); err != nil {
+			s.respondDBError(c, err)
+			return
+		}
+	}
+
+	s.respondCheck(c, check.ID)
+}
+
+// deleteCheck deletes the check along with its outputs.
+func (s *server) deleteCheck(c *gin.Context) {
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	if err := s.conn.DeleteCheck(c.Request.Context(), userID(c), check.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findPayload returns the index of the payload in the path of the request
+// among the payloads of the check. It responds with the error if it fails.
+func (s *server) findPayload(c *gin.Context, check *database.Check) (int, bool) {
+	payloadID, err := paramID(c, "payload")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return 0, false
+	}
+
+	for i := range check.Payloads {
+		if check.Payloads[i].ID == payloadID {
+			return i, true
+		}
+	}
+
+	s.respondDBError(c, fmt.Errorf("payload %d: %w", payloadID, database.ErrRecordNotFound))
+	return 0, false
+}
+
+// createPayload validates the check with the payload and adds it to the
+// check.
+func (s *server) createPayload(c *gin.Context) {
+	var req payloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	payload := database.Payload{Type: req.Type, Value: req.Value}
+
+	updated := *check
+	updated.Payloads = append(append([]database.Payload(nil), check.Payloads...), payload)
+	if err := checker.Validate(checkToConfig(&updated)); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	created, err := s.conn.CreatePayload(ctx, userID(c), check.ID, &payload)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if err = s.touchCheck(ctx, userID(c), check.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, payloadResponse(created))
+}
+
+// getPayload responds with the payload of the check.
+func (s *server) getPayload(c *gin.Context) {
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	i, ok := s.findPayload(c, check)
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, payloadResponse(&check.Payloads[i]))
+}
+
+// updatePayload validates the check with the updated payload and updates
+// the payload.
+func (s *server) updatePayload(c *gin.Context) {
+	var req payloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	i, ok := s.findPayload(c, check)
+	if !ok {
+		return
+	}
+
+	updated := *check
+	updated.Payloads = append([]database.Payload(nil), check.Payloads...)
+	updated.Payloads[i].Type = req.Type
+	updated.Payloads[i].Value = req.Value
+	if err := checker.Validate(checkToConfig(&updated)); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	payload := database.Payload{Type: req.Type, Value: req.Value}
+	if _, err := s.conn.UpdatePayload(ctx, userID(c), check.Payloads[i].ID, check.ID, &payload); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if err := s.touchCheck(ctx, userID(c), check.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, payloadResponse(&updated.Payloads[i]))
+}
+
+// deletePayload validates the check without the payload and deletes the
+// payload.
+func (s *server) deletePayload(c *gin.Context) {
+	check, ok := s.loadCheck(c, c.Param("check"))
+	if !ok {
+		return
+	}
+
+	i, ok := s.findPayload(c, check)
+	if !ok {
+		return
+	}
+
+	updated := *check
+	updated.Payloads = append(append([]database.Payload(nil), check.Payloads[:i]...), check.Payloads[i+1:]...)
+	if err := checker.Validate(checkToConfig(&updated)); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := s.conn.DeletePayload(ctx, userID(c), check.Payloads[i].ID, check.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if err := s.touchCheck(ctx, userID(c), check.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
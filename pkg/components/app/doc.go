@@ -1,3 +1,9 @@
 // Package app defines the app server which is the interface for interacting
 // with the app users.
+//
+// The app server exposes the checks, the status pages, and their incidents
+// and teams in the database as a JSON REST API. Users log in through one of
+// the OAuth providers and are then authenticated with a token that is sent
+// as a bearer token in the authorization header of each request. A user can
+// only access the checks and the pages they own.
 package app
@@ -0,0 +1,820 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/maintenance"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// pageRequest is the request to create or update a page. When updating, only
+// the fields that are set are updated.
+type pageRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Public      *bool  `json:"public"`
+}
+
+// apply sets the fields of the request that are set on the page.
+func (r *pageRequest) apply(page *database.Page) {
+	if r.Title != "" {
+		page.Title = r.Title
+	}
+
+	if r.Description != "" {
+		page.Description = r.Description
+	}
+
+	if r.Public != nil {
+		page.Visibility = toBool(*r.Public)
+	}
+}
+
+// pageChecksRequest is the request to add checks to a page.
+type pageChecksRequest struct {
+	Checks []string `json:"checks" binding:"required"`
+}
+
+// incidentRequest is the request to create or update an incident. When
+// updating, only the fields that are set are updated.
+type incidentRequest struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Resolved    *bool         `json:"resolved"`
+	TimeStamp   time.Time     `json:"timestamp"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// apply sets the fields of the request that are set on the incident.
+func (r *incidentRequest) apply(incident *database.Incident) {
+	if r.Title != "" {
+		incident.Title = r.Title
+	}
+
+	if r.Description != "" {
+		incident.Description = r.Description
+	}
+
+	if r.Resolved != nil {
+		incident.Resolved = toBool(*r.Resolved)
+	}
+
+	if !r.TimeStamp.IsZero() {
+		incident.TimeStamp = r.TimeStamp
+	}
+
+	if r.Duration != 0 {
+		incident.Duration = r.Duration
+	}
+}
+
+// maintenanceRequest is the request to create or update a maintenance. When
+// updating, only the fields that are set are updated.
+type maintenanceRequest struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Start       time.Time     `json:"start"`
+	Duration    time.Duration `json:"duration"`
+	Recurrence  string        `json:"recurrence"`
+	Until       *time.Time    `json:"until"`
+	Timezone    string        `json:"timezone"`
+
+	// Checks the maintenance is added to when it is created, all the
+	// checks of the page if empty.
+	Checks []string `json:"checks"`
+}
+
+// apply sets the fields of the request that are set on the maintenance.
+func (r *maintenanceRequest) apply(m *database.Maintenance) {
+	if r.Title != "" {
+		m.Title = r.Title
+	}
+
+	if r.Description != "" {
+		m.Description = r.Description
+	}
+
+	if !r.Start.IsZero() {
+		m.Start = r.Start
+	}
+
+	if r.Duration != 0 {
+		m.Duration = r.Duration
+	}
+
+	if r.Recurrence != "" {
+		m.Recurrence = r.Recurrence
+	}
+
+	if r.Until != nil {
+		m.Until = r.Until
+	}
+
+	if r.Timezone != "" {
+		m.Timezone = r.Timezone
+	}
+}
+
+// maintenanceChecksRequest is the request to add checks to a maintenance.
+type maintenanceChecksRequest struct {
+	Checks []string `json:"checks" binding:"required"`
+}
+
+// teamMemberRequest is the request to add a member to the team of a page or
+// to update the role of the member.
+type teamMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// toBool converts the bool to the one stored in the database.
+func toBool(b bool) database.Bool {
+	if b {
+		return database.True
+	}
+
+	return database.False
+}
+
+// validateRole validates if the role of a team member is one of the roles.
+func validateRole(role string) error {
+	switch role {
+	case database.RoleDefault, database.RoleMaintainer, database.RoleAdmin:
+		return nil
+	default:
+		return fmt.Errorf("invalid role: %q", role)
+	}
+}
+
+// addPageRoutes adds the routes for the pages of the authenticated user
+// along with their checks, incidents, maintenances and team.
+func (s *server) addPageRoutes(router gin.IRouter) {
+	router.GET("/pages", s.listPages)
+	router.POST("/pages", s.createPage)
+	router.GET("/pages/:page", s.getPage)
+	router.PATCH("/pages/:page", s.updatePage)
+	router.DELETE("/pages/:page", s.deletePage)
+
+	router.POST("/pages/:page/checks", s.addPageChecks)
+	router.DELETE("/pages/:page/checks/:check", s.removePageCheck)
+
+	router.POST("/pages/:page/incidents", s.createIncident)
+	router.GET("/pages/:page/incidents/:incident", s.getIncident)
+	router.PATCH("/pages/:page/incidents/:incident", s.updateIncident)
+	router.DELETE("/pages/:page/incidents/:incident", s.deleteIncident)
+
+	router.POST("/pages/:page/maintenances", s.createMaintenance)
+	router.GET("/pages/:page/maintenances/:maintenance", s.getMaintenance)
+	router.PATCH("/pages/:page/maintenances/:maintenance", s.updateMaintenance)
+	router.DELETE("/pages/:page/maintenances/:maintenance", s.deleteMaintenance)
+	router.POST("/pages/:page/maintenances/:maintenance/checks", s.addMaintenanceChecks)
+	router.DELETE("/pages/:page/maintenances/:maintenance/checks/:check", s.removeMaintenanceCheck)
+
+	router.POST("/pages/:page/team", s.addTeamMember)
+	router.PATCH("/pages/:page/team/:member", s.updateTeamMember)
+	router.DELETE("/pages/:page/team/:member", s.removeTeamMember)
+}
+
+// loadPage gets the page in the path of the request with the associations
+// in the options. It responds with the error if it fails.
+func (s *server) loadPage(c *gin.Context, opts database.GetPageOpts) (*database.Page, bool) {
+	pageID, err := paramID(c, "page")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return nil, false
+	}
+
+	page, err := s.conn.GetPage(c.Request.Context(), userID(c), pageID, opts)
+	if err != nil {
+		s.respondDBError(c, err)
+		return nil, false
+	}
+
+	if page.ID == 0 {
+		s.respondDBError(c, fmt.Errorf("page %d: %w", pageID, database.ErrRecordNotFound))
+		return nil, false
+	}
+
+	return page, true
+}
+
+// respondPage responds with the page in the path of the request along with
+// its checks, incidents, team and maintenances.
+func (s *server) respondPage(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{
+		Checks:       true,
+		Incidents:    true,
+		Team:         true,
+		Maintenances: true,
+	})
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, pageResponse(page))
+}
+
+// listPages responds with the pages of the authenticated user.
+func (s *server) listPages(c *gin.Context) {
+	user, err := s.conn.GetUserByID(c.Request.Context(), userID(c), database.GetUserOpts{Pages: true})
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, pagesResponse(user.Pages))
+}
+
+// createPage creates the page.
+func (s *server) createPage(c *gin.Context) {
+	var req pageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if req.Title == "" {
+		s.respondBadRequest(c, errors.New("title cannot be empty"))
+		return
+	}
+
+	page := database.Page{Visibility: database.False}
+	req.apply(&page)
+
+	created, err := s.conn.CreatePage(c.Request.Context(), userID(c), &page)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, pageResponse(created))
+}
+
+// getPage responds with the page.
+func (s *server) getPage(c *gin.Context) {
+	s.respondPage(c)
+}
+
+// updatePage updates the page.
+func (s *server) updatePage(c *gin.Context) {
+	var req pageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	update := database.Page{}
+	update.UpdatedAt = time.Now()
+	req.apply(&update)
+	if _, err := s.conn.UpdatePage(c.Request.Context(), userID(c), page.ID, &update); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
+
+// deletePage deletes the page.
+func (s *server) deletePage(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	if err := s.conn.DeletePage(c.Request.Context(), userID(c), page.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// addPageChecks adds the checks of the authenticated user to the page.
+func (s *server) addPageChecks(c *gin.Context) {
+	var req pageChecksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	// the checks should exist and be of the user, else they are created when
+	// they are added to the page.
+	ctx := c.Request.Context()
+	for _, checkID := range req.Checks {
+		if checkID == "" {
+			s.respondBadRequest(c, errors.New("check ID cannot be empty"))
+			return
+		}
+
+		check, err := s.conn.GetCheck(ctx, userID(c), checkID, database.GetCheckOpts{})
+		if err != nil {
+			s.respondDBError(c, err)
+			return
+		}
+
+		if check.ID == "" {
+			s.respondBadRequest(c, fmt.Errorf("check %q: %w", checkID, database.ErrRecordNotFound))
+			return
+		}
+	}
+
+	if err := s.conn.AddChecksToPage(ctx, userID(c), page.ID, req.Checks); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
+
+// removePageCheck removes the check from the page.
+func (s *server) removePageCheck(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	checkIDs := []string{c.Param("check")}
+	if err := s.conn.RemoveChecksFromPage(c.Request.Context(), userID(c), page.ID, checkIDs); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
+
+// loadIncident gets the incident in the path of the request from the page.
+// It responds with the error if it fails.
+func (s *server) loadIncident(c *gin.Context, page *database.Page) (*database.Incident, bool) {
+	incidentID, err := paramID(c, "incident")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return nil, false
+	}
+
+	incident, err := s.conn.GetIncident(
+		c.Request.Context(), userID(c), page.ID, incidentID, database.GetIncidentOpts{})
+	if err != nil {
+		s.respondDBError(c, err)
+		return nil, false
+	}
+
+	if incident.ID == 0 {
+		s.respondDBError(c, fmt.Errorf("incident %d: %w", incidentID, database.ErrRecordNotFound))
+		return nil, false
+	}
+
+	return incident, true
+}
+
+// createIncident creates the incident on the page.
+func (s *server) createIncident(c *gin.Context) {
+	var req incidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if req.Title == "" {
+		s.respondBadRequest(c, errors.New("title cannot be empty"))
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	incident := database.Incident{Resolved: database.False, TimeStamp: time.Now()}
+	req.apply(&incident)
+
+	created, err := s.conn.CreateIncident(c.Request.Context(), userID(c), page.ID, &incident)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, incidentResponse(created))
+}
+
+// getIncident responds with the incident of the page.
+func (s *server) getIncident(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	incident, ok := s.loadIncident(c, page)
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, incidentResponse(incident))
+}
+
+// updateIncident updates the incident of the page.
+func (s *server) updateIncident(c *gin.Context) {
+	var req incidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	incident, ok := s.loadIncident(c, page)
+	if !ok {
+		return
+	}
+
+	update := database.Incident{}
+	update.UpdatedAt = time.Now()
+	req.apply(&update)
+	_, err := s.conn.UpdateIncident(c.Request.Context(), userID(c), page.ID, incident.ID, &update)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	req.apply(incident)
+	httpserver.RespondOK(s.ctx, c, incidentResponse(incident))
+}
+
+// deleteIncident deletes the incident of the page.
+func (s *server) deleteIncident(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	incident, ok := s.loadIncident(c, page)
+	if !ok {
+		return
+	}
+
+	if err := s.conn.DeleteIncident(c.Request.Context(), userID(c), page.ID, incident.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadMaintenance gets the maintenance in the path of the request from the
+// page along with its checks. It responds with the error if it fails.
+func (s *server) loadMaintenance(c *gin.Context, page *database.Page) (*database.Maintenance, bool) {
+	maintenanceID, err := paramID(c, "maintenance")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return nil, false
+	}
+
+	return s.findMaintenance(c, page, maintenanceID)
+}
+
+// findMaintenance gets the maintenance with the ID from the page along with
+// its checks. It responds with the error if it fails.
+func (s *server) findMaintenance(
+	c *gin.Context,
+	page *database.Page,
+	maintenanceID uint,
+) (*database.Maintenance, bool) {
+	m, err := s.conn.GetMaintenance(
+		c.Request.Context(), userID(c), page.ID, maintenanceID, database.GetMaintenanceOpts{Checks: true})
+	if err != nil {
+		s.respondDBError(c, err)
+		return nil, false
+	}
+
+	if m.ID == 0 {
+		s.respondDBError(c, fmt.Errorf("maintenance %d: %w", maintenanceID, database.ErrRecordNotFound))
+		return nil, false
+	}
+
+	return m, true
+}
+
+// respondMaintenance responds with the maintenance of the page along with its
+// checks.
+func (s *server) respondMaintenance(c *gin.Context, page *database.Page, maintenanceID uint) {
+	m, ok := s.findMaintenance(c, page, maintenanceID)
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, maintenanceResponse(m))
+}
+
+// validateMaintenanceChecks validates that the checks are on the page, since
+// a maintenance only applies to the checks of its page.
+func validateMaintenanceChecks(page *database.Page, checkIDs []string) error {
+	onPage := make(map[string]bool, len(page.Checks))
+	for i := range page.Checks {
+		onPage[page.Checks[i].ID] = true
+	}
+
+	for _, checkID := range checkIDs {
+		if checkID == "" {
+			return errors.New("check ID cannot be empty")
+		}
+
+		if !onPage[checkID] {
+			return fmt.Errorf("check %q is not on the page", checkID)
+		}
+	}
+
+	return nil
+}
+
+// createMaintenance creates the maintenance on the page, added to the checks
+// in the request, if any.
+func (s *server) createMaintenance(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{Checks: true})
+	if !ok {
+		return
+	}
+
+	if err := validateMaintenanceChecks(page, req.Checks); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	m := database.Maintenance{}
+	req.apply(&m)
+	if _, err := maintenance.NewSchedule(&m); err != nil {
+		s.respondBadRequest(c, fmt.Errorf("invalid maintenance: %w", err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	created, err := s.conn.CreateMaintenance(ctx, userID(c), page.ID, &m)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if err := s.conn.AddChecksToMaintenance(ctx, userID(c), page.ID, created.ID, req.Checks); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondMaintenance(c, page, created.ID)
+}
+
+// getMaintenance responds with the maintenance of the page.
+func (s *server) getMaintenance(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	m, ok := s.loadMaintenance(c, page)
+	if !ok {
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, maintenanceResponse(m))
+}
+
+// updateMaintenance updates the maintenance of the page. The checks of the
+// maintenance are updated through its own routes.
+func (s *server) updateMaintenance(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if len(req.Checks) > 0 {
+		s.respondBadRequest(c, errors.New("checks are added to or removed from the maintenance separately"))
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	m, ok := s.loadMaintenance(c, page)
+	if !ok {
+		return
+	}
+
+	// the maintenance is validated as it is after the update.
+	updated := *m
+	req.apply(&updated)
+	if _, err := maintenance.NewSchedule(&updated); err != nil {
+		s.respondBadRequest(c, fmt.Errorf("invalid maintenance: %w", err))
+		return
+	}
+
+	update := database.Maintenance{}
+	update.UpdatedAt = time.Now()
+	req.apply(&update)
+	_, err := s.conn.UpdateMaintenance(c.Request.Context(), userID(c), page.ID, m.ID, &update)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondMaintenance(c, page, m.ID)
+}
+
+// deleteMaintenance deletes the maintenance of the page.
+func (s *server) deleteMaintenance(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	m, ok := s.loadMaintenance(c, page)
+	if !ok {
+		return
+	}
+
+	if err := s.conn.DeleteMaintenance(c.Request.Context(), userID(c), page.ID, m.ID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// addMaintenanceChecks adds the checks of the page to the maintenance.
+func (s *server) addMaintenanceChecks(c *gin.Context) {
+	var req maintenanceChecksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{Checks: true})
+	if !ok {
+		return
+	}
+
+	if err := validateMaintenanceChecks(page, req.Checks); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	m, ok := s.loadMaintenance(c, page)
+	if !ok {
+		return
+	}
+
+	if err := s.conn.AddChecksToMaintenance(c.Request.Context(), userID(c), page.ID, m.ID, req.Checks); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondMaintenance(c, page, m.ID)
+}
+
+// removeMaintenanceCheck removes the check from the maintenance, which then
+// applies to all the checks of the page if none are left.
+func (s *server) removeMaintenanceCheck(c *gin.Context) {
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	m, ok := s.loadMaintenance(c, page)
+	if !ok {
+		return
+	}
+
+	checkIDs := []string{c.Param("check")}
+	err := s.conn.RemoveChecksFromMaintenance(c.Request.Context(), userID(c), page.ID, m.ID, checkIDs)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondMaintenance(c, page, m.ID)
+}
+
+// addTeamMember adds the user with the email to the team of the page.
+func (s *server) addTeamMember(c *gin.Context) {
+	var req teamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if req.Email == "" {
+		s.respondBadRequest(c, errors.New("email cannot be empty"))
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = database.RoleDefault
+	}
+
+	if err := validateRole(req.Role); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	member, err := s.conn.GetUserByEmail(ctx, req.Email, database.GetUserOpts{})
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if member.ID == 0 {
+		s.respondDBError(c, fmt.Errorf("user %q: %w", req.Email, database.ErrRecordNotFound))
+		return
+	}
+
+	if _, err = s.conn.AddTeamMemberToPage(ctx, userID(c), page.ID, member.ID, req.Role); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
+
+// updateTeamMember updates the role of the member of the team of the page.
+func (s *server) updateTeamMember(c *gin.Context) {
+	var req teamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	if err := validateRole(req.Role); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	memberID, err := paramID(c, "member")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	_, err = s.conn.UpdateTeamMemberRole(c.Request.Context(), userID(c), page.ID, memberID, req.Role)
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
+
+// removeTeamMember removes the member from the team of the page.
+func (s *server) removeTeamMember(c *gin.Context) {
+	memberID, err := paramID(c, "member")
+	if err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	page, ok := s.loadPage(c, database.GetPageOpts{})
+	if !ok {
+		return
+	}
+
+	if err = s.conn.RemoveTeamMemberFromPage(c.Request.Context(), userID(c), page.ID, memberID); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.respondPage(c)
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// checkToConfig converts the check in the database to the check that is
+// validated and run by the checkers.
+func checkToConfig(check *database.Check) *config.Check {
+	outputs := make([]config.Component, len(check.Outputs))
+	for i := range check.Outputs {
+		outputs[i] = config.Component{
+			Type:  check.Outputs[i].Type,
+			Value: check.Outputs[i].Value,
+		}
+	}
+
+	payloads := make([]config.Component, len(check.Payloads))
+	for i := range check.Payloads {
+		payloads[i] = config.Component{
+			Type:  check.Payloads[i].Type,
+			Value: check.Payloads[i].Value,
+		}
+	}
+
+	return &config.Check{
+		ID:       check.ID,
+		Name:     check.Title,
+		Interval: check.Interval,
+		Timeout:  check.Timeout,
+		Input: config.Component{
+			Type:  check.InputType,
+			Value: check.InputValue,
+		},
+		Outputs:  outputs,
+		Operator: check.Operator,
+		Target: config.Component{
+			Type:  check.TargetType,
+			Value: check.TargetValue,
+		},
+		Payloads: payloads,
+
+		Retries:          check.Retries,
+		RetryInterval:    check.RetryInterval,
+		FailureThreshold: check.FailureThreshold,
+		SuccessThreshold: check.SuccessThreshold,
+	}
+}
+
+// userResponse serializes the user.
+func userResponse(user *database.User) httpserver.UserResponse {
+	return httpserver.UserResponse{
+		ID:    user.ID,
+		Email: user.Email,
+		Name:  user.Name,
+	}
+}
+
+// payloadResponse serializes the payload.
+func payloadResponse(payload *database.Payload) httpserver.PayloadResponse {
+	return httpserver.PayloadResponse{
+		ID:      payload.ID,
+		CheckID: payload.CheckID,
+		Type:    payload.Type,
+		Value:   payload.Value,
+	}
+}
+
+// checkResponse serializes the check along with its outputs and payloads,
+// if they are loaded.
+func checkResponse(check *database.Check) httpserver.CheckResponse {
+	resp := httpserver.CheckResponse{
+		ID:        check.ID,
+		Name:      check.Title,
+		CreatedAt: check.CreatedAt,
+		UpdatedAt: check.UpdatedAt,
+
+		Interval: check.Interval,
+		Timeout:  check.Timeout,
+
+		Retries:          check.Retries,
+		RetryInterval:    check.RetryInterval,
+		FailureThreshold: check.FailureThreshold,
+		SuccessThreshold: check.SuccessThreshold,
+
+		Input: httpserver.ComponentResponse{
+			Type:  check.InputType,
+			Value: check.InputValue,
+		},
+		Operator: check.Operator,
+		Target: httpserver.ComponentResponse{
+			Type:  check.TargetType,
+			Value: check.TargetValue,
+		},
+	}
+
+	for i := range check.Outputs {
+		resp.Outputs = append(resp.Outputs, httpserver.ComponentResponse{
+			Type:  check.Outputs[i].Type,
+			Value: check.Outputs[i].Value,
+		})
+	}
+
+	for i := range check.Payloads {
+		resp.Payloads = append(resp.Payloads, payloadResponse(&check.Payloads[i]))
+	}
+
+	return resp
+}
+
+// checksResponse serializes the checks.
+func checksResponse(checks []database.Check) []httpserver.CheckResponse {
+	resp := make([]httpserver.CheckResponse, len(checks))
+	for i := range checks {
+		resp[i] = checkResponse(&checks[i])
+	}
+
+	return resp
+}
+
+// incidentResponse serializes the incident.
+func incidentResponse(incident *database.Incident) httpserver.IncidentResponse {
+	return httpserver.IncidentResponse{
+		ID:          incident.ID,
+		PageID:      incident.PageID,
+		Title:       incident.Title,
+		Description: incident.Description,
+		Resolved:    incident.Resolved.T(),
+		TimeStamp:   incident.TimeStamp,
+		Duration:    incident.Duration,
+	}
+}
+
+// maintenanceResponse serializes the maintenance along with the IDs of its
+// checks, if they are loaded.
+func maintenanceResponse(maintenance *database.Maintenance) httpserver.StatusPageMaintenanceResponse {
+	return httpserver.StatusPageMaintenanceResponse{
+		ID:          maintenance.ID,
+		PageID:      maintenance.PageID,
+		Title:       maintenance.Title,
+		Description: maintenance.Description,
+		Start:       maintenance.Start,
+		Duration:    maintenance.Duration,
+		Recurrence:  maintenance.Recurrence,
+		Until:       maintenance.Until,
+		Timezone:    maintenance.Timezone,
+		Checks:      maintenance.GetChecks(),
+	}
+}
+
+// teamMemberResponse serializes the team member along with the user, which
+// should be loaded.
+func teamMemberResponse(member *database.PageTeam) httpserver.TeamMemberResponse {
+	return httpserver.TeamMemberResponse{
+		User: userResponse(&member.User),
+		Role: member.Role,
+	}
+}
+
+// pageResponse serializes the page along with its checks, incidents, team
+// and maintenances, if they are loaded.
+func pageResponse(page *database.Page) httpserver.StatusPageResponse {
+	resp := httpserver.StatusPageResponse{
+		ID:          page.ID,
+		CreatedAt:   page.CreatedAt,
+		UpdatedAt:   page.UpdatedAt,
+		Title:       page.Title,
+		Description: page.Description,
+		Public:      page.Visibility.T(),
+	}
+
+	if len(page.Checks) > 0 {
+		resp.Checks = checksResponse(page.Checks)
+	}
+
+	for i := range page.Incidents {
+		resp.Incidents = append(resp.Incidents, incidentResponse(&page.Incidents[i]))
+	}
+
+	for i := range page.Team {
+		resp.Team = append(resp.Team, teamMemberResponse(&page.Team[i]))
+	}
+
+	for i := range page.Maintenances {
+		resp.Maintenances = append(resp.Maintenances, maintenanceResponse(&page.Maintenances[i]))
+	}
+
+	return resp
+}
+
+// pagesResponse serializes the pages.
+func pagesResponse(pages []database.Page) []httpserver.StatusPageResponse {
+	resp := make([]httpserver.StatusPageResponse, len(pages))
+	for i := range pages {
+		resp[i] = pageResponse(&pages[i])
+	}
+
+	return resp
+}
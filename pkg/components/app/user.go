@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// userRequest is the request to update the user.
+type userRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// addUserRoutes adds the routes for the authenticated user.
+func (s *server) addUserRoutes(router gin.IRouter) {
+	router.GET("/user", s.getUser)
+	router.PATCH("/user", s.updateUser)
+	router.DELETE("/user", s.deleteUser)
+}
+
+// getUser responds with the authenticated user.
+func (s *server) getUser(c *gin.Context) {
+	user, err := s.conn.GetUserByID(c.Request.Context(), userID(c), database.GetUserOpts{})
+	if err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	if user.ID == 0 {
+		s.respondDBError(c, database.ErrRecordNotFound)
+		return
+	}
+
+	httpserver.RespondOK(s.ctx, c, userResponse(user))
+}
+
+// updateUser updates the name of the authenticated user.
+func (s *server) updateUser(c *gin.Context) {
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondBadRequest(c, err)
+		return
+	}
+
+	user := database.User{Name: req.Name}
+	if _, err := s.conn.UpdateUserByID(c.Request.Context(), userID(c), &user); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	s.getUser(c)
+}
+
+// deleteUser deletes the authenticated user. The checks and pages of the
+// user are left without an owner.
+func (s *server) deleteUser(c *gin.Context) {
+	if err := s.conn.DeleteUserByID(c.Request.Context(), userID(c)); err != nil {
+		s.respondDBError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
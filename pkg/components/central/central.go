@@ -0,0 +1,111 @@
+package central
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+	"github.com/sdslabs/pinger/pkg/util/controller"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// routeStatus is the route that responds with the status of the agents.
+const routeStatus = "/status"
+
+// Run starts the central server.
+//
+// It reconciles the checks on the agents at regular intervals till the
+// context is canceled, and serves the status of the agents if a port is
+// provided.
+func Run(ctx *appcontext.Context, conf *configfile.Central) error {
+	if conf.Interval <= 0 {
+		return fmt.Errorf("interval should be > 0")
+	}
+
+	if conf.Timeout <= 0 {
+		return fmt.Errorf("timeout should be > 0")
+	}
+
+	if conf.UnhealthyAfter <= 0 {
+		return fmt.Errorf("unhealthy after should be > 0")
+	}
+
+	if conf.Locations <= 0 {
+		return fmt.Errorf("locations should be > 0")
+	}
+
+	if len(conf.Agents) == 0 {
+		return fmt.Errorf("no agents registered")
+	}
+
+	conn, err := database.NewConn(ctx, &conf.Database)
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	reg, err := newRegistry(conf)
+	if err != nil {
+		return err
+	}
+	defer reg.close()
+
+	// the controller runs the function on each tick even if the last run has
+	// not finished, so a run is skipped while another is in progress.
+	var running int32
+
+	ctrl, err := controller.NewController(ctx, &controller.Opts{
+		Name:     "central_reconcile",
+		Interval: conf.Interval,
+		Func: func(context.Context) (interface{}, error) {
+			if !atomic.CompareAndSwapInt32(&running, 0, 1) {
+				ctx.Logger().Warnln("skipping reconcile as the last one is in progress")
+				return nil, nil
+			}
+			defer atomic.StoreInt32(&running, 0)
+
+			if err := reconcile(ctx, conn, reg); err != nil {
+				ctx.Logger().WithError(err).Errorln("cannot reconcile checks")
+				return nil, err
+			}
+
+			return nil, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctrl.Start()
+
+	if conf.Port != 0 {
+		go serveStatus(ctx, conf.Port, reg)
+	}
+
+	ctrl.Wait()
+	return nil
+}
+
+// serveStatus starts a HTTP server that responds with the status of the
+// agents.
+func serveStatus(ctx *appcontext.Context, port uint16, reg *registry) {
+	router := httpserver.NewRouter(ctx, httpserver.RouterOpts{
+		AllowedMethods: []string{http.MethodGet},
+	})
+
+	router.GET(routeStatus, func(c *gin.Context) {
+		httpserver.RespondOK(ctx, c, reg.status())
+	})
+
+	ctx.Logger().
+		WithField("address", fmt.Sprintf(":%d", port)).
+		Infof("serving central status")
+	if err := httpserver.ListenAndServe(ctx, port, router); err != nil {
+		ctx.Logger().WithError(err).Errorln("server exited unexpectedly")
+	}
+}
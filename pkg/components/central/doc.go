@@ -1,2 +1,9 @@
-// Package central implements the central API server for the application.
+// Package central implements the central server which schedules the checks
+// in the database on the agents registered with it.
+//
+// The central server keeps a registry of the agents along with their health
+// and capacity. At regular intervals it lists the checks running on each
+// agent, assigns each check to a healthy agent, and pushes and removes the
+// checks on the agents so that they run what is assigned to them. Checks of
+// an agent that goes down are assigned to the other agents.
 package central
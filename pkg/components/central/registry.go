@@ -0,0 +1,246 @@
+package central
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/sdslabs/pinger/pkg/components/agent/proto"
+	"github.com/sdslabs/pinger/pkg/config/configfile"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// agent is an agent registered with the central server.
+type agent struct {
+	name     string
+	address  string
+	location string
+	capacity int
+
+	conn   *grpc.ClientConn
+	client proto.AgentClient
+
+	healthy  bool
+	failures int
+	lastSeen time.Time
+	lastErr  error
+
+	// checks running on the agent as of the last time they were listed.
+	checks map[string]struct{}
+
+	// pushed is the version of each check pushed to the agent so that a
+	// check is pushed again only when it or its maintenances change.
+	pushed map[string]string
+
+	// rejected is the version of each check the agent rejected so that it
+	// is not pushed again till it changes.
+	rejected map[string]string
+}
+
+// fits tells if one more check can be assigned to the agent with the load.
+func (a *agent) fits(load int) bool {
+	return a.capacity <= 0 || load < a.capacity
+}
+
+// hasAgent tells if the agent is one of the agents.
+func hasAgent(agents []*agent, a *agent) bool {
+	for _, b := range agents {
+		if a == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registry keeps the agents registered with the central server and the
+// agents each check is assigned to.
+type registry struct {
+	agents []*agent // in the order of the config.
+
+	timeout        time.Duration
+	unhealthyAfter int
+	locations      int
+
+	// assigned maps the ID of each check to the agents it is assigned to,
+	// each in a different location.
+	assigned   map[string][]*agent
+	unassigned []string
+	partial    []string
+
+	mu sync.RWMutex
+}
+
+// newRegistry creates the registry and connects to the agents in the config.
+func newRegistry(conf *configfile.Central) (*registry, error) {
+	r := &registry{
+		agents:         make([]*agent, 0, len(conf.Agents)),
+		timeout:        conf.Timeout,
+		unhealthyAfter: conf.UnhealthyAfter,
+		locations:      conf.Locations,
+		assigned:       map[string][]*agent{},
+	}
+
+	names := map[string]struct{}{}
+	for i := range conf.Agents {
+		ac := conf.Agents[i]
+
+		if ac.Name == "" {
+			r.close()
+			return nil, fmt.Errorf("agent %d: name cannot be empty", i)
+		}
+
+		if _, ok := names[ac.Name]; ok {
+			r.close()
+			return nil, fmt.Errorf("agent %q already registered", ac.Name)
+		}
+		names[ac.Name] = struct{}{}
+
+		if ac.Capacity < 0 {
+			r.close()
+			return nil, fmt.Errorf("agent %q: capacity should be >= 0", ac.Name)
+		}
+
+		// dialing does not block, the agents are connected to when they are
+		// first requested and reconnected to when they go down.
+		conn, err := grpc.Dial(ac.Address, grpc.WithInsecure())
+		if err != nil {
+			r.close()
+			return nil, fmt.Errorf("agent %q: cannot connect: %w", ac.Name, err)
+		}
+
+		location := ac.Location
+		if location == "" {
+			location = ac.Name
+		}
+
+		r.agents = append(r.agents, &agent{
+			name:     ac.Name,
+			address:  ac.Address,
+			location: location,
+			capacity: ac.Capacity,
+			conn:     conn,
+			client:   proto.NewAgentClient(conn),
+			checks:   map[string]struct{}{},
+			pushed:   map[string]string{},
+			rejected: map[string]string{},
+		})
+	}
+
+	return r, nil
+}
+
+// close closes the connections to the agents.
+func (r *registry) close() {
+	for _, a := range r.agents {
+		a.conn.Close() // nolint:errcheck
+	}
+}
+
+// probeResult is the checks listed by an agent.
+type probeResult struct {
+	checks map[string]struct{}
+	err    error
+}
+
+// probe lists the checks running on each agent concurrently and updates the
+// health of the agents. An agent is unhealthy once it fails to respond
+// `unhealthyAfter` times in a row and healthy as soon as it responds.
+func (r *registry) probe(ctx context.Context) {
+	results := make([]probeResult, len(r.agents))
+
+	var wg sync.WaitGroup
+	for i, a := range r.agents {
+		wg.Add(1)
+		go func(i int, a *agent) {
+			defer wg.Done()
+
+			c, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			list, err := a.client.ListChecks(c, &proto.Nil{})
+			if err != nil {
+				results[i].err = err
+				return
+			}
+
+			results[i].checks = make(map[string]struct{}, len(list.Checks))
+			for _, cid := range list.Checks {
+				results[i].checks[cid.ID] = struct{}{}
+			}
+		}(i, a)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, a := range r.agents {
+		if results[i].err != nil {
+			r.fail(a, results[i].err)
+			continue
+		}
+
+		a.healthy = true
+		a.failures = 0
+		a.lastSeen = time.Now()
+		a.lastErr = nil
+		a.checks = results[i].checks
+	}
+}
+
+// fail records a failed request to the agent. The registry should be
+// locked.
+func (r *registry) fail(a *agent, err error) {
+	a.failures++
+	a.lastErr = err
+
+	if a.failures >= r.unhealthyAfter {
+		a.healthy = false
+		// the agent might have restarted by the time it is back up, so the
+		// checks are pushed to it again.
+		a.pushed = map[string]string{}
+		a.rejected = map[string]string{}
+	}
+}
+
+// status returns the status of the agents and the checks that could not be
+// assigned to any or enough of them.
+func (r *registry) status() httpserver.CentralStatusResponse {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	load := map[*agent]int{}
+	for _, agents := range r.assigned {
+		for _, a := range agents {
+			load[a]++
+		}
+	}
+
+	resp := httpserver.CentralStatusResponse{
+		Agents:     make([]httpserver.AgentStatusResponse, len(r.agents)),
+		Unassigned: r.unassigned,
+		Partial:    r.partial,
+	}
+
+	for i, a := range r.agents {
+		resp.Agents[i] = httpserver.AgentStatusResponse{
+			Name:     a.name,
+			Address:  a.address,
+			Location: a.location,
+			Healthy:  a.healthy,
+			Capacity: a.capacity,
+			Checks:   load[a],
+			LastSeen: a.lastSeen,
+		}
+
+		if a.lastErr != nil {
+			resp.Agents[i].Error = a.lastErr.Error()
+		}
+	}
+
+	return resp
+}
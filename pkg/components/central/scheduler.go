@@ -0,0 +1,381 @@
+package central
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/sdslabs/pinger/pkg/components/agent/proto"
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// assign assigns each check to healthy agents with the capacity for it, one
+// in each of as many distinct locations as required. The registry should be
+// locked.
+//
+// A check stays with the agents it is assigned to as long as they are
+// healthy, else it is adopted by a healthy agent that is already running
+// it, else it is assigned to the healthy agent with the least checks, in a
+// location the check does not run from yet.
+func (r *registry) assign(checks []database.Check) {
+	locations := r.locations
+	if locations < 1 {
+		locations = 1
+	}
+
+	load := map[*agent]int{}
+	assigned := make(map[string][]*agent, len(checks))
+
+	// taken tells if the check already runs from the location of the agent.
+	taken := func(id string, a *agent) bool {
+		for _, b := range assigned[id] {
+			if b.location == a.location {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range checks {
+		id := checks[i].ID
+		for _, a := range r.assigned[id] {
+			if len(assigned[id]) == locations {
+				break
+			}
+
+			if a.healthy && a.fits(load[a]) && !taken(id, a) {
+				assigned[id] = append(assigned[id], a)
+				load[a]++
+			}
+		}
+	}
+
+	var unassigned, partial []string
+	for i := range checks {
+		id := checks[i].ID
+		for len(assigned[id]) < locations {
+			var chosen *agent
+			for _, a := range r.agents {
+				if !a.healthy || !a.fits(load[a]) || taken(id, a) {
+					continue
+				}
+
+				if _, ok := a.checks[id]; ok {
+					chosen = a
+					break
+				}
+
+				if chosen == nil || load[a] < load[chosen] {
+					chosen = a
+				}
+			}
+
+			if chosen == nil {
+				break
+			}
+
+			assigned[id] = append(assigned[id], chosen)
+			load[chosen]++
+		}
+
+		switch {
+		case len(assigned[id]) == 0:
+			unassigned = append(unassigned, id)
+		case len(assigned[id]) < locations:
+			partial = append(partial, id)
+		}
+	}
+
+	r.assigned = assigned
+	r.unassigned = unassigned
+	r.partial = partial
+}
+
+// syncResult is the result of syncing the checks of an agent.
+type syncResult struct {
+	pushed   map[string]string
+	rejected map[string]string
+	removed  []string
+	err      error
+}
+
+// sync pushes the checks assigned to each healthy agent that are either not
+// running on it or have changed since they were pushed, and removes the
+// checks that are running on it but are not assigned to it. A check the
+// agent rejected is not pushed to it again till the check changes.
+func (r *registry) sync(ctx *appcontext.Context, checks []*proto.Check) {
+	versions := make(map[string]string, len(checks))
+	for _, check := range checks {
+		versions[check.ID] = checkVersion(check)
+	}
+
+	r.mu.RLock()
+	toPush := map[*agent][]*proto.Check{}
+	for _, check := range checks {
+		for _, a := range r.assigned[check.ID] {
+			_, running := a.checks[check.ID]
+			if rejected, ok := a.rejected[check.ID]; ok && rejected == versions[check.ID] && rejected != "" {
+				continue
+			}
+
+			pushed, ok := a.pushed[check.ID]
+			if !running || !ok || pushed != versions[check.ID] || pushed == "" {
+				toPush[a] = append(toPush[a], check)
+			}
+		}
+	}
+
+	toRemove := map[*agent][]string{}
+	for _, a := range r.agents {
+		for id := range a.checks {
+			if !hasAgent(r.assigned[id], a) {
+				toRemove[a] = append(toRemove[a], id)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]syncResult, len(r.agents))
+
+	var wg sync.WaitGroup
+	for i, a := range r.agents {
+		if !a.healthy || (len(toPush[a]) == 0 && len(toRemove[a]) == 0) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, a *agent) {
+			defer wg.Done()
+			results[i] = r.syncAgent(ctx, a, toPush[a], toRemove[a], versions)
+		}(i, a)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, a := range r.agents {
+		for id, version := range results[i].pushed {
+			a.pushed[id] = version
+			a.checks[id] = struct{}{}
+			delete(a.rejected, id)
+		}
+
+		for id, version := range results[i].rejected {
+			a.rejected[id] = version
+		}
+
+		// forget the rejections of the checks that no longer exist.
+		for id := range a.rejected {
+			if _, ok := versions[id]; !ok {
+				delete(a.rejected, id)
+			}
+		}
+
+		for _, id := range results[i].removed {
+			delete(a.pushed, id)
+			delete(a.checks, id)
+		}
+
+		if results[i].err != nil {
+			r.fail(a, results[i].err)
+		}
+	}
+}
+
+// syncAgent pushes and removes the checks on the agent. It stops at the
+// first request that fails since the agent is likely down.
+func (r *registry) syncAgent(
+	ctx *appcontext.Context,
+	a *agent,
+	push []*proto.Check,
+	remove []string,
+	versions map[string]string,
+) syncResult {
+	res := syncResult{pushed: map[string]string{}, rejected: map[string]string{}}
+
+	for _, check := range push {
+		c, cancel := context.WithTimeout(ctx, r.timeout)
+		resp, err := a.client.PushCheck(c, check)
+		cancel()
+		if err != nil {
+			res.err = fmt.Errorf("cannot push check %q: %w", check.ID, err)
+			return res
+		}
+
+		// the check itself is invalid, which is not a fault of the agent.
+		if !resp.Successful {
+			ctx.Logger().
+				WithField("agent", a.name).
+				WithField("check_id", check.ID).
+				Errorf("agent rejected check: %s", resp.Error)
+			res.rejected[check.ID] = versions[check.ID]
+			continue
+		}
+
+		res.pushed[check.ID] = versions[check.ID]
+	}
+
+	for _, id := range remove {
+		c, cancel := context.WithTimeout(ctx, r.timeout)
+		resp, err := a.client.RemoveCheck(c, &proto.CheckID{ID: id})
+		cancel()
+		if err != nil {
+			res.err = fmt.Errorf("cannot remove check %q: %w", id, err)
+			return res
+		}
+
+		if !resp.Successful {
+			// the check is stopped even if its alert state could not be
+			// cleaned up, so it is not removed again.
+			ctx.Logger().
+				WithField("agent", a.name).
+				WithField("check_id", id).
+				Warnf("agent could not clean up removed check: %s", resp.Error)
+		}
+
+		res.removed = append(res.removed, id)
+	}
+
+	return res
+}
+
+// reconcile brings the checks running on the agents in line with the checks
+// in the database, along with the maintenances of the pages they are on.
+func reconcile(ctx *appcontext.Context, conn *database.Conn, r *registry) error {
+	checks, err := conn.GetAllChecks(ctx, database.GetCheckOpts{
+		Outputs:  true,
+		Payloads: true,
+	})
+	if err != nil {
+		// the agents are left as they are since the checks are unknown.
+		return fmt.Errorf("cannot get checks: %w", err)
+	}
+
+	maintenances, err := conn.GetAllMaintenances(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get maintenances: %w", err)
+	}
+
+	r.probe(ctx)
+
+	r.mu.Lock()
+	r.assign(checks)
+	unassigned, partial := len(r.unassigned), len(r.partial)
+	r.mu.Unlock()
+
+	if unassigned > 0 {
+		ctx.Logger().
+			WithField("checks", unassigned).
+			Warnln("not enough healthy agents to assign checks")
+	}
+
+	if partial > 0 {
+		ctx.Logger().
+			WithField("checks", partial).
+			Warnln("not enough healthy agents to run checks from all locations")
+	}
+
+	r.sync(ctx, checksToProto(checks, maintenances))
+	return nil
+}
+
+// checkVersion returns the version of the check as it is pushed, which
+// changes when the check or any of its maintenances change. It is empty if
+// the version is unknown, in which case the check is always pushed.
+func checkVersion(check *proto.Check) string {
+	b, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(check)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// checksToProto converts the checks in the database to the checks pushed to
+// the agents, each with the maintenances that apply to it.
+func checksToProto(checks []database.Check, maintenances []database.Maintenance) []*proto.Check {
+	applies := map[string][]*proto.Maintenance{}
+	for i := range maintenances {
+		m := maintenanceToProto(&maintenances[i])
+		for _, id := range maintenances[i].AppliesTo() {
+			applies[id] = append(applies[id], m)
+		}
+	}
+
+	protos := make([]*proto.Check, len(checks))
+	for i := range checks {
+		protos[i] = checkToProto(&checks[i])
+		protos[i].Maintenances = applies[checks[i].ID]
+	}
+
+	return protos
+}
+
+// maintenanceToProto converts the maintenance in the database to the one
+// pushed with the checks.
+func maintenanceToProto(m *database.Maintenance) *proto.Maintenance {
+	p := &proto.Maintenance{
+		Name:        m.Title,
+		Description: m.Description,
+		Start:       m.Start.UnixNano(),
+		Duration:    int64(m.Duration),
+		Recurrence:  m.Recurrence,
+		Timezone:    m.Timezone,
+	}
+
+	if m.Until != nil {
+		p.Until = m.Until.UnixNano()
+	}
+
+	return p
+}
+
+// checkToProto converts the check in the database to the check pushed to
+// the agents.
+func checkToProto(check *database.Check) *proto.Check {
+	outputs := make([]*proto.Component, len(check.Outputs))
+	for i := range check.Outputs {
+		outputs[i] = &proto.Component{
+			Type:  check.Outputs[i].Type,
+			Value: check.Outputs[i].Value,
+		}
+	}
+
+	payloads := make([]*proto.Component, len(check.Payloads))
+	for i := range check.Payloads {
+		payloads[i] = &proto.Component{
+			Type:  check.Payloads[i].Type,
+			Value: check.Payloads[i].Value,
+		}
+	}
+
+	return &proto.Check{
+		ID:       check.ID,
+		Name:     check.Title,
+		Interval: int64(check.Interval),
+		Timeout:  int64(check.Timeout),
+		Input: &proto.Component{
+			Type:  check.InputType,
+			Value: check.InputValue,
+		},
+		Outputs:  outputs,
+		Operator: check.Operator,
+		Target: &proto.Component{
+			Type:  check.TargetType,
+			Value: check.TargetValue,
+		},
+		Payloads: payloads,
+
+		Retries:          int32(check.Retries),
+		RetryInterval:    int64(check.RetryInterval),
+		FailureThreshold: int32(check.FailureThreshold),
+		SuccessThreshold: int32(check.SuccessThreshold),
+	}
+}
@@ -9,6 +9,16 @@ import (
 
 // Check is the configuration of each check associated with each check.
 //
+// A check can either have a single `Output` or multiple `Outputs` which are
+// combined using the `Operator` ("AND" or "OR"). If both are specified, the
+// single output is considered to be the first of the outputs.
+//
+// A failed check is retried `Retries` times, waiting for `RetryInterval`
+// between each attempt, before the run is marked as failed. Further, the
+// check is only declared down after `FailureThreshold` consecutive failed
+// runs and up after `SuccessThreshold` consecutive successful runs. Both the
+// thresholds default to 1.
+//
 // Implements checker.Check interface.
 type Check struct {
 	ID       string        `mapstructure:"id" json:"id"`
@@ -17,9 +27,16 @@ type Check struct {
 	Timeout  time.Duration `mapstructure:"timeout" json:"timeout"`
 	Input    Component     `mapstructure:"input" json:"input"`
 	Output   Component     `mapstructure:"output" json:"output"`
+	Outputs  []Component   `mapstructure:"outputs" json:"outputs"`
+	Operator string        `mapstructure:"operator" json:"operator"`
 	Target   Component     `mapstructure:"target" json:"target"`
 	Payloads []Component   `mapstructure:"payloads" json:"payloads"`
 	Alerts   []Alert       `mapstructure:"alerts" json:"alerts"`
+
+	Retries          int           `mapstructure:"retries" json:"retries"`
+	RetryInterval    time.Duration `mapstructure:"retry_interval" json:"retry_interval"`
+	FailureThreshold int           `mapstructure:"failure_threshold" json:"failure_threshold"`
+	SuccessThreshold int           `mapstructure:"success_threshold" json:"success_threshold"`
 }
 
 // GetID returns the ID for the check.
@@ -47,9 +64,21 @@ func (c *Check) GetInput() checker.Component {
 	return &c.Input
 }
 
-// GetOutput returns the output of the check.
-func (c *Check) GetOutput() checker.Component {
-	return &c.Output
+// GetOutputs returns the outputs of the check.
+func (c *Check) GetOutputs() []checker.Component {
+	outputs := make([]checker.Component, 0, len(c.Outputs)+1)
+	if c.Output.Type != "" {
+		outputs = append(outputs, &c.Output)
+	}
+	for i := range c.Outputs {
+		outputs = append(outputs, &c.Outputs[i])
+	}
+	return outputs
+}
+
+// GetOperator returns the operator that combines the outputs.
+func (c *Check) GetOperator() string {
+	return c.Operator
 }
 
 // GetTarget returns the target of the check.
@@ -84,6 +113,36 @@ func (c *Component) GetValue() string {
 	return c.Value
 }
 
+// GetRetries returns the number of times a failed check is retried.
+func (c *Check) GetRetries() int {
+	return c.Retries
+}
+
+// GetRetryInterval returns the interval between the retries.
+func (c *Check) GetRetryInterval() time.Duration {
+	return c.RetryInterval
+}
+
+// GetFailureThreshold returns the number of consecutive failures after
+// which the check is declared down.
+func (c *Check) GetFailureThreshold() int {
+	if c.FailureThreshold <= 0 {
+		return 1
+	}
+
+	return c.FailureThreshold
+}
+
+// GetSuccessThreshold returns the number of consecutive successes after
+// which the check is declared up.
+func (c *Check) GetSuccessThreshold() int {
+	if c.SuccessThreshold <= 0 {
+		return 1
+	}
+
+	return c.SuccessThreshold
+}
+
 // ProtoToCheck converts a proto.Check into checker.Check.
 func ProtoToCheck(check *proto.Check) Check {
 	payloads := make([]Component, len(check.Payloads))
@@ -94,6 +153,14 @@ func ProtoToCheck(check *proto.Check) Check {
 		}
 	}
 
+	outputs := make([]Component, len(check.Outputs))
+	for i := range check.Outputs {
+		outputs[i] = Component{
+			Type:  check.Outputs[i].Type,
+			Value: check.Outputs[i].Value,
+		}
+	}
+
 	alerts := make([]Alert, len(check.Alerts))
 	for i := range check.Alerts {
 		alerts[i] = Alert{
@@ -111,15 +178,22 @@ func ProtoToCheck(check *proto.Check) Check {
 			Value: check.Input.Value,
 		},
 		Output: Component{
-			Type:  check.Output.Type,
-			Value: check.Output.Value,
+			Type:  check.GetOutput().GetType(),
+			Value: check.GetOutput().GetValue(),
 		},
+		Outputs:  outputs,
+		Operator: check.Operator,
 		Target: Component{
 			Type:  check.Target.Type,
 			Value: check.Target.Value,
 		},
 		Payloads: payloads,
 		Alerts:   alerts,
+
+		Retries:          int(check.Retries),
+		RetryInterval:    time.Duration(check.RetryInterval),
+		FailureThreshold: int(check.FailureThreshold),
+		SuccessThreshold: int(check.SuccessThreshold),
 	}
 }
 
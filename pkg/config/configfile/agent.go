@@ -17,15 +17,97 @@ type AgentPage struct {
 	Logo           string   `mapstructure:"logo" json:"logo"`
 	Favicon        string   `mapstructure:"favicon" json:"favicon"`
 	Website        string   `mapstructure:"website" json:"website"`
+	SLA            AgentSLA `mapstructure:"sla" json:"sla"`
+}
+
+// AgentSLA defines how the availability of the checks is computed for the
+// status page.
+type AgentSLA struct {
+	// Windows are the windows to compute the availability over, either a
+	// calendar period, i.e., "day", "month", "quarter" or "year", or a
+	// rolling duration like "24h" or "30d".
+	Windows []string `mapstructure:"windows" json:"windows"`
+
+	// Precision is the number of decimal places of the availability.
+	Precision int `mapstructure:"precision" json:"precision"`
+
+	// Missing is how the time without metrics is counted, either "ignore",
+	// "up" or "down".
+	Missing string `mapstructure:"missing" json:"missing"`
+}
+
+// AgentStats defines how the agent keeps the stats of the checks until they
+// are exported.
+type AgentStats struct {
+	// Capacity is the maximum number of stats kept for each check.
+	Capacity int `mapstructure:"capacity" json:"capacity"`
+
+	// Overflow is the policy when the capacity is reached, either
+	// "drop-oldest" or "summarize".
+	Overflow string `mapstructure:"overflow" json:"overflow"`
+}
+
+// AgentBuffer defines the buffer that holds the metrics until they are
+// exported.
+type AgentBuffer struct {
+	// Path is the directory where the metrics are buffered. If empty, the
+	// metrics are buffered in memory and lost when the agent restarts.
+	Path string `mapstructure:"path" json:"path"`
+
+	// MaxBackoff is the maximum time to wait before retrying the export.
+	MaxBackoff time.Duration `mapstructure:"max_backoff" json:"max_backoff"`
+
+	// MaxSize is the maximum size in bytes of the metrics buffered for each
+	// exporter. The oldest metrics are dropped when it is reached.
+	MaxSize int64 `mapstructure:"max_size" json:"max_size"`
+
+	// MaxAge is the time after which the metrics yet to be exported are
+	// dropped.
+	MaxAge time.Duration `mapstructure:"max_age" json:"max_age"`
+}
+
+// AgentConsensus defines how the status of a check that runs from agents in
+// multiple locations is decided. The agents should export the metrics into
+// the same exporter they read the metrics from.
+type AgentConsensus struct {
+	// Quorum is the number of locations that should agree for a check to be
+	// down. If it is 1 or less, any one location can mark the check down.
+	Quorum int `mapstructure:"quorum" json:"quorum"`
+
+	// Window is the time after which the latest metric of a location is too
+	// old to count.
+	Window time.Duration `mapstructure:"window" json:"window"`
+
+	// Locations are the locations the checks run from, including the one of
+	// the agent. The first of them that has voted sends the alerts, so they
+	// should be in the same order for all the agents.
+	Locations []string `mapstructure:"locations" json:"locations"`
 }
 
 // Agent represents the configuration for an agent.
+//
+// Metrics are exported into each of the exporters. If no exporters are
+// provided, the metrics provider is used as the only exporter.
+//
+// Maintenance windows apply to all the checks, unless they list the checks
+// they apply to.
+//
+// The metrics are labelled with the location of the agent, so that the
+// status of a check that runs from multiple locations is decided by the
+// consensus of the locations.
 type Agent struct {
-	Standalone bool                   `mapstructure:"standalone" json:"standalone"`
-	Page       AgentPage              `mapstructure:"page" json:"page"`
-	Port       uint16                 `mapstructure:"port" json:"port"`
-	Metrics    config.MetricsProvider `mapstructure:"metrics" json:"metrics"`
-	Alerts     []config.AlertProvider `mapstructure:"alerts" json:"alerts"`
-	Interval   time.Duration          `mapstructure:"interval" json:"interval"`
-	Checks     []config.Check         `mapstructure:"checks" json:"checks"`
+	Standalone  bool                      `mapstructure:"standalone" json:"standalone"`
+	Location    string                    `mapstructure:"location" json:"location"`
+	Consensus   AgentConsensus            `mapstructure:"consensus" json:"consensus"`
+	Page        AgentPage                 `mapstructure:"page" json:"page"`
+	Port        uint16                    `mapstructure:"port" json:"port"`
+	Metrics     config.MetricsProvider    `mapstructure:"metrics" json:"metrics"`
+	Exporters   []config.MetricsProvider  `mapstructure:"exporters" json:"exporters"`
+	Alerts      []config.AlertProvider    `mapstructure:"alerts" json:"alerts"`
+	State       config.StateStoreProvider `mapstructure:"state" json:"state"`
+	Stats       AgentStats                `mapstructure:"stats" json:"stats"`
+	Buffer      AgentBuffer               `mapstructure:"buffer" json:"buffer"`
+	Interval    time.Duration             `mapstructure:"interval" json:"interval"`
+	Checks      []config.Check            `mapstructure:"checks" json:"checks"`
+	Maintenance []config.Maintenance      `mapstructure:"maintenance" json:"maintenance"`
 }
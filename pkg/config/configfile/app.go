@@ -7,9 +7,16 @@ type App struct {
 	// Port to run the app server on.
 	Port uint16 `mapstructure:"port" json:"port"`
 
+	// AllowedOrigins which can request the app server.
+	AllowedOrigins []string `mapstructure:"allowed_origins" json:"allowed_origins"`
+
 	// Secret for signing tokens.
 	Secret string `mapstructure:"secret" json:"secret"`
 
+	// SecureCookies should be set when the app server is served over HTTPS,
+	// and is required if the client is on a different site.
+	SecureCookies bool `mapstructure:"secure_cookies" json:"secure_cookies"`
+
 	// Oauth providers with configuration.
 	Oauth []config.OauthProvider `mapstructure:"oauth" json:"oauth"`
 
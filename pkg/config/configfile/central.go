@@ -0,0 +1,53 @@
+package configfile
+
+import (
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/config"
+)
+
+// CentralAgent is an agent registered with the central server.
+type CentralAgent struct {
+	// Name to identify the agent.
+	Name string `mapstructure:"name" json:"name"`
+
+	// Address of the gRPC API of the agent, i.e., "host:port".
+	Address string `mapstructure:"address" json:"address"`
+
+	// Location of the agent. Agents without a location are each in a
+	// location of their own.
+	Location string `mapstructure:"location" json:"location"`
+
+	// Capacity is the maximum number of checks assigned to the agent. There
+	// is no limit if it is 0.
+	Capacity int `mapstructure:"capacity" json:"capacity"`
+}
+
+// Central is the configuration file for central server.
+//
+// The central server owns the checks of the agents registered with it, so
+// the agents should not have checks of their own in their config.
+type Central struct {
+	// Port to serve the status of the agents on.
+	Port uint16 `mapstructure:"port" json:"port"`
+
+	// Interval after which the checks on the agents are reconciled.
+	Interval time.Duration `mapstructure:"interval" json:"interval"`
+
+	// Timeout of each request to an agent.
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout"`
+
+	// UnhealthyAfter is the number of consecutive failed requests after
+	// which an agent is unhealthy and its checks are reassigned.
+	UnhealthyAfter int `mapstructure:"unhealthy_after" json:"unhealthy_after"`
+
+	// Locations is the number of distinct locations each check runs from,
+	// so that its status can be decided by their consensus.
+	Locations int `mapstructure:"locations" json:"locations"`
+
+	// Agents registered with the central server.
+	Agents []CentralAgent `mapstructure:"agents" json:"agents"`
+
+	// Database connection.
+	Database config.DBConn `mapstructure:"database" json:"database"`
+}
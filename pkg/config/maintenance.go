@@ -0,0 +1,92 @@
+package config
+
+import (
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/components/agent/proto"
+	"github.com/sdslabs/pinger/pkg/maintenance"
+)
+
+// Maintenance is the configuration of a scheduled maintenance window.
+//
+// The window recurs according to `Recurrence` which is either a cron
+// expression or a recurrence rule. It applies to all the checks of the page
+// unless `Checks` are specified.
+//
+// Implements the maintenance.Window interface.
+type Maintenance struct {
+	Name        string        `mapstructure:"name" json:"name"`
+	Description string        `mapstructure:"description" json:"description"`
+	Start       time.Time     `mapstructure:"start" json:"start"`
+	Duration    time.Duration `mapstructure:"duration" json:"duration"`
+	Recurrence  string        `mapstructure:"recurrence" json:"recurrence"`
+	Until       time.Time     `mapstructure:"until" json:"until"`
+	Timezone    string        `mapstructure:"timezone" json:"timezone"`
+	Checks      []string      `mapstructure:"checks" json:"checks"`
+}
+
+// GetName returns the name of the maintenance.
+func (m *Maintenance) GetName() string {
+	return m.Name
+}
+
+// GetDescription returns the description of the maintenance.
+func (m *Maintenance) GetDescription() string {
+	return m.Description
+}
+
+// GetStart returns the start of the first occurrence.
+func (m *Maintenance) GetStart() time.Time {
+	return m.Start
+}
+
+// GetDuration returns the length of each occurrence.
+func (m *Maintenance) GetDuration() time.Duration {
+	return m.Duration
+}
+
+// GetRecurrence returns the cron expression or the recurrence rule.
+func (m *Maintenance) GetRecurrence() string {
+	return m.Recurrence
+}
+
+// GetUntil returns the time after which the window does not recur.
+func (m *Maintenance) GetUntil() time.Time {
+	return m.Until
+}
+
+// GetTimezone returns the time zone of the recurrence.
+func (m *Maintenance) GetTimezone() string {
+	return m.Timezone
+}
+
+// GetChecks returns the IDs of the checks under maintenance.
+func (m *Maintenance) GetChecks() []string {
+	return m.Checks
+}
+
+// ProtoToMaintenances converts the maintenances pushed with the check into
+// the maintenance windows of the check.
+func ProtoToMaintenances(check *proto.Check) []Maintenance {
+	maintenances := make([]Maintenance, len(check.Maintenances))
+	for i, m := range check.Maintenances {
+		maintenances[i] = Maintenance{
+			Name:        m.Name,
+			Description: m.Description,
+			Start:       time.Unix(0, m.Start).UTC(),
+			Duration:    time.Duration(m.Duration),
+			Recurrence:  m.Recurrence,
+			Timezone:    m.Timezone,
+			Checks:      []string{check.ID},
+		}
+
+		if m.Until != 0 {
+			maintenances[i].Until = time.Unix(0, m.Until).UTC()
+		}
+	}
+
+	return maintenances
+}
+
+// Interface guard.
+var _ maintenance.Window = (*Maintenance)(nil)
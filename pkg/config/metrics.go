@@ -7,16 +7,41 @@ import (
 	"github.com/sdslabs/pinger/pkg/exporter"
 )
 
-// Metric represents the result of a check.
+// Metric represents the result of a check. It is also how the metrics are
+// serialized by the exporters that keep them themselves.
 //
 // Implements the checker.Metric interface.
 type Metric struct {
-	CheckID    string
-	CheckName  string
-	Successful bool
-	Timeout    bool
-	StartTime  time.Time
-	Duration   time.Duration
+	CheckID    string        `json:"check_id"`
+	CheckName  string        `json:"check_name"`
+	Location   string        `json:"location"`
+	Successful bool          `json:"successful"`
+	Timeout    bool          `json:"timeout"`
+	Degraded   bool          `json:"degraded"`
+	StartTime  time.Time     `json:"start_time"`
+	Duration   time.Duration `json:"duration"`
+
+	FailureCode    string `json:"failure_code"`
+	FailureMessage string `json:"failure_message"`
+
+	Measurements map[string]interface{} `json:"measurements"`
+}
+
+// NewMetric copies the checker metric into a metric.
+func NewMetric(m checker.Metric) *Metric {
+	return &Metric{
+		CheckID:        m.GetCheckID(),
+		CheckName:      m.GetCheckName(),
+		Location:       m.GetLocation(),
+		Successful:     m.IsSuccessful(),
+		Timeout:        m.IsTimeout(),
+		Degraded:       m.IsDegraded(),
+		StartTime:      m.GetStartTime(),
+		Duration:       m.GetDuration(),
+		FailureCode:    m.GetFailureCode(),
+		FailureMessage: m.GetFailureMessage(),
+		Measurements:   m.GetMeasurements(),
+	}
 }
 
 // GetCheckID returns the ID of the check for which the metric is.
@@ -29,6 +54,11 @@ func (m *Metric) GetCheckName() string {
 	return m.CheckName
 }
 
+// GetLocation returns the location of the agent the check ran from.
+func (m *Metric) GetLocation() string {
+	return m.Location
+}
+
 // IsSuccessful tells if the check was successful.
 func (m *Metric) IsSuccessful() bool {
 	return m.Successful
@@ -39,6 +69,11 @@ func (m *Metric) IsTimeout() bool {
 	return m.Timeout
 }
 
+// IsDegraded tells if the check took longer than the latency threshold.
+func (m *Metric) IsDegraded() bool {
+	return m.Degraded
+}
+
 // GetStartTime returns the start-time of the check.
 func (m *Metric) GetStartTime() time.Time {
 	return m.StartTime
@@ -49,10 +84,27 @@ func (m *Metric) GetDuration() time.Duration {
 	return m.Duration
 }
 
+// GetFailureCode returns the reason of failure of the check.
+func (m *Metric) GetFailureCode() string {
+	return m.FailureCode
+}
+
+// GetFailureMessage returns the detailed message of the failure.
+func (m *Metric) GetFailureMessage() string {
+	return m.FailureMessage
+}
+
+// GetMeasurements returns the measurements recorded during the check.
+func (m *Metric) GetMeasurements() map[string]interface{} {
+	return m.Measurements
+}
+
 // MetricsProvider represents the configuration of a metrics exporter.
 //
 // Implements the metrics.Provider interface.
 type MetricsProvider struct {
+	Name     string `mapstructure:"name" json:"name"`
+	Read     bool   `mapstructure:"read" json:"read"`
 	Backend  string `mapstructure:"backend" json:"backend"`
 	Host     string `mapstructure:"host" json:"host"`
 	Port     uint16 `mapstructure:"port" json:"port"`
@@ -61,6 +113,26 @@ type MetricsProvider struct {
 	Username string `mapstructure:"username" json:"username"`
 	Password string `mapstructure:"password" json:"password"`
 	SSLMode  bool   `mapstructure:"ssl_mode" json:"ssl_mode"`
+
+	Retention   MetricsRetention   `mapstructure:"retention" json:"retention"`
+	RemoteWrite MetricsRemoteWrite `mapstructure:"remotewrite" json:"remotewrite"`
+	OTLP        MetricsOTLP        `mapstructure:"otlp" json:"otlp"`
+	File        MetricsFile        `mapstructure:"file" json:"file"`
+}
+
+// GetName returns the name of the provider to identify it among multiple
+// providers. It defaults to the backend.
+func (m *MetricsProvider) GetName() string {
+	if m.Name == "" {
+		return m.Backend
+	}
+
+	return m.Name
+}
+
+// IsRead tells if the metrics are to be read from the provider.
+func (m *MetricsProvider) IsRead() bool {
+	return m.Read
 }
 
 // GetBackend returns the backend of the provider.
@@ -104,8 +176,150 @@ func (m *MetricsProvider) IsSSLMode() bool {
 	return m.SSLMode
 }
 
+// GetRetention returns the retention for the providers that clean up the old
+// metrics.
+func (m *MetricsProvider) GetRetention() exporter.RetentionConfig {
+	return &m.Retention
+}
+
+// GetRemoteWrite returns the config for the remotewrite provider.
+func (m *MetricsProvider) GetRemoteWrite() exporter.RemoteWriteConfig {
+	return &m.RemoteWrite
+}
+
+// GetOTLP returns the config for the otlp provider.
+func (m *MetricsProvider) GetOTLP() exporter.OTLPConfig {
+	return &m.OTLP
+}
+
+// GetFile returns the config for the file provider.
+func (m *MetricsProvider) GetFile() exporter.FileConfig {
+	return &m.File
+}
+
+// MetricsRetention represents how long the metrics are retained.
+//
+// Implements the exporter.RetentionConfig interface.
+type MetricsRetention struct {
+	Duration       time.Duration `mapstructure:"duration" json:"duration"`
+	Rollups        bool          `mapstructure:"rollups" json:"rollups"`
+	RollupDuration time.Duration `mapstructure:"rollup_duration" json:"rollup_duration"`
+}
+
+// GetDuration returns the duration to retain the metrics for.
+func (r *MetricsRetention) GetDuration() time.Duration {
+	return r.Duration
+}
+
+// HasRollups tells if the metrics are to be rolled up into hourly and daily
+// aggregates in the providers that support them.
+func (r *MetricsRetention) HasRollups() bool {
+	return r.Rollups
+}
+
+// GetRollupDuration returns the duration to retain the rollups for.
+func (r *MetricsRetention) GetRollupDuration() time.Duration {
+	return r.RollupDuration
+}
+
+// MetricsRemoteWrite represents the configuration of the remotewrite
+// provider.
+//
+// Implements the exporter.RemoteWriteConfig interface.
+type MetricsRemoteWrite struct {
+	WritePath string `mapstructure:"write_path" json:"write_path"`
+	QueryPath string `mapstructure:"query_path" json:"query_path"`
+}
+
+// GetWritePath returns the path of the endpoint the metrics are written into.
+func (r *MetricsRemoteWrite) GetWritePath() string {
+	return r.WritePath
+}
+
+// GetQueryPath returns the path of the endpoint the metrics are queried from.
+func (r *MetricsRemoteWrite) GetQueryPath() string {
+	return r.QueryPath
+}
+
+// MetricsOTLP represents the configuration of the otlp provider.
+//
+// Implements the exporter.OTLPConfig interface.
+type MetricsOTLP struct {
+	Protocol    string `mapstructure:"protocol" json:"protocol"`
+	Path        string `mapstructure:"path" json:"path"`
+	ServiceName string `mapstructure:"service_name" json:"service_name"`
+}
+
+// GetProtocol returns the protocol to connect with the collector.
+func (o *MetricsOTLP) GetProtocol() string {
+	return o.Protocol
+}
+
+// GetPath returns the path of the endpoint the metrics are sent to over HTTP.
+func (o *MetricsOTLP) GetPath() string {
+	return o.Path
+}
+
+// GetServiceName returns the name of the service sending the metrics.
+func (o *MetricsOTLP) GetServiceName() string {
+	return o.ServiceName
+}
+
+// MetricsFile represents the configuration of the file provider.
+//
+// Implements the exporter.FileConfig interface.
+type MetricsFile struct {
+	Path           string        `mapstructure:"path" json:"path"`
+	Format         string        `mapstructure:"format" json:"format"`
+	MaxSize        int64         `mapstructure:"max_size" json:"max_size"`
+	RotateInterval time.Duration `mapstructure:"rotate_interval" json:"rotate_interval"`
+	Compress       bool          `mapstructure:"compress" json:"compress"`
+	MaxFiles       int           `mapstructure:"max_files" json:"max_files"`
+	MaxAge         time.Duration `mapstructure:"max_age" json:"max_age"`
+}
+
+// GetPath returns the path of the file the metrics are written into.
+func (f *MetricsFile) GetPath() string {
+	return f.Path
+}
+
+// GetFormat returns the format the metrics are written in.
+func (f *MetricsFile) GetFormat() string {
+	return f.Format
+}
+
+// GetMaxSize returns the size in bytes after which the file is rotated.
+func (f *MetricsFile) GetMaxSize() int64 {
+	return f.MaxSize
+}
+
+// GetRotateInterval returns the interval after which the file is rotated.
+func (f *MetricsFile) GetRotateInterval() time.Duration {
+	return f.RotateInterval
+}
+
+// IsCompress tells if the rotated files are to be compressed.
+func (f *MetricsFile) IsCompress() bool {
+	return f.Compress
+}
+
+// GetMaxFiles returns the number of rotated files to keep. The older ones are
+// deleted.
+func (f *MetricsFile) GetMaxFiles() int {
+	return f.MaxFiles
+}
+
+// GetMaxAge returns the duration after which the rotated files are deleted.
+func (f *MetricsFile) GetMaxAge() time.Duration {
+	return f.MaxAge
+}
+
 // Interface guards.
 var (
-	_ checker.Metric    = (*Metric)(nil)
-	_ exporter.Provider = (*MetricsProvider)(nil)
+	_ checker.Metric             = (*Metric)(nil)
+	_ exporter.Provider          = (*MetricsProvider)(nil)
+	_ exporter.RetentionConfig   = (*MetricsRetention)(nil)
+	_ exporter.RemoteWriteConfig = (*MetricsRemoteWrite)(nil)
+	_ exporter.OTLPConfig        = (*MetricsOTLP)(nil)
+	_ exporter.FileConfig        = (*MetricsFile)(nil)
 )
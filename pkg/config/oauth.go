@@ -10,6 +10,7 @@ type OauthProvider struct {
 	ClientSecret string   `mapstructure:"client_secret" json:"client_secret"`
 	RedirectURL  string   `mapstructure:"redirect_url" json:"redirect_url"`
 	Scopes       []string `mapstructure:"scopes" json:"scopes"`
+	Issuer       string   `mapstructure:"issuer" json:"issuer"`
 }
 
 // GetProvider returns the provider name.
@@ -37,5 +38,10 @@ func (o *OauthProvider) GetScopes() []string {
 	return o.Scopes
 }
 
+// GetIssuer returns the issuer URL.
+func (o *OauthProvider) GetIssuer() string {
+	return o.Issuer
+}
+
 // Interface guard.
 var _ oauther.Provider = (*OauthProvider)(nil)
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/statestore"
+)
+
+// StateStoreProvider represents the configuration of the store that
+// persists the alert state of checks.
+//
+// Implements the statestore.Provider interface.
+type StateStoreProvider struct {
+	Backend  string `mapstructure:"backend" json:"backend"`
+	Path     string `mapstructure:"path" json:"path"`
+	Database DBConn `mapstructure:"database" json:"database"`
+}
+
+// GetBackend returns the backend of the store.
+func (s *StateStoreProvider) GetBackend() string {
+	return s.Backend
+}
+
+// GetPath returns the path of the file for file based stores.
+func (s *StateStoreProvider) GetPath() string {
+	return s.Path
+}
+
+// GetDatabase returns the database config for database based stores.
+func (s *StateStoreProvider) GetDatabase() database.Config {
+	return &s.Database
+}
+
+// Interface guard.
+var _ statestore.Provider = (*StateStoreProvider)(nil)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -170,6 +172,7 @@ func (c *Conn) CreateCheck(ctx context.Context, ownerID uint, check *Check) (*Ch
 type GetCheckOpts struct {
 	Owner bool
 
+	Outputs  bool
 	Payloads bool
 }
 
@@ -182,6 +185,10 @@ func (c *Conn) GetCheck(ctx context.Context, ownerID uint, checkID string, opts
 		tx = tx.Preload("Owner")
 	}
 
+	if opts.Outputs {
+		tx = tx.Preload("Outputs")
+	}
+
 	if opts.Payloads {
 		tx = tx.Preload("Payloads")
 	}
@@ -191,6 +198,28 @@ func (c *Conn) GetCheck(ctx context.Context, ownerID uint, checkID string, opts
 	return &check, tx.Error
 }
 
+// GetAllChecks gets the checks of all the users, such as for scheduling them
+// on the agents.
+func (c *Conn) GetAllChecks(ctx context.Context, opts GetCheckOpts) ([]Check, error) {
+	tx := c.db.WithContext(ctx)
+
+	if opts.Owner {
+		tx = tx.Preload("Owner")
+	}
+
+	if opts.Outputs {
+		tx = tx.Preload("Outputs")
+	}
+
+	if opts.Payloads {
+		tx = tx.Preload("Payloads")
+	}
+
+	var checks []Check
+	tx = tx.Order("id").Find(&checks)
+	return checks, tx.Error
+}
+
 // UpdateCheck updates a check with the given ID.
 func (c *Conn) UpdateCheck(ctx context.Context, ownerID uint, checkID string, check *Check) (*Check, error) {
 	if check == nil {
@@ -203,6 +232,35 @@ func (c *Conn) UpdateCheck(ctx context.Context, ownerID uint, checkID string, ch
 	return &ch, tx.Error
 }
 
+// ReplaceCheckOutputs replaces the outputs of the check with the given ID.
+func (c *Conn) ReplaceCheckOutputs(ctx context.Context, ownerID uint, checkID string, outputs []Output) error {
+	ch := rawCheckWithID(ownerID, checkID)
+
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the check is updated as well, so that it is known to have changed.
+		res := tx.Model(&Check{}).Where(&ch).Update("updated_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		err := tx.Where(&Output{CheckID: checkID}).Unscoped().Delete(&Output{}).Error
+		if err != nil || len(outputs) == 0 {
+			return err
+		}
+
+		for i := range outputs {
+			outputs[i].ID = 0
+			outputs[i].CheckID = checkID
+		}
+
+		return tx.Create(&outputs).Error
+	})
+}
+
 // DeleteCheck deletes the check with the given ID.
 func (c *Conn) DeleteCheck(ctx context.Context, ownerID uint, checkID string) error {
 	ch := rawCheckWithID(ownerID, checkID)
@@ -309,9 +367,10 @@ func (c *Conn) CreatePage(ctx context.Context, ownerID uint, page *Page) (*Page,
 type GetPageOpts struct {
 	Owner bool
 
-	Checks    bool
-	Incidents bool
-	Team      bool
+	Checks       bool
+	Incidents    bool
+	Team         bool
+	Maintenances bool
 }
 
 // GetPage gets a page from given pageID.
@@ -335,6 +394,10 @@ func (c *Conn) GetPage(ctx context.Context, ownerID, pageID uint, opts GetPageOp
 		tx = tx.Preload("Team.User")
 	}
 
+	if opts.Maintenances {
+		tx = tx.Preload("Maintenances.Checks")
+	}
+
 	page := Page{}
 	tx = tx.Find(&page)
 	return &page, tx.Error
@@ -433,6 +496,142 @@ func (c *Conn) DeleteIncident(ctx context.Context, ownerID, pageID, incidentID u
 	return tx.Error
 }
 
+// rawMaintenanceWithID returns an empty maintenance with the given ID.
+func rawMaintenanceWithID(ownerID, pageID, maintenanceID uint) Maintenance {
+	m := Maintenance{}
+	m.OwnerID = ownerID
+	m.PageID = pageID
+	m.ID = maintenanceID
+	return m
+}
+
+// CreateMaintenance creates a new maintenance for the page.
+func (c *Conn) CreateMaintenance(
+	ctx context.Context,
+	ownerID, pageID uint,
+	maintenance *Maintenance,
+) (*Maintenance, error) {
+	if maintenance == nil {
+		return nil, fmt.Errorf("*Maintenance: %w", ErrNilPointer)
+	}
+
+	maintenance.OwnerID = ownerID
+	maintenance.PageID = pageID
+	err := c.db.WithContext(ctx).Create(maintenance).Error
+	return maintenance, err
+}
+
+// GetMaintenanceOpts are the options to preload maintenance associations.
+type GetMaintenanceOpts struct {
+	Owner  bool
+	Page   bool
+	Checks bool
+}
+
+// GetMaintenance gets a maintenance from given maintenanceID.
+func (c *Conn) GetMaintenance(
+	ctx context.Context,
+	ownerID, pageID, maintenanceID uint,
+	opts GetMaintenanceOpts,
+) (*Maintenance, error) {
+	m := rawMaintenanceWithID(ownerID, pageID, maintenanceID)
+	tx := c.db.WithContext(ctx).Where(m)
+
+	if opts.Owner {
+		tx = tx.Preload("Owner")
+	}
+
+	if opts.Page {
+		tx = tx.Preload("Page")
+	}
+
+	if opts.Checks {
+		tx = tx.Preload("Checks")
+	}
+
+	maintenance := Maintenance{}
+	tx = tx.Find(&maintenance)
+	return &maintenance, tx.Error
+}
+
+// GetMaintenancesOfPage gets all the maintenances of the page along with
+// the checks they are added to.
+func (c *Conn) GetMaintenancesOfPage(ctx context.Context, ownerID, pageID uint) ([]Maintenance, error) {
+	m := rawMaintenanceWithID(ownerID, pageID, 0)
+
+	var maintenances []Maintenance
+	tx := c.db.WithContext(ctx).Where(m).Preload("Checks").Order("start").Find(&maintenances)
+	return maintenances, tx.Error
+}
+
+// GetAllMaintenances gets the maintenances of all the users along with the
+// checks they are added to and the checks of their pages, such as for pushing
+// them to the agents with the checks.
+func (c *Conn) GetAllMaintenances(ctx context.Context) ([]Maintenance, error) {
+	var maintenances []Maintenance
+	tx := c.db.WithContext(ctx).Preload("Checks").Preload("Page.Checks").Order("id").Find(&maintenances)
+	return maintenances, tx.Error
+}
+
+// UpdateMaintenance updates a maintenance with the given ID.
+func (c *Conn) UpdateMaintenance(
+	ctx context.Context,
+	ownerID, pageID, maintenanceID uint,
+	maintenance *Maintenance,
+) (*Maintenance, error) {
+	if maintenance == nil {
+		return nil, fmt.Errorf("*Maintenance: %w", ErrNilPointer)
+	}
+
+	m := rawMaintenanceWithID(ownerID, pageID, maintenanceID)
+
+	tx := c.db.WithContext(ctx).Model(Maintenance{}).Where(&m).Updates(*maintenance)
+	return &m, tx.Error
+}
+
+// DeleteMaintenance deletes the maintenance with the given ID.
+func (c *Conn) DeleteMaintenance(ctx context.Context, ownerID, pageID, maintenanceID uint) error {
+	m := rawMaintenanceWithID(ownerID, pageID, maintenanceID)
+
+	tx := c.db.WithContext(ctx).Where(&m).Unscoped().Delete(&Maintenance{})
+	return tx.Error
+}
+
+// AddChecksToMaintenance adds relationship between the checks and the
+// maintenance, so that it only applies to these checks of the page.
+func (c *Conn) AddChecksToMaintenance(
+	ctx context.Context,
+	ownerID, pageID, maintenanceID uint,
+	checkIDs []string,
+) error {
+	if len(checkIDs) == 0 {
+		return nil
+	}
+
+	m := rawMaintenanceWithID(ownerID, pageID, maintenanceID)
+	checks := checkSliceFromIDs(ownerID, checkIDs)
+
+	return c.db.WithContext(ctx).Model(&m).Where(&m).Association("Checks").Append(checks)
+}
+
+// RemoveChecksFromMaintenance removes relationship between the checks and
+// the maintenance. It applies to all the checks of the page once none are
+// left.
+func (c *Conn) RemoveChecksFromMaintenance(
+	ctx context.Context,
+	ownerID, pageID, maintenanceID uint,
+	checkIDs []string,
+) error {
+	if len(checkIDs) == 0 {
+		return nil
+	}
+
+	m := rawMaintenanceWithID(ownerID, pageID, maintenanceID)
+	checks := checkSliceFromIDs(ownerID, checkIDs)
+
+	return c.db.WithContext(ctx).Model(&m).Where(&m).Association("Checks").Delete(checks)
+}
+
 // checkSliceFromIDs returns a slice of raw checks from multiple IDs.
 func checkSliceFromIDs(ownerID uint, checkIDs []string) []Check {
 	checks := make([]Check, len(checkIDs))
@@ -525,3 +724,23 @@ func (c *Conn) RemoveTeamMemberFromPage(ctx context.Context, ownerID, pageID, me
 
 	return c.db.WithContext(ctx).Model(&p).Where(&p).Association("Team").Delete(pt)
 }
+
+// GetAlertState gets the alert state for the given key.
+func (c *Conn) GetAlertState(ctx context.Context, key string) (*AlertState, error) {
+	state := AlertState{}
+	err := c.db.WithContext(ctx).Where(&AlertState{Key: key}).First(&state).Error
+	return &state, err
+}
+
+// SetAlertState creates or updates the alert state for the given key.
+func (c *Conn) SetAlertState(ctx context.Context, key, state string) error {
+	return c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"state", "updated_at"}),
+	}).Create(&AlertState{Key: key, State: state}).Error
+}
+
+// DeleteAlertState deletes the alert state for the given key.
+func (c *Conn) DeleteAlertState(ctx context.Context, key string) error {
+	return c.db.WithContext(ctx).Where(&AlertState{Key: key}).Delete(&AlertState{}).Error
+}
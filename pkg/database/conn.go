@@ -27,20 +27,7 @@ type Conn struct{ db *gorm.DB }
 
 // NewConn creates a new connection with the database.
 func NewConn(ctx context.Context, conf Config) (*Conn, error) {
-	connStr := fmt.Sprintf(
-		`host=%s port=%d user=%s dbname=%s password=%s`,
-		conf.GetHost(),
-		conf.GetPort(),
-		conf.GetUsername(),
-		conf.GetName(),
-		conf.GetPassword(),
-	)
-
-	if !conf.IsSSLMode() {
-		connStr = fmt.Sprintf("%s sslmode=disable", connStr)
-	}
-
-	db, err := gorm.Open(postgres.Open(connStr), &gorm.Config{})
+	db, err := open(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -53,14 +40,82 @@ func NewConn(ctx context.Context, conf Config) (*Conn, error) {
 	err = db.WithContext(ctx).AutoMigrate(
 		&User{},
 		&Check{},
+		&Output{},
 		&Payload{},
 		&Page{},
 		&Incident{},
 		&PageTeam{},
+		&AlertState{},
+		&Maintenance{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = migrateOutputs(db.WithContext(ctx)); err != nil {
+		return nil, fmt.Errorf("cannot migrate outputs of checks: %w", err)
+	}
+
 	return &Conn{db: db}, nil
 }
+
+// NewAlertStateConn creates a new connection with the database that is only
+// used to store the alert states. Unlike `NewConn`, only the table of the
+// alert states is migrated.
+func NewAlertStateConn(ctx context.Context, conf Config) (*Conn, error) {
+	db, err := open(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.WithContext(ctx).AutoMigrate(&AlertState{}); err != nil {
+		return nil, err
+	}
+
+	return &Conn{db: db}, nil
+}
+
+// open opens the connection with the database.
+func open(conf Config) (*gorm.DB, error) {
+	connStr := fmt.Sprintf(
+		`host=%s port=%d user=%s dbname=%s password=%s`,
+		conf.GetHost(),
+		conf.GetPort(),
+		conf.GetUsername(),
+		conf.GetName(),
+		conf.GetPassword(),
+	)
+
+	if !conf.IsSSLMode() {
+		connStr = fmt.Sprintf("%s sslmode=disable", connStr)
+	}
+
+	return gorm.Open(postgres.Open(connStr), &gorm.Config{})
+}
+
+// migrateOutputs moves the output of each check from the columns of the
+// checks table, where it was kept before checks could have multiple outputs,
+// into the outputs table. The columns are dropped afterwards since they can
+// not be left empty for the new checks.
+func migrateOutputs(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Check{}, "output_type") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO outputs (created_at, updated_at, check_id, type, value)
+			SELECT NOW(), NOW(), id, output_type, COALESCE(output_value, '')
+			FROM checks
+			WHERE output_type IS NOT NULL AND output_type <> ''
+				AND NOT EXISTS (SELECT 1 FROM outputs WHERE outputs.check_id = checks.id);
+		`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(
+			"ALTER TABLE checks DROP COLUMN IF EXISTS output_type, DROP COLUMN IF EXISTS output_value;",
+		).Error
+	})
+}
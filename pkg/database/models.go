@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/sdslabs/pinger/pkg/maintenance"
 )
 
 // Bool for storing in models as a rune.
@@ -34,6 +36,8 @@ type User struct {
 	Pages     []Page     `gorm:"foreignkey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	TeamPages []PageTeam `gorm:"foreignkey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Incidents []Incident `gorm:"foreignkey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	Maintenances []Maintenance `gorm:"foreignkey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
 
 // Check model.
@@ -51,11 +55,16 @@ type Check struct {
 	Interval time.Duration `gorm:"DEFAULT:30"`
 	Timeout  time.Duration `gorm:"DEFAULT:30"`
 
+	Retries          int           `gorm:"DEFAULT:0"`
+	RetryInterval    time.Duration `gorm:"DEFAULT:0"`
+	FailureThreshold int           `gorm:"DEFAULT:1"`
+	SuccessThreshold int           `gorm:"DEFAULT:1"`
+
 	InputType  string `gorm:"NOT NULL"`
 	InputValue string `gorm:"NOT NULL"`
 
-	OutputType  string `gorm:"NOT NULL"`
-	OutputValue string `gorm:"NOT NULL"`
+	Operator string   `gorm:"DEFAULT:'AND'"`
+	Outputs  []Output `gorm:"foreignkey:CheckID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 
 	TargetType  string `gorm:"NOT NULL"`
 	TargetValue string `gorm:"NOT NULL"`
@@ -63,6 +72,17 @@ type Check struct {
 	Payloads []Payload `gorm:"foreignkey:CheckID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
 
+// Output model.
+type Output struct {
+	gorm.Model
+
+	CheckID string
+	Check   Check
+
+	Type  string `gorm:"NOT NULL"`
+	Value string `gorm:"NOT NULL;TYPE:text"`
+}
+
 // Payload model.
 type Payload struct {
 	gorm.Model
@@ -91,6 +111,8 @@ type Page struct {
 	Checks    []Check    `gorm:"many2many:page_checks"`
 	Incidents []Incident `gorm:"foreignkey:PageID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Team      []PageTeam `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	Maintenances []Maintenance `gorm:"foreignkey:PageID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 // Incident model.
@@ -111,6 +133,102 @@ type Incident struct {
 	Duration  time.Duration `gorm:"NOT NULL"`
 }
 
+// Maintenance model.
+//
+// A maintenance applies to all the checks of the page unless it is added to
+// specific checks. It recurs according to the cron expression or recurrence
+// rule, if any.
+//
+// Implements the maintenance.Window interface.
+type Maintenance struct {
+	gorm.Model
+
+	Owner   User
+	OwnerID uint
+
+	PageID uint
+	Page   Page
+
+	Title       string `gorm:"NOT NULL"`
+	Description string `gorm:"TYPE:text"`
+
+	Start      time.Time     `gorm:"NOT NULL"`
+	Duration   time.Duration `gorm:"NOT NULL"`
+	Recurrence string
+	Until      *time.Time
+	Timezone   string
+
+	Checks []Check `gorm:"many2many:maintenance_checks"`
+}
+
+// GetName returns the title of the maintenance.
+func (m *Maintenance) GetName() string {
+	return m.Title
+}
+
+// GetDescription returns the description of the maintenance.
+func (m *Maintenance) GetDescription() string {
+	return m.Description
+}
+
+// GetStart returns the start of the first occurrence.
+func (m *Maintenance) GetStart() time.Time {
+	return m.Start
+}
+
+// GetDuration returns the length of each occurrence.
+func (m *Maintenance) GetDuration() time.Duration {
+	return m.Duration
+}
+
+// GetRecurrence returns the cron expression or the recurrence rule.
+func (m *Maintenance) GetRecurrence() string {
+	return m.Recurrence
+}
+
+// GetUntil returns the time after which the maintenance does not recur.
+func (m *Maintenance) GetUntil() time.Time {
+	if m.Until == nil {
+		return time.Time{}
+	}
+
+	return *m.Until
+}
+
+// GetTimezone returns the time zone of the recurrence.
+func (m *Maintenance) GetTimezone() string {
+	return m.Timezone
+}
+
+// GetChecks returns the IDs of the checks the maintenance is added to. The
+// checks should be preloaded.
+//
+// It is empty if the maintenance applies to all the checks of the page. Use
+// AppliesTo for the checks of the page it applies to.
+func (m *Maintenance) GetChecks() []string {
+	checks := make([]string, len(m.Checks))
+	for i := range m.Checks {
+		checks[i] = m.Checks[i].ID
+	}
+
+	return checks
+}
+
+// AppliesTo returns the IDs of the checks of the page the maintenance applies
+// to. The checks of both the maintenance and the page should be preloaded.
+func (m *Maintenance) AppliesTo() []string {
+	if len(m.Checks) > 0 {
+		return m.GetChecks()
+	}
+
+	checks := make([]string, len(m.Page.Checks))
+	for i := range m.Page.Checks {
+		checks[i] = m.Page.Checks[i].ID
+	}
+
+	return checks
+}
+
 // Various roles of a team member.
 const (
 	RoleDefault    = "DEFAULT"
@@ -128,3 +246,17 @@ type PageTeam struct {
 
 	Role string `gorm:"NOT NULL"`
 }
+
+// AlertState model.
+//
+// The key is usually the ID of the check but is not constrained to a check
+// in the database since checks can also be configured through the agent.
+type AlertState struct {
+	Key       string `gorm:"primaryKey"`
+	UpdatedAt time.Time
+
+	State string `gorm:"NOT NULL"`
+}
+
+// Interface guard.
+var _ maintenance.Window = (*Maintenance)(nil)
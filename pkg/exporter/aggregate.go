@@ -0,0 +1,278 @@
+package exporter
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+)
+
+// Aggregate is the aggregate of the runs of a check from a location that
+// started within a bucket of time.
+type Aggregate struct {
+	StartTime time.Time // Start of the bucket.
+	Location  string    // Location the check ran from.
+
+	Runs         int64 // Number of runs in the bucket.
+	Failures     int64 // Number of runs that failed.
+	Timeouts     int64 // Number of runs that timed out.
+	Degradations int64 // Number of runs that were degraded.
+
+	P50 time.Duration // Median of the time taken by the runs.
+	P95 time.Duration // 95th percentile of the time taken by the runs.
+	P99 time.Duration // 99th percentile of the time taken by the runs.
+}
+
+// Uptime returns the percentage of the runs that were successful.
+func (a *Aggregate) Uptime() float64 {
+	if a.Runs == 0 {
+		return 0
+	}
+
+	return float64(a.Runs-a.Failures) * 100 / float64(a.Runs)
+}
+
+// BucketWidth returns the width of each of the buckets when the duration is
+// divided into the given number of buckets. It is rounded up to a second so
+// that every database can bucket the metrics alike.
+func BucketWidth(duration time.Duration, buckets int) time.Duration {
+	if buckets <= 0 {
+		buckets = 1
+	}
+
+	width := ((duration/time.Duration(buckets) + time.Second - 1) / time.Second) * time.Second
+	if width < time.Second {
+		return time.Second
+	}
+
+	return width
+}
+
+// BucketStart returns the start of the first bucket when the past duration
+// is divided into the given number of buckets, along with their width.
+//
+// The buckets are aligned to their width and the last bucket is the one the
+// current time falls in, so the buckets do not change till it ends and the
+// metrics aggregated separately can be merged.
+func BucketStart(duration time.Duration, buckets int) (start time.Time, width time.Duration) {
+	if buckets <= 0 {
+		buckets = 1
+	}
+
+	width = BucketWidth(duration, buckets)
+	start = time.Now().Truncate(width).Add(-1 * width * time.Duration(buckets-1))
+	return start, width
+}
+
+// AggregateMetrics aggregates the metrics of each location into buckets of
+// the given width starting at `start`. It is used by the exporters that
+// cannot aggregate the metrics in the database.
+func AggregateMetrics(
+	metrics map[string][]checker.Metric,
+	start time.Time,
+	width time.Duration,
+) map[string][]Aggregate {
+	aggregates := make(map[string][]Aggregate, len(metrics))
+
+	for checkID, ms := range metrics {
+		durations := map[bucketKey][]time.Duration{}
+		buckets := map[bucketKey]*Aggregate{}
+
+		for _, m := range ms {
+			if m.GetStartTime().Before(start) {
+				continue
+			}
+
+			bucket := start.Add(m.GetStartTime().Sub(start) / width * width)
+			k := bucketKey{start: bucket.UnixNano(), location: m.GetLocation()}
+			a, ok := buckets[k]
+			if !ok {
+				a = &Aggregate{StartTime: bucket, Location: m.GetLocation()}
+				buckets[k] = a
+			}
+
+			a.Runs++
+			if !m.IsSuccessful() {
+				a.Failures++
+			}
+			if m.IsTimeout() {
+				a.Timeouts++
+			}
+			if m.IsDegraded() {
+				a.Degradations++
+			}
+
+			durations[k] = append(durations[k], m.GetDuration())
+		}
+
+		for k, a := range buckets {
+			ds := durations[k]
+			sort.Slice(ds, func(x, y int) bool { return ds[x] < ds[y] })
+
+			a.P50 = percentile(ds, 0.50)
+			a.P95 = percentile(ds, 0.95)
+			a.P99 = percentile(ds, 0.99)
+
+			aggregates[checkID] = append(aggregates[checkID], *a)
+		}
+
+		sortAggregates(aggregates[checkID])
+	}
+
+	return aggregates
+}
+
+// MergeAggregates adds the aggregates of `from` into `into`. Counts of the
+// buckets of a location that start at the same time are added while the percentiles are
+// averaged, weighted by the number of runs, which is only an estimate.
+func MergeAggregates(into, from map[string][]Aggregate) map[string][]Aggregate {
+	if into == nil {
+		into = map[string][]Aggregate{}
+	}
+
+	for checkID, as := range from {
+		index := make(map[bucketKey]int, len(into[checkID]))
+		for i := range into[checkID] {
+			index[keyOf(&into[checkID][i])] = i
+		}
+
+		for _, a := range as {
+			i, ok := index[keyOf(&a)]
+			if !ok {
+				index[keyOf(&a)] = len(into[checkID])
+				into[checkID] = append(into[checkID], a)
+				continue
+			}
+
+			b := &into[checkID][i]
+			runs := a.Runs + b.Runs
+			if runs > 0 {
+				b.P50 = weighted(a.P50, a.Runs, b.P50, b.Runs)
+				b.P95 = weighted(a.P95, a.Runs, b.P95, b.Runs)
+				b.P99 = weighted(a.P99, a.Runs, b.P99, b.Runs)
+			}
+
+			b.Runs = runs
+			b.Failures += a.Failures
+			b.Timeouts += a.Timeouts
+			b.Degradations += a.Degradations
+		}
+
+		sortAggregates(into[checkID])
+	}
+
+	return into
+}
+
+// CombineAggregates combines the aggregates of the locations of a check into
+// a single aggregate for each bucket, as decided by the consensus.
+//
+// The check is down in a bucket for as long as the quorum of locations is,
+// so the fraction of runs that failed is that of the location with the
+// quorum-th most failures, assuming the failures of the locations overlap.
+// If fewer locations than the quorum ran in the bucket, none of its runs
+// failed. Degradations and timeouts are combined alike. The percentiles are
+// averaged, weighted by the number of runs, as in MergeAggregates.
+func CombineAggregates(aggregates []Aggregate, consensus checker.Consensus) []Aggregate {
+	byStart := map[int64][]*Aggregate{}
+	for i := range aggregates {
+		a := &aggregates[i]
+		if a.Runs == 0 || !consensus.Votes(a.Location) {
+			continue
+		}
+
+		byStart[a.StartTime.UnixNano()] = append(byStart[a.StartTime.UnixNano()], a)
+	}
+
+	needed := consensus.Needed()
+	combined := make([]Aggregate, 0, len(byStart))
+	for _, as := range byStart {
+		c := Aggregate{StartTime: as[0].StartTime}
+		for _, a := range as {
+			if c.Runs+a.Runs > 0 {
+				c.P50 = weighted(a.P50, a.Runs, c.P50, c.Runs)
+				c.P95 = weighted(a.P95, a.Runs, c.P95, c.Runs)
+				c.P99 = weighted(a.P99, a.Runs, c.P99, c.Runs)
+			}
+
+			c.Runs += a.Runs
+		}
+
+		down := agreed(as, needed, func(a *Aggregate) int64 { return a.Failures })
+		degraded := agreed(as, needed, func(a *Aggregate) int64 { return a.Failures + a.Degradations })
+		timeout := agreed(as, needed, func(a *Aggregate) int64 { return a.Timeouts })
+
+		c.Failures = int64(math.Round(down * float64(c.Runs)))
+		c.Degradations = int64(math.Round(degraded*float64(c.Runs))) - c.Failures
+		c.Timeouts = int64(math.Round(math.Min(timeout, down) * float64(c.Runs)))
+		combined = append(combined, c)
+	}
+
+	sortAggregates(combined)
+	return combined
+}
+
+// agreed returns the fraction of the runs for which at least the needed
+// number of the aggregates agree, given the count of runs each one votes for.
+func agreed(aggregates []*Aggregate, needed int, count func(*Aggregate) int64) float64 {
+	if len(aggregates) < needed {
+		return 0
+	}
+
+	fractions := make([]float64, len(aggregates))
+	for i, a := range aggregates {
+		fractions[i] = float64(count(a)) / float64(a.Runs)
+	}
+
+	sort.Sort(sort.Reverse(sort.Float64Slice(fractions)))
+	return fractions[needed-1]
+}
+
+// SplitAggregates groups the aggregates of a check by their location.
+func SplitAggregates(aggregates []Aggregate) map[string][]Aggregate {
+	byLocation := map[string][]Aggregate{}
+	for i := range aggregates {
+		byLocation[aggregates[i].Location] = append(byLocation[aggregates[i].Location], aggregates[i])
+	}
+
+	return byLocation
+}
+
+// bucketKey identifies the bucket of a location.
+type bucketKey struct {
+	start    int64
+	location string
+}
+
+// keyOf returns the key of the bucket of the aggregate.
+func keyOf(a *Aggregate) bucketKey {
+	return bucketKey{start: a.StartTime.UnixNano(), location: a.Location}
+}
+
+// percentile returns the q-th quantile of the sorted durations using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	return sorted[i]
+}
+
+// weighted returns the mean of the durations weighted by the runs.
+func weighted(a time.Duration, aRuns int64, b time.Duration, bRuns int64) time.Duration {
+	return time.Duration((int64(a)*aRuns + int64(b)*bRuns) / (aRuns + bRuns))
+}
+
+// sortAggregates sorts the aggregates in descending order of start times.
+func sortAggregates(aggregates []Aggregate) {
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].StartTime.After(aggregates[j].StartTime)
+	})
+}
@@ -2,6 +2,7 @@ package exporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -43,7 +44,8 @@ type Exporter interface {
 	// sets other configuration for the exporter.
 	Provision(*appcontext.Context, Provider) error
 
-	// Export is the function that does the actual exporting.
+	// Export is the function that does the actual exporting. Errors which
+	// cannot succeed on a retry should be marked with `Permanent`.
 	Export(context.Context, []checker.Metric) error
 
 	// GetMetrics get the metrics for the given check IDs. It accepts a `duration`
@@ -54,6 +56,60 @@ type Exporter interface {
 	// the database much quicker than processing it on here so it is required
 	// that the exporter returns metrics in the correct order.
 	GetMetrics(_ context.Context, _ time.Duration, checkIDs ...string) (map[string][]checker.Metric, error)
+
+	// GetAggregates aggregates the metrics for the given check IDs in the past
+	// `duration`, divided into `buckets` buckets of equal width. The buckets
+	// begin at the start returned by `BucketStart`.
+	//
+	// Like `GetMetrics`, each of the aggregates array should be ordered in
+	// descending order of their start times. Buckets without any run of the
+	// check are left out.
+	GetAggregates(_ context.Context, _ time.Duration, buckets int, checkIDs ...string) (map[string][]Aggregate, error)
+}
+
+// Retainer is implemented by the exporters that drop the metrics older than
+// their retention.
+type Retainer interface {
+	// GetRetention returns how long the metrics are retained for. It is 0 if
+	// the metrics are kept forever.
+	GetRetention() time.Duration
+}
+
+// Forgetter is implemented by the exporters that keep the metrics of each of
+// the checks in memory, so that they can be dropped once the check is removed.
+type Forgetter interface {
+	// Forget drops the metrics kept for the check.
+	Forget(checkID string)
+}
+
+// permanentError is an error of the export that cannot succeed on a retry.
+type permanentError struct{ err error }
+
+// Error returns the message of the underlying error.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of the export as one that cannot succeed on a
+// retry, like the metrics being rejected by the provider. Such metrics are
+// dropped rather than exported again.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent tells if the error of the export cannot succeed on a retry.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
 }
 
 // ExportFunc is the function that is used to export the metrics into the
@@ -64,20 +120,52 @@ type ExportFunc = func(context.Context, []checker.Metric) error
 // the given checks.
 type GetterFunc = func(context.Context, time.Duration, ...string) (map[string][]checker.Metric, error)
 
-// Initialize method initializes the exporter and returns a function that
-// exports the metrics.
-func Initialize(ctx *appcontext.Context, provider Provider) (ExportFunc, GetterFunc, error) {
+// AggregatorFunc is the function that aggregates the metrics in the database
+// for the given checks.
+type AggregatorFunc = func(context.Context, time.Duration, int, ...string) (map[string][]Aggregate, error)
+
+// New creates the exporter of the provider's backend and provisions it.
+func New(ctx *appcontext.Context, provider Provider) (Exporter, error) {
 	name := provider.GetBackend()
 	newExporter, ok := exporters[name]
 	if !ok {
-		return nil, nil, fmt.Errorf("exporter with name does not exist: %s", name)
+		return nil, fmt.Errorf("exporter with name does not exist: %s", name)
 	}
 
 	exporter := newExporter()
 
 	if err := exporter.Provision(ctx, provider); err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Initialize method initializes the exporter and returns the functions that
+// export, get and aggregate the metrics.
+func Initialize(ctx *appcontext.Context, provider Provider) (ExportFunc, GetterFunc, AggregatorFunc, error) {
+	exporter, err := New(ctx, provider)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	return exporter.Export, exporter.GetMetrics, nil
+	return exporter.Export, exporter.GetMetrics, exporter.GetAggregates, nil
+}
+
+// GetRetention returns how long the exporter retains the metrics for. It is
+// 0 if the metrics are kept forever.
+func GetRetention(exporter Exporter) time.Duration {
+	if r, ok := exporter.(Retainer); ok {
+		return r.GetRetention()
+	}
+
+	return 0
+}
+
+// Forget drops the metrics the exporter keeps in memory for the check, if it
+// keeps any.
+func Forget(exporter Exporter, checkID string) {
+	if f, ok := exporter.(Forgetter); ok {
+		f.Forget(checkID)
+	}
 }
@@ -0,0 +1,8 @@
+// Package file implements the file exporter.
+//
+// The metrics are appended to a file as JSON Lines or CSV. The file is
+// rotated once it grows beyond the maximum size or after the rotation
+// interval, and the rotated files can be compressed with gzip and deleted
+// once there are too many of them or they are too old. Metrics are read back
+// by scanning the files, so the status page keeps working.
+package file
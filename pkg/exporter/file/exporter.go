@@ -0,0 +1,517 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const exporterName = "file"
+
+// Formats the metrics can be written in.
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+)
+
+const (
+	// rotatedTimeFormat is the format of the time of rotation that is added
+	// to the name of the rotated files.
+	rotatedTimeFormat = "20060102T150405.000000000Z"
+
+	// gzipExt is the extension added to the compressed files.
+	gzipExt = ".gz"
+)
+
+func init() {
+	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
+}
+
+// Exporter appends the metrics to a file.
+//
+// A rotated file is named after the file with the time of rotation added
+// before the extension, for example, `metrics-20210102T150405.000000000Z.jsonl`
+// for `metrics.jsonl`. Since every metric in a rotated file is older than the
+// time of rotation, the files that cannot have metrics in the requested
+// duration are skipped while reading.
+type Exporter struct {
+	path           string
+	format         string
+	maxSize        int64
+	rotateInterval time.Duration
+	compress       bool
+	maxFiles       int
+	maxAge         time.Duration
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// rotateMu is held for writing while the files are rotated, and for
+	// reading while they are scanned, so that the writes are not blocked
+	// by the reads.
+	rotateMu sync.RWMutex
+}
+
+// Provision sets e's configuration and opens the file.
+func (e *Exporter) Provision(_ *appcontext.Context, provider exporter.Provider) error {
+	if provider.GetBackend() != exporterName {
+		return fmt.Errorf(
+			"invalid exporter name: expected '%s'; got '%s'",
+			exporterName,
+			provider.GetBackend(),
+		)
+	}
+
+	conf := provider.GetFile()
+
+	e.format = conf.GetFormat()
+	switch e.format {
+	case "":
+		e.format = formatJSONL
+	case formatJSONL, formatCSV:
+	default:
+		return fmt.Errorf("invalid format: expected '%s' or '%s'; got '%s'",
+			formatJSONL, formatCSV, e.format)
+	}
+
+	e.path = conf.GetPath()
+	if e.path == "" {
+		e.path = "metrics." + e.format
+	}
+
+	e.maxSize = conf.GetMaxSize()
+	if e.maxSize < 0 {
+		return fmt.Errorf("max size should be >= 0")
+	}
+
+	e.rotateInterval = conf.GetRotateInterval()
+	if e.rotateInterval < 0 {
+		return fmt.Errorf("rotate interval should be >= 0")
+	}
+
+	e.compress = conf.IsCompress()
+
+	e.maxFiles = conf.GetMaxFiles()
+	e.maxAge = conf.GetMaxAge()
+	if e.maxFiles < 0 || e.maxAge < 0 {
+		return fmt.Errorf("max files and max age should be >= 0")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o750); err != nil {
+		return fmt.Errorf("cannot create directory: %w", err)
+	}
+
+	if err := e.prune(); err != nil {
+		return err
+	}
+
+	return e.open()
+}
+
+// Export appends the metrics to the file, rotating it first if required.
+func (e *Exporter) Export(_ context.Context, metrics []checker.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shouldRotate() {
+		e.rotateMu.Lock()
+		err := e.rotate()
+		e.rotateMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	var buf strings.Builder
+	switch e.format {
+	case formatCSV:
+		w := csv.NewWriter(&buf)
+		if e.size == 0 {
+			if err := w.Write(csvHeader); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range metrics {
+			record, err := toCSV(m)
+			if err != nil {
+				return fmt.Errorf("cannot encode metric: %w", err)
+			}
+
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+	default:
+		enc := json.NewEncoder(&buf)
+		for _, m := range metrics {
+			if err := enc.Encode(toJSON(m)); err != nil {
+				return fmt.Errorf("cannot encode metric: %w", err)
+			}
+		}
+	}
+
+	n, err := e.file.WriteString(buf.String())
+	e.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("cannot write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// GetMetrics get the metrics of the given checks by scanning the file and
+// the rotated files that can have metrics in the past `duration`.
+func (e *Exporter) GetMetrics(
+	_ context.Context,
+	duration time.Duration,
+	checkIDs ...string,
+) (map[string][]checker.Metric, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	after := time.Now().Add(-duration)
+
+	ids := make(map[string]struct{}, len(checkIDs))
+	for _, id := range checkIDs {
+		ids[id] = struct{}{}
+	}
+
+	metrics := map[string][]checker.Metric{}
+	collect := func(m *config.Metric) {
+		if _, ok := ids[m.CheckID]; !ok || !m.StartTime.After(after) {
+			return
+		}
+
+		metrics[m.CheckID] = append(metrics[m.CheckID], m)
+	}
+
+	// the files are scanned while the metrics are being written into them,
+	// so a batch may be read partially, but the lines that cannot be parsed
+	// are skipped anyway. Only the rotation waits for the scan.
+	e.rotateMu.RLock()
+	defer e.rotateMu.RUnlock()
+
+	rotated, err := e.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, r := range rotated {
+		if !r.rotatedAt.Before(after) {
+			paths = append(paths, r.path)
+		}
+	}
+
+	for _, path := range append(paths, e.path) {
+		if err := e.scan(path, collect); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ms := range metrics {
+		sort.SliceStable(ms, func(i, j int) bool {
+			return ms[i].GetStartTime().After(ms[j].GetStartTime())
+		})
+	}
+
+	return metrics, nil
+}
+
+// GetRetention returns how long the rotated files are retained for.
+func (e *Exporter) GetRetention() time.Duration {
+	return e.maxAge
+}
+
+// GetAggregates aggregates the metrics of the given checks after scanning
+// them from the files.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	start, width := exporter.BucketStart(duration, buckets)
+	metrics, err := e.GetMetrics(ctx, time.Since(start), checkIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.AggregateMetrics(metrics, start, width), nil
+}
+
+// open opens the file for appending.
+func (e *Exporter) open() error {
+	f, err := os.OpenFile(e.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+
+	e.file = f
+	e.size = info.Size()
+	e.openedAt = time.Now()
+	return nil
+}
+
+// shouldRotate tells if the file has to be rotated before writing.
+func (e *Exporter) shouldRotate() bool {
+	if e.size == 0 {
+		return false
+	}
+
+	if e.maxSize > 0 && e.size >= e.maxSize {
+		return true
+	}
+
+	return e.rotateInterval > 0 && time.Since(e.openedAt) >= e.rotateInterval
+}
+
+// rotate renames the file with the time of rotation, compresses it if
+// required, opens a new file and deletes the rotated files that are not to
+// be kept.
+func (e *Exporter) rotate() error {
+	if err := e.file.Close(); err != nil {
+		return fmt.Errorf("cannot rotate file: %w", err)
+	}
+
+	ext := filepath.Ext(e.path)
+	rotated := fmt.Sprintf("%s-%s%s",
+		strings.TrimSuffix(e.path, ext), time.Now().UTC().Format(rotatedTimeFormat), ext)
+
+	if err := os.Rename(e.path, rotated); err != nil {
+		return fmt.Errorf("cannot rotate file: %w", err)
+	}
+
+	if err := e.open(); err != nil {
+		return err
+	}
+
+	if e.compress {
+		if err := compressFile(rotated); err != nil {
+			return fmt.Errorf("cannot compress rotated file: %w", err)
+		}
+	}
+
+	return e.prune()
+}
+
+// prune deletes the rotated files that are older than the maximum age, and
+// the oldest ones over the maximum number of files.
+func (e *Exporter) prune() error {
+	if e.maxFiles == 0 && e.maxAge == 0 {
+		return nil
+	}
+
+	rotated, err := e.rotatedFiles()
+	if err != nil {
+		return err
+	}
+
+	for i, r := range rotated {
+		expired := e.maxAge > 0 && time.Since(r.rotatedAt) > e.maxAge
+		extra := e.maxFiles > 0 && len(rotated)-i > e.maxFiles
+		if !expired && !extra {
+			continue
+		}
+
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot delete rotated file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rotatedFile is a file that has been rotated.
+type rotatedFile struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// rotatedFiles returns the rotated files, oldest first.
+func (e *Exporter) rotatedFiles() ([]rotatedFile, error) {
+	ext := filepath.Ext(e.path)
+	prefix := strings.TrimSuffix(filepath.Base(e.path), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(e.path))
+	if err != nil {
+		return nil, fmt.Errorf("cannot list rotated files: %w", err)
+	}
+
+	var files []rotatedFile
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), gzipExt)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		rotatedAt, err := time.Parse(rotatedTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+
+		files = append(files, rotatedFile{
+			path:      filepath.Join(filepath.Dir(e.path), entry.Name()),
+			rotatedAt: rotatedAt,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].rotatedAt.Before(files[j].rotatedAt) })
+	return files, nil
+}
+
+// scan reads the metrics from the file, decompressing it if required.
+func (e *Exporter) scan(path string, collect func(*config.Metric)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+	defer f.Close() // nolint:errcheck
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, gzipExt) {
+		gz, er := gzip.NewReader(f)
+		if er != nil {
+			return fmt.Errorf("cannot read file %s: %w", path, er)
+		}
+		defer gz.Close() // nolint:errcheck
+		r = gz
+	}
+
+	if e.format == formatCSV {
+		return scanCSV(r, collect)
+	}
+
+	return scanJSONL(r, collect)
+}
+
+// scanJSONL reads the metrics from JSON Lines. Lines that cannot be parsed
+// are skipped.
+func scanJSONL(r io.Reader, collect func(*config.Metric)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for sc.Scan() {
+		m, err := fromJSON(sc.Bytes())
+		if err != nil {
+			continue
+		}
+
+		collect(m)
+	}
+
+	return sc.Err()
+}
+
+// scanCSV reads the metrics from CSV. The header and records that cannot
+// be parsed are skipped.
+func scanCSV(r io.Reader, collect func(*config.Metric)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		m, err := fromCSV(record)
+		if err != nil {
+			continue
+		}
+
+		collect(m)
+	}
+}
+
+// compressFile compresses the file with gzip and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close() // nolint:errcheck
+
+	tmp := path + gzipExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // nolint:errcheck
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close() // nolint:errcheck,gosec
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		dst.Close() // nolint:errcheck,gosec
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path+gzipExt); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Interface guards.
+var (
+	_ exporter.Exporter = (*Exporter)(nil)
+	_ exporter.Retainer = (*Exporter)(nil)
+)
@@ -0,0 +1,163 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config"
+)
+
+// csvHeader is the header of the CSV files, in the order of the columns.
+var csvHeader = []string{
+	"check_id",
+	"check_name",
+	"start_time",
+	"duration_seconds",
+	"successful",
+	"timeout",
+	"degraded",
+	"failure_code",
+	"failure_message",
+	"measurements",
+	"location",
+}
+
+// jsonMetric is the metric as it is written into the JSON Lines files. The
+// duration is written in seconds, like in the CSV files.
+type jsonMetric struct {
+	CheckID    string    `json:"check_id"`
+	CheckName  string    `json:"check_name"`
+	Location   string    `json:"location,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	Duration   float64   `json:"duration_seconds"`
+	Successful bool      `json:"successful"`
+	Timeout    bool      `json:"timeout"`
+	Degraded   bool      `json:"degraded"`
+
+	FailureCode    string `json:"failure_code,omitempty"`
+	FailureMessage string `json:"failure_message,omitempty"`
+
+	Measurements map[string]interface{} `json:"measurements,omitempty"`
+}
+
+// toJSON returns the JSON line of the metric.
+func toJSON(m checker.Metric) *jsonMetric {
+	return &jsonMetric{
+		CheckID:        m.GetCheckID(),
+		CheckName:      m.GetCheckName(),
+		Location:       m.GetLocation(),
+		StartTime:      m.GetStartTime(),
+		Duration:       m.GetDuration().Seconds(),
+		Successful:     m.IsSuccessful(),
+		Timeout:        m.IsTimeout(),
+		Degraded:       m.IsDegraded(),
+		FailureCode:    m.GetFailureCode(),
+		FailureMessage: m.GetFailureMessage(),
+		Measurements:   m.GetMeasurements(),
+	}
+}
+
+// fromJSON parses the JSON line into the metric.
+func fromJSON(line []byte) (*config.Metric, error) {
+	var j jsonMetric
+	if err := json.Unmarshal(line, &j); err != nil {
+		return nil, err
+	}
+
+	return &config.Metric{
+		CheckID:        j.CheckID,
+		CheckName:      j.CheckName,
+		Location:       j.Location,
+		Successful:     j.Successful,
+		Timeout:        j.Timeout,
+		Degraded:       j.Degraded,
+		StartTime:      j.StartTime,
+		Duration:       time.Duration(j.Duration * float64(time.Second)),
+		FailureCode:    j.FailureCode,
+		FailureMessage: j.FailureMessage,
+		Measurements:   j.Measurements,
+	}, nil
+}
+
+// toCSV returns the CSV record of the metric.
+func toCSV(m checker.Metric) ([]string, error) {
+	measurements := ""
+	if ms := m.GetMeasurements(); len(ms) > 0 {
+		b, err := json.Marshal(ms)
+		if err != nil {
+			return nil, err
+		}
+		measurements = string(b)
+	}
+
+	return []string{
+		m.GetCheckID(),
+		m.GetCheckName(),
+		m.GetStartTime().Format(time.RFC3339Nano),
+		strconv.FormatFloat(m.GetDuration().Seconds(), 'f', -1, 64),
+		strconv.FormatBool(m.IsSuccessful()),
+		strconv.FormatBool(m.IsTimeout()),
+		strconv.FormatBool(m.IsDegraded()),
+		m.GetFailureCode(),
+		m.GetFailureMessage(),
+		measurements,
+		m.GetLocation(),
+	}, nil
+}
+
+// fromCSV parses the CSV record into the metric.
+func fromCSV(record []string) (*config.Metric, error) {
+	// files written before the location was added have no location column.
+	if len(record) != len(csvHeader) && len(record) != len(csvHeader)-1 {
+		return nil, fmt.Errorf("expected %d columns; got %d", len(csvHeader), len(record))
+	}
+
+	var (
+		m   config.Metric
+		err error
+	)
+
+	m.CheckID = record[0]
+	m.CheckName = record[1]
+
+	if m.StartTime, err = time.Parse(time.RFC3339Nano, record[2]); err != nil {
+		return nil, err
+	}
+
+	seconds, err := strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Duration = time.Duration(seconds * float64(time.Second))
+
+	if m.Successful, err = strconv.ParseBool(record[4]); err != nil {
+		return nil, err
+	}
+
+	if m.Timeout, err = strconv.ParseBool(record[5]); err != nil {
+		return nil, err
+	}
+
+	if m.Degraded, err = strconv.ParseBool(record[6]); err != nil {
+		return nil, err
+	}
+
+	m.FailureCode = record[7]
+	m.FailureMessage = record[8]
+
+	if len(record) == len(csvHeader) {
+		m.Location = record[10]
+	}
+
+	if record[9] != "" {
+		if err := json.Unmarshal([]byte(record[9]), &m.Measurements); err != nil {
+			return nil, err
+		}
+	}
+
+	return &m, nil
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	client "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/influxdata/influxdb-client-go/v2/domain"
 
 	"github.com/sirupsen/logrus"
 
@@ -26,18 +29,67 @@ const exporterName = "influxdb"
 const (
 	keyCheckID      = "check_id"
 	keyCheckName    = "check_name"
+	keyLocation     = "location"
 	keyIsSuccessful = "is_successful"
 	keyIsTimeout    = "is_timeout"
+	keyIsDegraded   = "is_degraded"
 	keyStartTime    = "start_time"
 	keyDuration     = "duration"
+
+	// keyDurationNanos is the duration in nanoseconds, since the duration
+	// is stored as a string which cannot be aggregated.
+	keyDurationNanos = "duration_ns"
+
+	keyFailureCode    = "failure_code"
+	keyFailureMessage = "failure_message"
+
+	// keyMeasurementPrefix is prefixed to the key of each measurement which
+	// is stored as a separate field.
+	keyMeasurementPrefix = "measurement_"
+)
+
+// rollup keys
+const (
+	keyInterval     = "interval"
+	keyRuns         = "runs"
+	keySuccesses    = "successes"
+	keyTimeouts     = "timeouts"
+	keyDegradations = "degradations"
+	keyMeanDuration = "mean_duration"
+	keyMaxDuration  = "max_duration"
+)
+
+const (
+	// rollupBucketSuffix is added to the name of the bucket of the metrics
+	// to name the bucket of the rollups, so that they can be retained for
+	// longer than the metrics.
+	rollupBucketSuffix = "_rollups"
+
+	// rollupLookback is the number of intervals that are rolled up again
+	// on start, since rollups are not persisted by the exporter.
+	rollupLookback = 3
+
+	// rollupSchedule is the interval after which the metrics are rolled up.
+	rollupSchedule = time.Hour
 )
 
 // Exporter for exporting metrics to influxdb.
+//
+// The retention of the metrics is the retention of the bucket. When rollups
+// are enabled, the metrics are also aggregated into hourly and daily rollups
+// in a separate bucket which are read for long durations.
 type Exporter struct {
 	writeAPI api.WriteAPIBlocking
 	queryAPI api.QueryAPI
 	dbname   string
 	log      *logrus.Logger
+
+	retention      time.Duration
+	rollups        bool
+	rollupWriteAPI api.WriteAPIBlocking
+
+	mu          sync.RWMutex
+	rolledUntil map[time.Duration]time.Time
 }
 
 func init() {
@@ -85,12 +137,23 @@ func (e *Exporter) Export(ctx context.Context, metrics []checker.Metric) error {
 		tags := map[string]string{
 			keyCheckID: metric.GetCheckID(),
 		}
+		if location := metric.GetLocation(); location != "" {
+			tags[keyLocation] = location
+		}
 		fields := map[string]interface{}{
-			keyStartTime:    metric.GetStartTime(),
-			keyDuration:     metric.GetDuration(),
-			keyCheckName:    metric.GetCheckName(),
-			keyIsSuccessful: metric.IsSuccessful(),
-			keyIsTimeout:    metric.IsTimeout(),
+			keyStartTime:     metric.GetStartTime(),
+			keyDuration:      metric.GetDuration(),
+			keyDurationNanos: int64(metric.GetDuration()),
+			keyCheckName:     metric.GetCheckName(),
+			keyIsSuccessful:  metric.IsSuccessful(),
+			keyIsTimeout:     metric.IsTimeout(),
+			keyIsDegraded:    metric.IsDegraded(),
+
+			keyFailureCode:    metric.GetFailureCode(),
+			keyFailureMessage: metric.GetFailureMessage(),
+		}
+		for k, v := range metric.GetMeasurements() {
+			fields[keyMeasurementPrefix+k] = v
 		}
 		p := client.NewPoint("metrics", tags, fields, metric.GetStartTime())
 		points = append(points, p)
@@ -107,16 +170,27 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 	checkIDs []string,
 	duration time.Duration,
 ) (map[string][]checker.Metric, error) {
-	startTime := time.Now().Add(-1 * duration).Format(time.RFC3339Nano)
+	now := time.Now()
+	filter := fmt.Sprintf(" and r.check_id =~ %s", regexMatchIDs(checkIDs))
+	return e.queryMetrics(ctx, bucketName, filter, now.Add(-1*duration), now)
+}
 
+// queryMetrics fetches the metrics that match the filter and start between
+// `start` and `stop`.
+func (e *Exporter) queryMetrics(
+	ctx context.Context,
+	bucketName string,
+	filter string,
+	start, stop time.Time,
+) (map[string][]checker.Metric, error) {
 	query := fmt.Sprintf(`
 from(bucket:%q)
-	|> range(start: %s) 
-	|> filter(fn: (r) => r._measurement == "metrics" and r.check_id =~ %s )
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r._measurement == "metrics"%s)
 	|> sort(columns:["_time"], desc: true)
 	|> pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")
 	|> drop(columns:["_time", "_start", "_stop"])
-`, bucketName, startTime, regexMatchIDs(checkIDs))
+`, bucketName, start.Format(time.RFC3339Nano), stop.Format(time.RFC3339Nano), filter)
 	metrics := map[string][]checker.Metric{}
 	result, err := e.queryAPI.Query(ctx, query)
 	if err != nil {
@@ -142,13 +216,366 @@ from(bucket:%q)
 			Successful: result.Record().ValueByKey(keyIsSuccessful).(bool),
 		}
 
+		// metrics exported before degradation and failure reasons were
+		// recorded do not have these fields.
+		if degraded, ok := result.Record().ValueByKey(keyIsDegraded).(bool); ok {
+			metric.Degraded = degraded
+		}
+		if code, ok := result.Record().ValueByKey(keyFailureCode).(string); ok {
+			metric.FailureCode = code
+		}
+		if msg, ok := result.Record().ValueByKey(keyFailureMessage).(string); ok {
+			metric.FailureMessage = msg
+		}
+		if location, ok := result.Record().ValueByKey(keyLocation).(string); ok {
+			metric.Location = location
+		}
+
+		for k, v := range result.Record().Values() {
+			if v == nil || !strings.HasPrefix(k, keyMeasurementPrefix) {
+				continue
+			}
+
+			if metric.Measurements == nil {
+				metric.Measurements = map[string]interface{}{}
+			}
+			metric.Measurements[strings.TrimPrefix(k, keyMeasurementPrefix)] = v
+		}
+
 		if _, ok := metrics[metric.CheckID]; !ok {
 			metrics[metric.CheckID] = make([]checker.Metric, 0)
 		}
 		metrics[metric.CheckID] = append(metrics[metric.CheckID], &metric)
 	}
 
-	return metrics, nil
+	return metrics, result.Err()
+}
+
+// aggregateQuery aggregates the metrics into buckets. Each of the
+// aggregates is yielded as a separate result. The metrics of a check from
+// each location are aggregated separately.
+const aggregateQuery = `
+data = from(bucket:%q)
+	|> range(start: %s)
+	|> filter(fn: (r) => r._measurement == "metrics" and r.check_id =~ %s)
+	|> group(columns: ["check_id", "location", "_field"])
+	|> window(every: %ds, offset: %ds)
+
+successful = data |> filter(fn: (r) => r._field == "is_successful")
+durations = data |> filter(fn: (r) => r._field == "duration_ns")
+
+successful |> count() |> yield(name: "runs")
+successful |> map(fn: (r) => ({r with _value: if r._value then 0 else 1})) |> sum() |> yield(name: "failures")
+data |> filter(fn: (r) => r._field == "is_timeout") |> map(fn: (r) => ({r with _value: if r._value then 1 else 0})) |> sum() |> yield(name: "timeouts")
+data |> filter(fn: (r) => r._field == "is_degraded") |> map(fn: (r) => ({r with _value: if r._value then 1 else 0})) |> sum() |> yield(name: "degradations")
+durations |> quantile(q: 0.50) |> yield(name: "p50")
+durations |> quantile(q: 0.95) |> yield(name: "p95")
+durations |> quantile(q: 0.99) |> yield(name: "p99")
+`
+
+// GetRetention returns how long the metrics are retained for. The rollups are
+// not aggregated, so it is the retention of the raw metrics. It is 0 if the
+// retention of the bucket was left as-is.
+func (e *Exporter) GetRetention() time.Duration {
+	return e.retention
+}
+
+// GetAggregates aggregates the metrics of each location of the given checks
+// in the database. Metrics exported before the duration was stored in nanoseconds are left
+// out of the percentiles.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	start, width := exporter.BucketStart(duration, buckets)
+	widthSeconds := int64(width.Seconds())
+
+	query := fmt.Sprintf(aggregateQuery,
+		e.dbname,
+		start.Format(time.RFC3339Nano),
+		regexMatchIDs(checkIDs),
+		widthSeconds,
+		start.Unix()%widthSeconds,
+	)
+	result, err := e.queryAPI.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		checkID  string
+		location string
+		start    int64
+	}
+
+	byKey := map[key]*exporter.Aggregate{}
+	for result.Next() {
+		record := result.Record()
+		checkID, _ := record.ValueByKey(keyCheckID).(string)
+		location, _ := record.ValueByKey(keyLocation).(string)
+
+		k := key{checkID: checkID, location: location, start: record.Start().UnixNano()}
+		a, ok := byKey[k]
+		if !ok {
+			a = &exporter.Aggregate{StartTime: record.Start(), Location: location}
+			byKey[k] = a
+		}
+
+		// the name of the result is the aggregate the value is of.
+		name, _ := record.ValueByKey("result").(string)
+
+		switch v := record.Value().(type) {
+		case int64:
+			switch name {
+			case "runs":
+				a.Runs = v
+			case "failures":
+				a.Failures = v
+			case "timeouts":
+				a.Timeouts = v
+			case "degradations":
+				a.Degradations = v
+			}
+
+		case float64:
+			switch name {
+			case "p50":
+				a.P50 = time.Duration(v)
+			case "p95":
+				a.P95 = time.Duration(v)
+			case "p99":
+				a.P99 = time.Duration(v)
+			}
+		}
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	aggregates := map[string][]exporter.Aggregate{}
+	for k, a := range byKey {
+		if a.Runs == 0 {
+			continue
+		}
+
+		aggregates[k.checkID] = append(aggregates[k.checkID], *a)
+	}
+
+	for _, as := range aggregates {
+		sort.Slice(as, func(i, j int) bool {
+			return as[i].StartTime.After(as[j].StartTime)
+		})
+	}
+
+	return aggregates, nil
+}
+
+// rollUp aggregates the metrics into rollups of each interval periodically
+// till the context is canceled.
+func (e *Exporter) rollUp(ctx *appcontext.Context) {
+	ticker := time.NewTicker(rollupSchedule)
+	defer ticker.Stop()
+
+	for {
+		for _, every := range []time.Duration{exporter.RollupHourly, exporter.RollupDaily} {
+			if err := e.rollUpInterval(ctx, every); err != nil {
+				e.log.WithError(err).WithField("interval", every).Errorln("cannot roll up metrics")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollUpInterval aggregates the metrics of the intervals that ended since
+// the last rolled up interval. Rollups are points of the same series at the
+// start of the interval, so rolling up an interval again overwrites it.
+func (e *Exporter) rollUpInterval(ctx context.Context, every time.Duration) error {
+	to := time.Now().UTC().Truncate(every)
+
+	e.mu.RLock()
+	from, ok := e.rolledUntil[every]
+	e.mu.RUnlock()
+	if !ok {
+		from = to.Add(-rollupLookback * every)
+	}
+
+	if !from.Before(to) {
+		return nil
+	}
+
+	metrics, err := e.queryMetrics(ctx, e.dbname, "", from, to)
+	if err != nil {
+		return err
+	}
+
+	var all []checker.Metric
+	for _, ms := range metrics {
+		all = append(all, ms...)
+	}
+
+	rollups := exporter.RollUp(all, every)
+	points := make([]*write.Point, 0, len(rollups))
+	for _, r := range rollups {
+		tags := map[string]string{
+			keyCheckID:  r.CheckID,
+			keyInterval: every.String(),
+		}
+		fields := map[string]interface{}{
+			keyCheckName:    r.CheckName,
+			keyRuns:         r.Runs,
+			keySuccesses:    r.Successes,
+			keyTimeouts:     r.Timeouts,
+			keyDegradations: r.Degradations,
+			keyMeanDuration: int64(r.MeanDuration),
+			keyMaxDuration:  int64(r.MaxDuration),
+		}
+		points = append(points, client.NewPoint("rollups", tags, fields, r.StartTime))
+	}
+
+	if len(points) > 0 {
+		if err := e.rollupWriteAPI.WritePoint(ctx, points...); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	e.rolledUntil[every] = to
+	e.mu.Unlock()
+
+	return nil
+}
+
+// getRollupsByChecksAndDuration fetches the rollups of the given interval
+// that start in the past `duration`. The metrics after the last rolled up
+// interval are fetched raw.
+func (e *Exporter) getRollupsByChecksAndDuration(
+	ctx context.Context,
+	checkIDs []string,
+	duration time.Duration,
+	every time.Duration,
+) (map[string][]checker.Metric, error) {
+	now := time.Now()
+	start := now.Add(-1 * duration)
+
+	e.mu.RLock()
+	boundary, ok := e.rolledUntil[every]
+	e.mu.RUnlock()
+	if !ok || boundary.Before(start) {
+		return e.getMetricsByChecksAndDuration(ctx, e.dbname, checkIDs, duration)
+	}
+
+	metrics, err := e.getMetricsByChecksAndDuration(ctx, e.dbname, checkIDs, now.Sub(boundary))
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+from(bucket:%q)
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r._measurement == "rollups" and r.%s == %q and r.check_id =~ %s)
+	|> pivot(rowKey:["_time"], columnKey:["_field"], valueColumn:"_value")
+`, e.dbname+rollupBucketSuffix, start.Format(time.RFC3339Nano), boundary.Format(time.RFC3339Nano),
+		keyInterval, every.String(), regexMatchIDs(checkIDs))
+	result, err := e.queryAPI.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var rollups []*exporter.Rollup
+	for result.Next() {
+		record := result.Record()
+		r := &exporter.Rollup{
+			StartTime: record.Time(),
+			Interval:  every,
+		}
+		r.CheckID, _ = record.ValueByKey(keyCheckID).(string)
+		r.CheckName, _ = record.ValueByKey(keyCheckName).(string)
+		r.Runs, _ = record.ValueByKey(keyRuns).(int64)
+		r.Successes, _ = record.ValueByKey(keySuccesses).(int64)
+		r.Timeouts, _ = record.ValueByKey(keyTimeouts).(int64)
+		r.Degradations, _ = record.ValueByKey(keyDegradations).(int64)
+
+		meanDuration, _ := record.ValueByKey(keyMeanDuration).(int64)
+		maxDuration, _ := record.ValueByKey(keyMaxDuration).(int64)
+		r.MeanDuration = time.Duration(meanDuration)
+		r.MaxDuration = time.Duration(maxDuration)
+
+		rollups = append(rollups, r)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter.MergeRollups(metrics, rollups), nil
+}
+
+// setRetention updates the retention of the bucket.
+func setRetention(ctx context.Context, cli client.Client, bucketName string, retention time.Duration) error {
+	bucket, err := cli.BucketsAPI().FindBucketByName(ctx, bucketName)
+	if err != nil {
+		return fmt.Errorf("cannot find bucket %s: %w", bucketName, err)
+	}
+
+	bucket.RetentionRules = domain.RetentionRules{retentionRule(retention)}
+	if _, err := cli.BucketsAPI().UpdateBucket(ctx, bucket); err != nil {
+		return fmt.Errorf("cannot update retention of bucket %s: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// createRollupBucket creates the bucket of the rollups if it does not exist
+// and sets its retention.
+func createRollupBucket(
+	ctx context.Context,
+	cli client.Client,
+	orgName, bucketName string,
+	retention time.Duration,
+) error {
+	if _, err := cli.BucketsAPI().FindBucketByName(ctx, bucketName); err == nil {
+		if retention == 0 {
+			return nil
+		}
+
+		return setRetention(ctx, cli, bucketName, retention)
+	}
+
+	org, err := cli.OrganizationsAPI().FindOrganizationByName(ctx, orgName)
+	if err != nil {
+		return fmt.Errorf("cannot find organization %s: %w", orgName, err)
+	}
+
+	var rules []domain.RetentionRule
+	if retention != 0 {
+		rules = append(rules, retentionRule(retention))
+	}
+
+	if _, err := cli.BucketsAPI().CreateBucketWithName(ctx, org, bucketName, rules...); err != nil {
+		return fmt.Errorf("cannot create bucket %s: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// retentionRule creates the rule that expires data older than retention.
+func retentionRule(retention time.Duration) domain.RetentionRule {
+	return domain.RetentionRule{
+		EverySeconds: int(retention.Seconds()),
+		Type:         domain.RetentionRuleTypeExpire,
+	}
 }
 
 // regexMatchIDs creates a regex which matches all the given check IDs.
@@ -186,6 +613,10 @@ func (e *Exporter) GetMetrics(
 		return nil, nil
 	}
 
+	if every := exporter.RollupInterval(time); e.rollups && every != 0 {
+		return e.getRollupsByChecksAndDuration(ctx, checkIDs, time, every)
+	}
+
 	return e.getMetricsByChecksAndDuration(ctx, e.dbname, checkIDs, time)
 }
 
@@ -208,8 +639,39 @@ func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider
 	e.queryAPI = cli.QueryAPI(provider.GetOrgName())
 	e.dbname = provider.GetDBName()
 
+	retention := provider.GetRetention()
+	if retention.GetDuration() < 0 || retention.GetRollupDuration() < 0 {
+		return fmt.Errorf("retention should be >= 0")
+	}
+
+	// the retention of the bucket is left as-is unless one is provided.
+	e.retention = retention.GetDuration()
+	if e.retention != 0 {
+		if err := setRetention(ctx, cli, e.dbname, retention.GetDuration()); err != nil {
+			return err
+		}
+	}
+
+	e.rollups = retention.HasRollups()
+	if !e.rollups {
+		return nil
+	}
+
+	rollupBucket := e.dbname + rollupBucketSuffix
+	err = createRollupBucket(ctx, cli, provider.GetOrgName(), rollupBucket, retention.GetRollupDuration())
+	if err != nil {
+		return err
+	}
+
+	e.rollupWriteAPI = cli.WriteAPIBlocking(provider.GetOrgName(), rollupBucket)
+	e.rolledUntil = map[time.Duration]time.Time{}
+	go e.rollUp(ctx)
+
 	return nil
 }
 
-// Interface guard.
-var _ exporter.Exporter = (*Exporter)(nil)
+// Interface guards.
+var (
+	_ exporter.Exporter = (*Exporter)(nil)
+	_ exporter.Retainer = (*Exporter)(nil)
+)
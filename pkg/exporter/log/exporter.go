@@ -18,10 +18,16 @@ const exporterName = "log"
 const (
 	keyCheckID      = "check_id"
 	keyCheckName    = "check_name"
+	keyLocation     = "location"
 	keyIsSuccessful = "is_successful"
 	keyIsTimeout    = "is_timeout"
+	keyIsDegraded   = "is_degraded"
 	keyStartTime    = "start_time"
 	keyDuration     = "duration"
+
+	keyFailureCode    = "failure_code"
+	keyFailureMessage = "failure_message"
+	keyMeasurements   = "measurements"
 )
 
 func init() {
@@ -75,10 +81,16 @@ func (e *Exporter) logMetric(metric checker.Metric) {
 	e.logger.WithFields(logrus.Fields{
 		keyCheckID:      metric.GetCheckID(),
 		keyCheckName:    metric.GetCheckName(),
+		keyLocation:     metric.GetLocation(),
 		keyIsSuccessful: metric.IsSuccessful(),
 		keyIsTimeout:    metric.IsTimeout(),
+		keyIsDegraded:   metric.IsDegraded(),
 		keyStartTime:    metric.GetStartTime(),
 		keyDuration:     metric.GetDuration(),
+
+		keyFailureCode:    metric.GetFailureCode(),
+		keyFailureMessage: metric.GetFailureMessage(),
+		keyMeasurements:   metric.GetMeasurements(),
 	}).Infof("metrics for check (%s) %s", metric.GetCheckID(), metric.GetCheckName())
 }
 
@@ -95,5 +107,19 @@ func (e *Exporter) GetMetrics(
 	)
 }
 
+// GetAggregates returns error as could not be used with log exporter.
+func (e *Exporter) GetAggregates(
+	context.Context,
+	time.Duration,
+	int,
+	...string,
+) (map[string][]exporter.Aggregate, error) {
+	return nil, fmt.Errorf(
+		`the method is not implemented for exporter: %s,
+		log exporter is only meant to be used for debugging`,
+		exporterName,
+	)
+}
+
 // Interface guard.
 var _ exporter.Exporter = (*Exporter)(nil)
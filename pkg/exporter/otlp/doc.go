@@ -0,0 +1,6 @@
+// Package otlp implements the OpenTelemetry metrics exporter.
+//
+// Metrics are converted into OTLP gauge data points and sent to an
+// OpenTelemetry collector over OTLP/gRPC or OTLP/HTTP. The exporter can only
+// write metrics, so it cannot be used as the source of the status page.
+package otlp
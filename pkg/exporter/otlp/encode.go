@@ -0,0 +1,112 @@
+package otlp
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// attribute is a key-value pair attached to a resource or a data point.
+type attribute struct {
+	key, value string
+}
+
+// dataPoint is a single value of a gauge.
+type dataPoint struct {
+	attributes []attribute
+	timestamp  uint64 // in nanoseconds since the epoch
+	value      float64
+}
+
+// gauge is a metric with its data points.
+type gauge struct {
+	name        string
+	description string
+	unit        string
+	points      []dataPoint
+}
+
+// encodeRequest encodes the gauges as the `ExportMetricsServiceRequest`
+// message of OTLP with a single resource and scope:
+//
+//	message ExportMetricsServiceRequest { repeated ResourceMetrics resource_metrics = 1; }
+//	message ResourceMetrics { Resource resource = 1; repeated ScopeMetrics scope_metrics = 2; }
+//	message Resource { repeated KeyValue attributes = 1; }
+//	message ScopeMetrics { InstrumentationScope scope = 1; repeated Metric metrics = 2; }
+//	message InstrumentationScope { string name = 1; string version = 2; }
+//	message Metric { string name = 1; string description = 2; string unit = 3; Gauge gauge = 5; }
+//	message Gauge { repeated NumberDataPoint data_points = 1; }
+//	message NumberDataPoint { fixed64 time_unix_nano = 3; double as_double = 4; repeated KeyValue attributes = 7; }
+func encodeRequest(resource []attribute, scopeName, scopeVersion string, gauges []gauge) []byte {
+	var res []byte
+	for _, a := range resource {
+		res = appendMessage(res, 1, encodeKeyValue(a))
+	}
+
+	var scope []byte
+	scope = appendString(scope, 1, scopeName)
+	scope = appendString(scope, 2, scopeVersion)
+
+	var scopeMetrics []byte
+	scopeMetrics = appendMessage(scopeMetrics, 1, scope)
+	for _, g := range gauges {
+		scopeMetrics = appendMessage(scopeMetrics, 2, encodeGauge(g))
+	}
+
+	var resourceMetrics []byte
+	resourceMetrics = appendMessage(resourceMetrics, 1, res)
+	resourceMetrics = appendMessage(resourceMetrics, 2, scopeMetrics)
+
+	return appendMessage(nil, 1, resourceMetrics)
+}
+
+// encodeGauge encodes the `Metric` message with the gauge data.
+func encodeGauge(g gauge) []byte {
+	var data []byte
+	for _, p := range g.points {
+		var dp []byte
+		dp = protowire.AppendTag(dp, 3, protowire.Fixed64Type)
+		dp = protowire.AppendFixed64(dp, p.timestamp)
+		dp = protowire.AppendTag(dp, 4, protowire.Fixed64Type)
+		dp = protowire.AppendFixed64(dp, math.Float64bits(p.value))
+		for _, a := range p.attributes {
+			dp = appendMessage(dp, 7, encodeKeyValue(a))
+		}
+
+		data = appendMessage(data, 1, dp)
+	}
+
+	var metric []byte
+	metric = appendString(metric, 1, g.name)
+	metric = appendString(metric, 2, g.description)
+	metric = appendString(metric, 3, g.unit)
+	return appendMessage(metric, 5, data)
+}
+
+// encodeKeyValue encodes the `KeyValue` message with the string value of the
+// attribute:
+//
+//	message KeyValue { string key = 1; AnyValue value = 2; }
+//	message AnyValue { string string_value = 1; }
+func encodeKeyValue(a attribute) []byte {
+	var kv []byte
+	kv = appendString(kv, 1, a.key)
+	return appendMessage(kv, 2, appendString(nil, 1, a.value))
+}
+
+// appendString appends the string field, skipping it if empty as proto3
+// does.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendMessage appends the encoded message as a field.
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
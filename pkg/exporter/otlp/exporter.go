@@ -0,0 +1,368 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const exporterName = "otlp"
+
+// Protocols to send the metrics with.
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+)
+
+const (
+	// defaultGRPCPort is the default port of the OTLP/gRPC receiver.
+	defaultGRPCPort uint16 = 4317
+
+	// defaultHTTPPort is the default port of the OTLP/HTTP receiver.
+	defaultHTTPPort uint16 = 4318
+
+	// defaultWritePath is the default path of the OTLP/HTTP metrics
+	// endpoint.
+	defaultWritePath = "/v1/metrics"
+
+	// exportMethod is the gRPC method of the metrics service.
+	exportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+	// scopeName is the name of the instrumentation scope of the metrics.
+	scopeName = "github.com/sdslabs/pinger"
+
+	// requestTimeout is the time after which a request is canceled.
+	requestTimeout = 30 * time.Second
+)
+
+// Keys of the attributes.
+const (
+	attrServiceName       = "service.name"
+	attrServiceInstanceID = "service.instance.id"
+	attrHostName          = "host.name"
+	attrCheckID           = "check.id"
+	attrCheckName         = "check.name"
+	attrCheckLocation     = "check.location"
+	attrFailureCode       = "check.failure_code"
+)
+
+func init() {
+	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
+}
+
+// Exporter sends the metrics to an OpenTelemetry collector.
+//
+// Each metric is converted into a data point of four gauges, attributed with
+// the ID, the name and the location of the check at the start time of the
+// check:
+//
+//	pinger.check.up        1 if the check was successful
+//	pinger.check.degraded  1 if the check was degraded
+//	pinger.check.timeout   1 if the check timed-out
+//	pinger.check.duration  time taken by the check in seconds
+//
+// The resource identifies the agent with the host it runs on.
+type Exporter struct {
+	protocol string
+	resource []attribute
+	headers  map[string]string
+
+	// for OTLP/gRPC
+	conn *grpc.ClientConn
+
+	// for OTLP/HTTP
+	client *http.Client
+	url    string
+}
+
+// Provision sets e's configuration.
+func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider) error {
+	if provider.GetBackend() != exporterName {
+		return fmt.Errorf(
+			"invalid exporter name: expected '%s'; got '%s'",
+			exporterName,
+			provider.GetBackend(),
+		)
+	}
+
+	if provider.GetHost() == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+
+	conf := provider.GetOTLP()
+
+	e.protocol = conf.GetProtocol()
+	if e.protocol == "" {
+		e.protocol = protocolGRPC
+	}
+
+	port := provider.GetPort()
+
+	switch e.protocol {
+	case protocolGRPC:
+		if port == 0 {
+			port = defaultGRPCPort
+		}
+	case protocolHTTP:
+		if port == 0 {
+			port = defaultHTTPPort
+		}
+	default:
+		return fmt.Errorf("invalid protocol: expected '%s' or '%s'; got '%s'",
+			protocolGRPC, protocolHTTP, e.protocol)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("cannot get hostname: %w", err)
+	}
+
+	serviceName := conf.GetServiceName()
+	if serviceName == "" {
+		serviceName = "pinger-agent"
+	}
+
+	e.resource = []attribute{
+		{key: attrServiceName, value: serviceName},
+		{key: attrServiceInstanceID, value: hostname},
+		{key: attrHostName, value: hostname},
+	}
+
+	e.headers = map[string]string{}
+	switch {
+	case provider.GetUsername() != "":
+		auth := provider.GetUsername() + ":" + provider.GetPassword()
+		e.headers["authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+	case provider.GetPassword() != "":
+		e.headers["authorization"] = "Bearer " + provider.GetPassword() // token authentication
+	}
+
+	addr := net.JoinHostPort(provider.GetHost(), strconv.Itoa(int(port)))
+
+	if e.protocol == protocolHTTP {
+		scheme := "http"
+		if provider.IsSSLMode() {
+			scheme = "https"
+		}
+
+		path := conf.GetPath()
+		if path == "" {
+			path = defaultWritePath
+		}
+
+		e.client = &http.Client{Timeout: requestTimeout}
+		e.url = (&url.URL{Scheme: scheme, Host: addr, Path: path}).String()
+		return nil
+	}
+
+	creds := grpc.WithInsecure()
+	if provider.IsSSLMode() {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}))
+	}
+
+	// the connection is established lazily, so the collector does not need
+	// to be up when the agent starts.
+	e.conn, err = grpc.Dial(addr, creds)
+	if err != nil {
+		return fmt.Errorf("cannot connect to collector: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		e.conn.Close() // nolint:errcheck,gosec
+	}()
+
+	return nil
+}
+
+// Export sends the metrics to the collector.
+func (e *Exporter) Export(ctx context.Context, metrics []checker.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	gauges := []gauge{
+		{name: "pinger.check.up", description: "Whether the check was successful.", unit: "1"},
+		{name: "pinger.check.degraded", description: "Whether the check took longer than the latency threshold.", unit: "1"},
+		{name: "pinger.check.timeout", description: "Whether the check timed-out.", unit: "1"},
+		{name: "pinger.check.duration", description: "Time taken by the check.", unit: "s"},
+	}
+
+	for _, m := range metrics {
+		attributes := []attribute{
+			{key: attrCheckID, value: m.GetCheckID()},
+			{key: attrCheckName, value: m.GetCheckName()},
+		}
+		if m.GetLocation() != "" {
+			attributes = append(attributes, attribute{key: attrCheckLocation, value: m.GetLocation()})
+		}
+
+		timestamp := uint64(m.GetStartTime().UnixNano())
+		values := []float64{
+			boolValue(m.IsSuccessful()),
+			boolValue(m.IsDegraded()),
+			boolValue(m.IsTimeout()),
+			m.GetDuration().Seconds(),
+		}
+
+		for i := range gauges {
+			attrs := attributes
+			if i == 0 && m.GetFailureCode() != "" {
+				attrs = append(attrs[:len(attrs):len(attrs)], attribute{key: attrFailureCode, value: m.GetFailureCode()})
+			}
+
+			gauges[i].points = append(gauges[i].points, dataPoint{
+				attributes: attrs,
+				timestamp:  timestamp,
+				value:      values[i],
+			})
+		}
+	}
+
+	req := encodeRequest(e.resource, scopeName, "", gauges)
+
+	if e.protocol == protocolHTTP {
+		return e.exportHTTP(ctx, req)
+	}
+
+	return e.exportGRPC(ctx, req)
+}
+
+// exportHTTP sends the encoded request over OTLP/HTTP.
+func (e *Exporter) exportHTTP(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot export metrics: %w", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		err = fmt.Errorf("cannot export metrics: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+
+		// the collector rejected the metrics themselves, so sending them
+		// again cannot succeed, unless it is only rate limiting the exports.
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return exporter.Permanent(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// exportGRPC sends the encoded request over OTLP/gRPC.
+func (e *Exporter) exportGRPC(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.headers))
+	}
+
+	var resp []byte
+	err := e.conn.Invoke(ctx, exportMethod, &body, &resp, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return fmt.Errorf("cannot export metrics: %w", err)
+	}
+
+	return nil
+}
+
+// GetMetrics returns error as metrics cannot be read from a collector.
+func (e *Exporter) GetMetrics(
+	context.Context,
+	time.Duration,
+	...string,
+) (map[string][]checker.Metric, error) {
+	return nil, fmt.Errorf(
+		"the method is not implemented for exporter: %s, metrics can only be written",
+		exporterName,
+	)
+}
+
+// GetAggregates returns error as metrics cannot be read from a collector.
+func (e *Exporter) GetAggregates(
+	context.Context,
+	time.Duration,
+	int,
+	...string,
+) (map[string][]exporter.Aggregate, error) {
+	return nil, fmt.Errorf(
+		"the method is not implemented for exporter: %s, metrics can only be written",
+		exporterName,
+	)
+}
+
+// rawCodec passes the already encoded messages through gRPC as-is.
+type rawCodec struct{}
+
+// Marshal returns the encoded message.
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal %T", v)
+	}
+
+	return *b, nil
+}
+
+// Unmarshal stores the encoded message.
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal into %T", v)
+	}
+
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Name returns the name of the codec.
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// Interface guard.
+var _ exporter.Exporter = (*Exporter)(nil)
@@ -0,0 +1,7 @@
+// Package prometheus implements the prometheus exporter.
+//
+// Unlike the other exporters, it does not push the metrics into a database.
+// The latest results of the checks are kept in memory and served on the
+// `/metrics` endpoint in the Prometheus text exposition format so that the
+// agent can be scraped by Prometheus directly.
+package prometheus
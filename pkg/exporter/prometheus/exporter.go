@@ -0,0 +1,325 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+const exporterName = "prometheus"
+
+const (
+	// defaultPort is the port the metrics are served on if none is
+	// provided.
+	defaultPort uint16 = 9011
+
+	// metricsPath is the path of the endpoint that serves the metrics.
+	metricsPath = "/metrics"
+
+	// contentType is the content type of the text exposition format.
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the buckets of the
+// duration histogram.
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func init() {
+	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
+}
+
+// Exporter keeps the latest results of the checks in memory and serves them
+// for Prometheus to scrape.
+type Exporter struct {
+	mu     sync.RWMutex
+	checks map[string]*series
+
+	// forgotten is the time each of the removed checks was forgotten at, so
+	// that its metrics still being exported do not bring its series back.
+	forgotten map[string]time.Time
+}
+
+// series is everything that is tracked for a single check.
+type series struct {
+	latest *config.Metric
+
+	runs     uint64
+	failures uint64
+	timeouts uint64
+
+	buckets []uint64 // number of runs in each of the duration buckets
+	sum     float64  // sum of durations in seconds
+}
+
+// Provision sets e's configuration and starts serving the metrics.
+func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider) error {
+	if provider.GetBackend() != exporterName {
+		return fmt.Errorf(
+			"invalid exporter name: expected '%s'; got '%s'",
+			exporterName,
+			provider.GetBackend(),
+		)
+	}
+
+	e.checks = map[string]*series{}
+	e.forgotten = map[string]time.Time{}
+
+	port := provider.GetPort()
+	if port == 0 {
+		port = defaultPort
+	}
+
+	// the port is bound here so that the exporter fails to provision if it
+	// is taken, rather than the metrics silently not being served.
+	lst, err := httpserver.Listen(port)
+	if err != nil {
+		return fmt.Errorf("cannot listen on port %d: %w", port, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, e.serveMetrics)
+
+	go func() {
+		ctx.Logger().
+			WithField("address", fmt.Sprintf(":%d%s", port, metricsPath)).
+			Infof("serving prometheus metrics")
+		if err := httpserver.Serve(ctx, lst, mux); err != nil {
+			ctx.Logger().WithError(err).Errorln("prometheus server exited unexpectedly")
+		}
+	}()
+
+	return nil
+}
+
+// Export records the metrics to be served on the next scrape.
+func (e *Exporter) Export(_ context.Context, metrics []checker.Metric) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, m := range metrics {
+		if at, ok := e.forgotten[m.GetCheckID()]; ok {
+			if m.GetStartTime().Before(at) {
+				continue
+			}
+
+			// the check has been added again.
+			delete(e.forgotten, m.GetCheckID())
+		}
+
+		s, ok := e.checks[m.GetCheckID()]
+		if !ok {
+			s = &series{buckets: make([]uint64, len(durationBuckets))}
+			e.checks[m.GetCheckID()] = s
+		}
+
+		s.runs++
+		if !m.IsSuccessful() {
+			s.failures++
+		}
+		if m.IsTimeout() {
+			s.timeouts++
+		}
+
+		seconds := m.GetDuration().Seconds()
+		s.sum += seconds
+		for i, bound := range durationBuckets {
+			if seconds <= bound {
+				s.buckets[i]++
+				break
+			}
+		}
+
+		if s.latest == nil || !m.GetStartTime().Before(s.latest.StartTime) {
+			s.latest = config.NewMetric(m)
+		}
+	}
+
+	return nil
+}
+
+// Forget drops the series of the check so that it is not served once the
+// check is removed.
+func (e *Exporter) Forget(checkID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.checks, checkID)
+	e.forgotten[checkID] = time.Now()
+}
+
+// GetMetrics returns the latest metric of each of the checks if it started
+// within the duration. The older metrics are not kept by the exporter.
+func (e *Exporter) GetMetrics(
+	_ context.Context,
+	duration time.Duration,
+	checkIDs ...string,
+) (map[string][]checker.Metric, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	after := time.Now().Add(-duration)
+	metrics := make(map[string][]checker.Metric, len(checkIDs))
+	for _, id := range checkIDs {
+		s, ok := e.checks[id]
+		if !ok || s.latest.StartTime.Before(after) {
+			continue
+		}
+
+		metrics[id] = []checker.Metric{s.latest}
+	}
+
+	return metrics, nil
+}
+
+// GetAggregates aggregates the latest metric of each of the given checks,
+// since the older metrics are not kept by the exporter.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	start, width := exporter.BucketStart(duration, buckets)
+	metrics, err := e.GetMetrics(ctx, time.Since(start), checkIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.AggregateMetrics(metrics, start, width), nil
+}
+
+// serveMetrics writes the metrics in the Prometheus text exposition format.
+func (e *Exporter) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	e.mu.RLock()
+	checks := make([]labelled, 0, len(e.checks))
+	for id, s := range e.checks {
+		labels := fmt.Sprintf(`check_id="%s",check_name="%s"`,
+			escapeLabel(id), escapeLabel(s.latest.CheckName))
+		if s.latest.Location != "" {
+			labels += fmt.Sprintf(`,location="%s"`, escapeLabel(s.latest.Location))
+		}
+
+		checks = append(checks, labelled{
+			series: s,
+			labels: labels,
+		})
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].labels < checks[j].labels })
+
+	var buf bytes.Buffer
+
+	writeFamily(&buf, "pinger_check_up", "gauge",
+		"Whether the latest run of the check was successful.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_up", c.labels, boolValue(c.latest.Successful))
+	}
+
+	writeFamily(&buf, "pinger_check_degraded", "gauge",
+		"Whether the latest run of the check took longer than the latency threshold.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_degraded", c.labels, boolValue(c.latest.Degraded))
+	}
+
+	writeFamily(&buf, "pinger_check_last_run_timestamp_seconds", "gauge",
+		"Time when the latest run of the check started.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_last_run_timestamp_seconds", c.labels,
+			float64(c.latest.StartTime.UnixNano())/float64(time.Second))
+	}
+
+	writeFamily(&buf, "pinger_check_duration_seconds", "histogram",
+		"Time taken by the runs of the check.")
+	for _, c := range checks {
+		var cumulative uint64
+		for i, bound := range durationBuckets {
+			cumulative += c.buckets[i]
+			writeSample(&buf, "pinger_check_duration_seconds_bucket",
+				c.labels+`,le="`+formatFloat(bound)+`"`, float64(cumulative))
+		}
+		writeSample(&buf, "pinger_check_duration_seconds_bucket", c.labels+`,le="+Inf"`, float64(c.runs))
+		writeSample(&buf, "pinger_check_duration_seconds_sum", c.labels, c.sum)
+		writeSample(&buf, "pinger_check_duration_seconds_count", c.labels, float64(c.runs))
+	}
+
+	writeFamily(&buf, "pinger_check_runs_total", "counter",
+		"Number of runs of the check.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_runs_total", c.labels, float64(c.runs))
+	}
+
+	writeFamily(&buf, "pinger_check_failures_total", "counter",
+		"Number of failed runs of the check.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_failures_total", c.labels, float64(c.failures))
+	}
+
+	writeFamily(&buf, "pinger_check_timeouts_total", "counter",
+		"Number of runs of the check that timed out.")
+	for _, c := range checks {
+		writeSample(&buf, "pinger_check_timeouts_total", c.labels, float64(c.timeouts))
+	}
+	e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// labelled is the series of a check along with its labels.
+type labelled struct {
+	*series
+	labels string
+}
+
+// writeFamily writes the metadata of a metric family.
+func writeFamily(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, typ)
+}
+
+// writeSample writes a single sample of the metric.
+func writeSample(buf *bytes.Buffer, name, labels string, value float64) {
+	fmt.Fprintf(buf, "%s{%s} %s\n", name, labels, formatFloat(value))
+}
+
+// formatFloat formats the value as expected by Prometheus.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// labelEscaper escapes the characters that are not allowed in the label
+// values as-is.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes the label value.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// Interface guards.
+var (
+	_ exporter.Exporter  = (*Exporter)(nil)
+	_ exporter.Forgetter = (*Exporter)(nil)
+)
@@ -1,5 +1,7 @@
 package exporter
 
+import "time"
+
 // Provider is anything that can be used to configure and create a metrics
 // exporter.
 type Provider interface {
@@ -12,4 +14,41 @@ type Provider interface {
 	GetUsername() string // Returns the username.
 	GetPassword() string // Returns the password.
 	IsSSLMode() bool     // Tells if connection is through SSL mode.
+
+	GetRetention() RetentionConfig     // Returns the retention for backends that clean up metrics.
+	GetRemoteWrite() RemoteWriteConfig // Returns the config for the remotewrite backend.
+	GetOTLP() OTLPConfig               // Returns the config for the otlp backend.
+	GetFile() FileConfig               // Returns the config for the file backend.
+}
+
+// RetentionConfig is the configuration of how long the metrics are retained
+// in the backends that clean up the old metrics.
+type RetentionConfig interface {
+	GetDuration() time.Duration       // Returns the duration to retain metrics for.
+	HasRollups() bool                 // Tells if metrics are to be rolled up.
+	GetRollupDuration() time.Duration // Returns the duration to retain rollups for.
+}
+
+// RemoteWriteConfig is the configuration of the remotewrite backend.
+type RemoteWriteConfig interface {
+	GetWritePath() string // Returns the path of the endpoint to write into.
+	GetQueryPath() string // Returns the path of the endpoint to query from.
+}
+
+// OTLPConfig is the configuration of the otlp backend.
+type OTLPConfig interface {
+	GetProtocol() string    // Returns the protocol to connect with.
+	GetPath() string        // Returns the path of the endpoint to write into over HTTP.
+	GetServiceName() string // Returns the name of the service sending the metrics.
+}
+
+// FileConfig is the configuration of the file backend.
+type FileConfig interface {
+	GetPath() string                  // Returns the path of the file to write into.
+	GetFormat() string                // Returns the format to write metrics in.
+	GetMaxSize() int64                // Returns the size in bytes after which file is rotated.
+	GetRotateInterval() time.Duration // Returns the interval after which file is rotated.
+	IsCompress() bool                 // Tells if rotated files are to be compressed.
+	GetMaxFiles() int                 // Returns the number of rotated files to keep.
+	GetMaxAge() time.Duration         // Returns the duration to keep rotated files for.
 }
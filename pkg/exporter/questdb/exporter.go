@@ -2,8 +2,10 @@ package questdb
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -16,20 +18,57 @@ import (
 
 const exporterName = "questdb"
 
+// maintenanceInterval is the interval after which the metrics are rolled up
+// and the partitions older than the retention are dropped.
+const maintenanceInterval = time.Hour
+
+// rollupTable is the table of the rollups of an interval.
+type rollupTable struct {
+	name      string // name of the table
+	sampleBy  string // unit of SAMPLE BY for the interval
+	partition string // partitioning of the table
+}
+
+// rollupTables are the tables of the rollups for each interval.
+var rollupTables = map[time.Duration]rollupTable{
+	exporter.RollupHourly: {name: "metrics_hourly", sampleBy: "1h", partition: "DAY"},
+	exporter.RollupDaily:  {name: "metrics_daily", sampleBy: "1d", partition: "MONTH"},
+}
+
 // Exporter for exporting metrics to quest db.
+//
+// QuestDB can only drop whole partitions, so the metrics are partitioned by
+// day and the partitions older than the retention are dropped. When rollups
+// are enabled, the metrics are also aggregated into hourly and daily tables
+// which are read for long durations.
 type Exporter struct {
 	conn *pgxpool.Pool
+
+	retention       time.Duration
+	rollups         bool
+	rollupRetention time.Duration
+
+	// unpartitioned are the tables that were created without partitions,
+	// so the retention cannot be applied to them.
+	unpartitioned map[string]bool
 }
 
 // Metric model.
 type Metric struct {
 	CheckID   string
 	CheckName string
+	Location  string
 
 	StartTime time.Time
 	Duration  time.Duration
 	Timeout   bool
 	Success   bool
+	Degraded  bool
+
+	FailureCode    string
+	FailureMessage string
+
+	Measurements map[string]interface{}
 }
 
 func init() {
@@ -46,6 +85,11 @@ func (m Metric) GetCheckName() string {
 	return m.CheckName
 }
 
+// GetLocation returns the location of the agent the check ran from.
+func (m Metric) GetLocation() string {
+	return m.Location
+}
+
 // GetStartTime returns the start time.
 func (m Metric) GetStartTime() time.Time {
 	return m.StartTime
@@ -61,11 +105,31 @@ func (m Metric) IsTimeout() bool {
 	return m.Timeout
 }
 
+// IsDegraded tells if the check took longer than the latency threshold.
+func (m Metric) IsDegraded() bool {
+	return m.Degraded
+}
+
 // IsSuccessful tells if the check was successful.
 func (m Metric) IsSuccessful() bool {
 	return m.Success
 }
 
+// GetFailureCode returns the reason of failure.
+func (m Metric) GetFailureCode() string {
+	return m.FailureCode
+}
+
+// GetFailureMessage returns the detailed message of failure.
+func (m Metric) GetFailureMessage() string {
+	return m.FailureMessage
+}
+
+// GetMeasurements returns the measurements recorded during the check.
+func (m Metric) GetMeasurements() map[string]interface{} {
+	return m.Measurements
+}
+
 // newConn creates a new connection with the database.
 func newConn(ctx *appcontext.Context, provider exporter.Provider) (*pgxpool.Pool, error) {
 	connStr := fmt.Sprintf(
@@ -92,14 +156,234 @@ func newConn(ctx *appcontext.Context, provider exporter.Provider) (*pgxpool.Pool
 		return nil, err
 	}
 
-	_, err1 := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS metrics(check_id string, check_name string, start_time timestamp, duration long,timeout string, success string) timestamp(start_time);")
+	_, err1 := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS metrics(check_id string, check_name string, start_time timestamp, duration long,timeout string, success string, degraded string, failure_code string, failure_message string, measurements string, location symbol) timestamp(start_time) PARTITION BY DAY;")
 	if err1 != nil {
 		return nil, err1
 	}
 
+	// tables created before degradation, failure reasons, measurements and
+	// locations were recorded need the columns to be added.
+	for _, col := range []string{"degraded", "failure_code", "failure_message", "measurements", "location"} {
+		var exists bool
+		err := db.QueryRow(
+			ctx,
+			"SELECT count() > 0 FROM table_columns('metrics') WHERE column = $1;",
+			col,
+		).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			continue
+		}
+
+		typ := "string"
+		if col == "location" {
+			typ = "symbol"
+		}
+
+		if _, err := db.Exec(ctx, fmt.Sprintf("ALTER TABLE metrics ADD COLUMN %s %s;", col, typ)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !provider.GetRetention().HasRollups() {
+		return db, nil
+	}
+
+	for _, table := range rollupTables {
+		_, err := db.Exec(ctx, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s(check_id string, check_name string, start_time timestamp, runs long, successes long, timeouts long, degradations long, mean_duration long, max_duration long) timestamp(start_time) PARTITION BY %s;",
+			table.name, table.partition,
+		))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
+// maintain rolls up the metrics and drops the partitions older than the
+// retention periodically till the context is canceled.
+func (e *Exporter) maintain(ctx *appcontext.Context) {
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		if e.rollups {
+			for every, table := range rollupTables {
+				if err := e.rollUp(ctx, every, table.name, table.sampleBy); err != nil {
+					ctx.Logger().WithError(err).WithField("table", table.name).Errorln("cannot roll up metrics")
+				}
+
+				if e.rollupRetention == 0 || e.unpartitioned[table.name] {
+					continue
+				}
+
+				if err := e.dropPartitions(ctx, table.name, e.rollupRetention); err != nil {
+					ctx.Logger().WithError(err).WithField("table", table.name).Errorln("cannot drop old rollups")
+				}
+			}
+		}
+
+		// the metrics are dropped only after they are rolled up.
+		if e.retention != 0 && !e.unpartitioned["metrics"] {
+			if err := e.dropPartitions(ctx, "metrics", e.retention); err != nil {
+				ctx.Logger().WithError(err).Errorln("cannot drop old metrics")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollUp aggregates the metrics of the intervals that ended since the last
+// rolled up interval into the rollup table.
+func (e *Exporter) rollUp(ctx context.Context, every time.Duration, table, sampleBy string) error {
+	from, err := e.rolledUntil(ctx, table, every)
+	if err != nil {
+		return err
+	}
+
+	if from.IsZero() {
+		// nothing is rolled up yet, so start from the oldest metric.
+		var oldest *time.Time
+		if err := e.conn.QueryRow(ctx, "SELECT min(start_time) FROM metrics;").Scan(&oldest); err != nil {
+			return err
+		}
+
+		if oldest == nil {
+			return nil
+		}
+
+		from = oldest.UTC().Truncate(every)
+	}
+
+	to := time.Now().UTC().Truncate(every)
+	if !from.Before(to) {
+		return nil
+	}
+
+	_, err = e.conn.Exec(ctx, fmt.Sprintf(`INSERT INTO %s
+SELECT check_id, check_name, start_time, count() runs,
+	sum(CASE WHEN success = 'true' THEN 1 ELSE 0 END) successes,
+	sum(CASE WHEN timeout = 'true' THEN 1 ELSE 0 END) timeouts,
+	sum(CASE WHEN degraded = 'true' THEN 1 ELSE 0 END) degradations,
+	cast(avg(duration) AS long) mean_duration,
+	max(duration) max_duration
+FROM metrics
+WHERE start_time >= '%s' AND start_time < '%s'
+SAMPLE BY %s ALIGN TO CALENDAR;`,
+		table, from.Format(time.RFC3339), to.Format(time.RFC3339), sampleBy,
+	))
+
+	return err
+}
+
+// rolledUntil returns the end of the last interval that is rolled up into
+// the table, or zero time if none is.
+func (e *Exporter) rolledUntil(ctx context.Context, table string, every time.Duration) (time.Time, error) {
+	var last *time.Time
+	if err := e.conn.QueryRow(ctx, fmt.Sprintf("SELECT max(start_time) FROM %s;", table)).Scan(&last); err != nil {
+		return time.Time{}, err
+	}
+
+	if last == nil {
+		return time.Time{}, nil
+	}
+
+	return last.UTC().Add(every), nil
+}
+
+// partitionBy returns the partitioning of the table. The columns of `tables()`
+// are looked up by their name since they have been renamed across versions.
+func (e *Exporter) partitionBy(ctx context.Context, table string) (string, error) {
+	rows, err := e.conn.Query(ctx, "SELECT * FROM tables();")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	nameCol, partitionCol := -1, -1
+	for i, f := range rows.FieldDescriptions() {
+		switch string(f.Name) {
+		case "name", "table_name":
+			nameCol = i
+		case "partitionBy":
+			partitionCol = i
+		}
+	}
+
+	if nameCol < 0 || partitionCol < 0 {
+		return "", fmt.Errorf("cannot find the partitioning of tables")
+	}
+
+	partition := ""
+	for rows.Next() {
+		values, er := rows.Values()
+		if er != nil {
+			return "", er
+		}
+
+		if name, ok := values[nameCol].(string); ok && name == table {
+			if partition, ok = values[partitionCol].(string); !ok {
+				return "", fmt.Errorf("unexpected partitioning of table %s: %v", table, values[partitionCol])
+			}
+		}
+	}
+
+	return partition, rows.Err()
+}
+
+// checkPartitions finds the tables with a retention that are not partitioned,
+// which happens for the tables created by older versions of the agent. The
+// retention is skipped for them since only partitions can be dropped.
+func (e *Exporter) checkPartitions(ctx *appcontext.Context) error {
+	retentions := map[string]time.Duration{"metrics": e.retention}
+	if e.rollups {
+		for _, table := range rollupTables {
+			retentions[table.name] = e.rollupRetention
+		}
+	}
+
+	e.unpartitioned = map[string]bool{}
+	for table, retention := range retentions {
+		if retention == 0 {
+			continue
+		}
+
+		partition, err := e.partitionBy(ctx, table)
+		if err != nil {
+			return fmt.Errorf("cannot check partitions of table %s: %w", table, err)
+		}
+
+		if partition != "" && partition != "NONE" {
+			continue
+		}
+
+		e.unpartitioned[table] = true
+		ctx.Logger().
+			WithField("table", table).
+			Warnln("table is not partitioned, so the retention is not applied; recreate the table to apply it")
+	}
+
+	return nil
+}
+
+// dropPartitions drops the partitions of the table that only have rows
+// older than the retention.
+func (e *Exporter) dropPartitions(ctx context.Context, table string, retention time.Duration) error {
+	before := time.Now().Add(-1 * retention).UTC().Format(time.RFC3339)
+	_, err := e.conn.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DROP PARTITION WHERE start_time < '%s';", table, before))
+	return err
+}
+
 // Provision sets e's configuration.
 func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider) error {
 	if provider.GetBackend() != exporterName {
@@ -110,12 +394,28 @@ func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider
 		)
 	}
 
+	retention := provider.GetRetention()
+	e.retention = retention.GetDuration()
+	e.rollups = retention.HasRollups()
+	e.rollupRetention = retention.GetRollupDuration()
+	if e.retention < 0 || e.rollupRetention < 0 {
+		return fmt.Errorf("retention should be >= 0")
+	}
+
 	cli, err := newConn(ctx, provider)
 	if err != nil {
 		return err
 	}
 	e.conn = cli
 
+	if err := e.checkPartitions(ctx); err != nil {
+		return err
+	}
+
+	if e.retention != 0 || e.rollups {
+		go e.maintain(ctx)
+	}
+
 	return nil
 }
 
@@ -128,9 +428,76 @@ func (e *Exporter) GetMetrics(
 		return nil, nil
 	}
 
+	if every := exporter.RollupInterval(time); e.rollups && every != 0 {
+		return e.getRollupsByChecksAndDuration(ctx, checkIDs, time, every)
+	}
+
 	return e.getMetricsByChecksAndDuration(ctx, checkIDs, time)
 }
 
+// getRollupsByChecksAndDuration fetches the rollups of the given interval
+// that start in the past `duration`. The metrics after the last rolled up
+// interval are fetched raw.
+func (e *Exporter) getRollupsByChecksAndDuration(
+	ctx context.Context,
+	checkIDs []string,
+	duration time.Duration,
+	every time.Duration,
+) (map[string][]checker.Metric, error) {
+	table := rollupTables[every]
+	now := time.Now()
+
+	boundary, err := e.rolledUntil(ctx, table.name, every)
+	if err != nil {
+		return nil, err
+	}
+
+	if boundary.IsZero() || boundary.Before(now.Add(-1*duration)) {
+		return e.getMetricsByChecksAndDuration(ctx, checkIDs, duration)
+	}
+
+	metrics, err := e.getMetricsByChecksAndDuration(ctx, checkIDs, now.Sub(boundary))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.conn.Query(ctx, fmt.Sprintf(
+		`SELECT check_id, check_name, start_time, runs, successes, timeouts, degradations, mean_duration, max_duration FROM %s WHERE check_id IN (%s) AND start_time >= '%s' AND start_time < '%s';`,
+		table.name,
+		quoteIDs(checkIDs),
+		now.Add(-1*duration).UTC().Format(time.RFC3339),
+		boundary.Format(time.RFC3339),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*exporter.Rollup
+	for rows.Next() {
+		r := &exporter.Rollup{Interval: every}
+
+		var meanDuration, maxDuration int64
+		err := rows.Scan(
+			&r.CheckID, &r.CheckName, &r.StartTime, &r.Runs, &r.Successes, &r.Timeouts, &r.Degradations,
+			&meanDuration, &maxDuration,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		r.MeanDuration = time.Duration(meanDuration)
+		r.MaxDuration = time.Duration(maxDuration)
+		rollups = append(rollups, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter.MergeRollups(metrics, rollups), nil
+}
+
 func (e *Exporter) getMetricsByChecksAndDuration(
 	ctx context.Context,
 	checkIDs []string,
@@ -139,7 +506,7 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 	startTime := time.Now().Add(-1 * duration).UTC().Format(time.RFC3339)
 	metrics := map[string][]checker.Metric{}
 
-	querystring := fmt.Sprintf(` SELECT * FROM metrics WHERE
+	querystring := fmt.Sprintf(` SELECT check_id, check_name, start_time, duration, timeout, success, degraded, failure_code, failure_message, measurements, location FROM metrics WHERE
 	 ( check_id= '%s' `,
 		checkIDs[0],
 	)
@@ -162,8 +529,16 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 		var Duration time.Duration
 		var Timeout string
 		var Success string
-
-		err = fetched.Scan(&CheckID, &CheckName, &StartTime, &Duration, &Timeout, &Success)
+		var Degraded *string
+		var FailureCode *string
+		var FailureMessage *string
+		var Measurements *string
+		var Location *string
+
+		err = fetched.Scan(
+			&CheckID, &CheckName, &StartTime, &Duration, &Timeout, &Success, &Degraded,
+			&FailureCode, &FailureMessage, &Measurements, &Location,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -178,7 +553,27 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 			return nil, err
 		}
 
-		m := Metric{CheckID, CheckName, StartTime, Duration, timeout1, success1}
+		m := Metric{CheckID, CheckName, "", StartTime, Duration, timeout1, success1, false, "", "", nil}
+		if Location != nil {
+			m.Location = *Location
+		}
+		if Degraded != nil && *Degraded != "" {
+			m.Degraded, err = strconv.ParseBool(*Degraded)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if FailureCode != nil {
+			m.FailureCode = *FailureCode
+		}
+		if FailureMessage != nil {
+			m.FailureMessage = *FailureMessage
+		}
+		if Measurements != nil && *Measurements != "" {
+			if err := json.Unmarshal([]byte(*Measurements), &m.Measurements); err != nil {
+				return nil, err
+			}
+		}
 
 		if _, ok := metrics[m.CheckID]; !ok {
 			metrics[m.CheckID] = []checker.Metric{}
@@ -191,6 +586,92 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 	return metrics, nil
 }
 
+// GetRetention returns how long the metrics are retained for. The rollups are
+// not aggregated, so it is the retention of the raw metrics, which is not
+// applied if the table is not partitioned.
+func (e *Exporter) GetRetention() time.Duration {
+	if e.unpartitioned["metrics"] {
+		return 0
+	}
+
+	return e.retention
+}
+
+// GetAggregates aggregates the metrics of each location of the given checks
+// in the database. Timestamps in QuestDB are in microseconds, so the buckets are computed in
+// microseconds as well.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	start, width := exporter.BucketStart(duration, buckets)
+
+	rows, err := e.conn.Query(ctx, fmt.Sprintf(`SELECT
+	check_id,
+	coalesce(location, '') location,
+	(cast(start_time AS long) - %d) / %d bucket,
+	count() runs,
+	sum(CASE WHEN success = 'true' THEN 0 ELSE 1 END) failures,
+	sum(CASE WHEN timeout = 'true' THEN 1 ELSE 0 END) timeouts,
+	sum(CASE WHEN degraded = 'true' THEN 1 ELSE 0 END) degradations,
+	approx_percentile(cast(duration AS double), 0.50) p50,
+	approx_percentile(cast(duration AS double), 0.95) p95,
+	approx_percentile(cast(duration AS double), 0.99) p99
+FROM metrics
+WHERE check_id IN (%s) AND start_time >= '%s'
+ORDER BY bucket DESC;`,
+		start.UnixNano()/int64(time.Microsecond),
+		width.Microseconds(),
+		quoteIDs(checkIDs),
+		start.UTC().Format(time.RFC3339),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := map[string][]exporter.Aggregate{}
+	for rows.Next() {
+		var (
+			checkID       string
+			location      string
+			bucket        int64
+			a             exporter.Aggregate
+			p50, p95, p99 float64
+		)
+
+		err := rows.Scan(&checkID, &location, &bucket, &a.Runs, &a.Failures, &a.Timeouts, &a.Degradations, &p50, &p95, &p99)
+		if err != nil {
+			return nil, err
+		}
+
+		a.StartTime = start.Add(time.Duration(bucket) * width)
+		a.Location = location
+		a.P50 = time.Duration(p50)
+		a.P95 = time.Duration(p95)
+		a.P99 = time.Duration(p99)
+		aggregates[checkID] = append(aggregates[checkID], a)
+	}
+
+	return aggregates, rows.Err()
+}
+
+// quoteIDs quotes the check IDs to be used in an IN clause.
+func quoteIDs(checkIDs []string) string {
+	quoted := make([]string, len(checkIDs))
+	for i := range checkIDs {
+		quoted[i] = "'" + strings.ReplaceAll(checkIDs[i], "'", "''") + "'"
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
 // Export exports the metrics to the exporter.
 func (e *Exporter) Export(ctx context.Context, metrics []checker.Metric) error {
 	return e.createMetrics(ctx, metrics)
@@ -205,14 +686,28 @@ func (e *Exporter) createMetrics(ctx context.Context, metrics []checker.Metric)
 	batch := &pgx.Batch{}
 
 	for i := range metrics {
+		var measurements string
+		if m := metrics[i].GetMeasurements(); m != nil {
+			b, err := json.Marshal(m)
+			if err != nil {
+				return err
+			}
+
+			measurements = string(b)
+		}
 
-		batch.Queue("insert into metrics(check_id,check_name, start_time,duration,timeout,success) values($1, $2, $3, $4, $5,$6)",
+		batch.Queue("insert into metrics(check_id,check_name, start_time,duration,timeout,success,degraded,failure_code,failure_message,measurements,location) values($1, $2, $3, $4, $5,$6,$7,$8,$9,$10,$11)",
 			metrics[i].GetCheckID(),
 			metrics[i].GetCheckName(),
 			metrics[i].GetStartTime(),
 			metrics[i].GetDuration(),
 			strconv.FormatBool(metrics[i].IsTimeout()),
 			strconv.FormatBool(metrics[i].IsSuccessful()),
+			strconv.FormatBool(metrics[i].IsDegraded()),
+			metrics[i].GetFailureCode(),
+			metrics[i].GetFailureMessage(),
+			measurements,
+			metrics[i].GetLocation(),
 		)
 	}
 
@@ -222,3 +717,9 @@ func (e *Exporter) createMetrics(ctx context.Context, metrics []checker.Metric)
 
 	return err
 }
+
+// Interface guards.
+var (
+	_ exporter.Exporter = (*Exporter)(nil)
+	_ exporter.Retainer = (*Exporter)(nil)
+)
@@ -0,0 +1,7 @@
+// Package remotewrite implements the prometheus remote-write exporter.
+//
+// Metrics are pushed using the remote-write protocol into any compatible
+// endpoint, for example, Prometheus, Mimir, VictoriaMetrics or Thanos
+// receive. The metrics are read back for the status page using the PromQL
+// HTTP query API of the same.
+package remotewrite
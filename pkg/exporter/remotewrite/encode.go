@@ -0,0 +1,111 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// label is a name-value pair that identifies a time series.
+type label struct {
+	name, value string
+}
+
+// sample is a single value of a time series at the given timestamp in
+// milliseconds.
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+// timeSeries is the series of samples for the set of labels.
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+// encodeWriteRequest encodes the time series as the `WriteRequest` message
+// of the remote-write protocol:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+//
+// The protocol requires labels sorted by name and samples sorted by time,
+// so both are sorted before encoding.
+func encodeWriteRequest(series []timeSeries) []byte {
+	var req []byte
+	for i := range series {
+		ts := &series[i]
+		sort.Slice(ts.labels, func(a, b int) bool { return ts.labels[a].name < ts.labels[b].name })
+		sort.Slice(ts.samples, func(a, b int) bool { return ts.samples[a].timestamp < ts.samples[b].timestamp })
+
+		var msg []byte
+		for _, l := range ts.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+
+			msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+			msg = protowire.AppendBytes(msg, lb)
+		}
+
+		for _, s := range ts.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(s.timestamp))
+
+			msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+			msg = protowire.AppendBytes(msg, sb)
+		}
+
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, msg)
+	}
+
+	return req
+}
+
+// maxLiteral is the maximum length of a literal in a snappy block that is
+// encoded with a 2 byte length.
+const maxLiteral = 1 << 16
+
+// encodeSnappy encodes the data in the snappy block format which the
+// remote-write protocol requires.
+//
+// The data is encoded only using literals, i.e., without any compression.
+// This is a valid snappy block that every decoder accepts and it keeps the
+// exporter free of a compression dependency. Since a batch of metrics is
+// only a few kilobytes, the size does not matter much.
+func encodeSnappy(data []byte) []byte {
+	dst := make([]byte, 0, binary.MaxVarintLen64+len(data)+3*(len(data)/maxLiteral+1))
+	dst = appendUvarint(dst, uint64(len(data)))
+
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxLiteral {
+			n = maxLiteral
+		}
+
+		// tag 61 << 2 says that the length - 1 follows in 2 bytes.
+		dst = append(dst, 61<<2, byte(n-1), byte((n-1)>>8))
+		dst = append(dst, data[:n]...)
+		data = data[n:]
+	}
+
+	return dst
+}
+
+// appendUvarint appends the unsigned varint encoding of v.
+func appendUvarint(dst []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(dst, buf[:n]...)
+}
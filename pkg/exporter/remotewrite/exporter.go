@@ -0,0 +1,381 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const exporterName = "remotewrite"
+
+const (
+	// defaultWritePath is the path of the remote-write endpoint of
+	// Prometheus, used when no path is provided.
+	defaultWritePath = "/api/v1/write"
+
+	// defaultQueryPath is the path of the PromQL query endpoint of
+	// Prometheus, used when no path is provided.
+	defaultQueryPath = "/api/v1/query"
+
+	// requestTimeout is the time after which a request is canceled.
+	requestTimeout = 30 * time.Second
+)
+
+// Names of the metrics that are written.
+const (
+	metricUp       = "pinger_check_up"
+	metricDegraded = "pinger_check_degraded"
+	metricTimeout  = "pinger_check_timeout"
+	metricDuration = "pinger_check_duration_seconds"
+)
+
+// Names of the labels of each metric.
+const (
+	labelName      = "__name__"
+	labelCheckID   = "check_id"
+	labelCheckName = "check_name"
+	labelLocation  = "location"
+)
+
+func init() {
+	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
+}
+
+// Exporter pushes the metrics into a remote-write endpoint.
+//
+// Each metric is written as samples of four series, all labelled with the ID
+// and the name of the check at the start time of the check:
+//
+//	pinger_check_up                1 if the check was successful
+//	pinger_check_degraded          1 if the check was degraded
+//	pinger_check_timeout           1 if the check timed-out
+//	pinger_check_duration_seconds  time taken by the check
+type Exporter struct {
+	client   *http.Client
+	writeURL string
+	queryURL string
+
+	username string
+	password string
+	tenant   string
+}
+
+// Provision sets e's configuration.
+func (e *Exporter) Provision(_ *appcontext.Context, provider exporter.Provider) error {
+	if provider.GetBackend() != exporterName {
+		return fmt.Errorf(
+			"invalid exporter name: expected '%s'; got '%s'",
+			exporterName,
+			provider.GetBackend(),
+		)
+	}
+
+	if provider.GetHost() == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+
+	scheme := "http"
+	if provider.IsSSLMode() {
+		scheme = "https"
+	}
+
+	host := provider.GetHost()
+	if provider.GetPort() != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(int(provider.GetPort())))
+	}
+
+	conf := provider.GetRemoteWrite()
+
+	writePath := conf.GetWritePath()
+	if writePath == "" {
+		writePath = defaultWritePath
+	}
+
+	queryPath := conf.GetQueryPath()
+	if queryPath == "" {
+		queryPath = defaultQueryPath
+	}
+
+	e.client = &http.Client{Timeout: requestTimeout}
+	e.writeURL = (&url.URL{Scheme: scheme, Host: host, Path: writePath}).String()
+	e.queryURL = (&url.URL{Scheme: scheme, Host: host, Path: queryPath}).String()
+	e.username = provider.GetUsername()
+	e.password = provider.GetPassword()
+	e.tenant = provider.GetOrgName()
+
+	return nil
+}
+
+// Export pushes the metrics into the remote-write endpoint.
+func (e *Exporter) Export(ctx context.Context, metrics []checker.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	// samples of the same series are grouped together.
+	index := map[string]int{}
+	var series []timeSeries
+
+	add := func(name string, m checker.Metric, value float64) {
+		key := name + "\xff" + m.GetCheckID() + "\xff" + m.GetCheckName() + "\xff" + m.GetLocation()
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			labels := []label{
+				{name: labelName, value: name},
+				{name: labelCheckID, value: m.GetCheckID()},
+				{name: labelCheckName, value: m.GetCheckName()},
+			}
+			// labels with empty values are not allowed.
+			if m.GetLocation() != "" {
+				labels = append(labels, label{name: labelLocation, value: m.GetLocation()})
+			}
+			series = append(series, timeSeries{labels: labels})
+		}
+
+		series[i].samples = append(series[i].samples, sample{
+			value:     value,
+			timestamp: m.GetStartTime().UnixNano() / int64(time.Millisecond),
+		})
+	}
+
+	for _, m := range metrics {
+		add(metricUp, m, boolValue(m.IsSuccessful()))
+		add(metricDegraded, m, boolValue(m.IsDegraded()))
+		add(metricTimeout, m, boolValue(m.IsTimeout()))
+		add(metricDuration, m, m.GetDuration().Seconds())
+	}
+
+	body := encodeSnappy(encodeWriteRequest(series))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	e.authorize(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot write metrics: %w", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		err = fmt.Errorf("cannot write metrics: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+
+		// the receiver rejected the samples themselves, so sending them again
+		// cannot succeed, unless it is only rate limiting the writes.
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return exporter.Permanent(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// queryResponse is the response of the PromQL query API for a range vector.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// GetMetrics get the metrics of the given checks by querying the raw
+// samples of the series in the past `duration`.
+func (e *Exporter) GetMetrics(
+	ctx context.Context,
+	duration time.Duration,
+	checkIDs ...string,
+) (map[string][]checker.Metric, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	seconds := int64(math.Ceil(duration.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	query := fmt.Sprintf(`{%s=~%s,%s=~%s}[%ds]`,
+		labelName, strconv.Quote(strings.Join([]string{metricUp, metricDegraded, metricTimeout, metricDuration}, "|")),
+		labelCheckID, strconv.Quote(regexMatchIDs(checkIDs)),
+		seconds,
+	)
+
+	form := url.Values{}
+	form.Set("query", query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.queryURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.authorize(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query metrics: %w", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	var qr queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&qr); err != nil {
+		return nil, fmt.Errorf("cannot query metrics: %s: %w", resp.Status, err)
+	}
+
+	if qr.Status != "success" {
+		return nil, fmt.Errorf("cannot query metrics: %s: %s", resp.Status, qr.Error)
+	}
+
+	type key struct {
+		checkID   string
+		location  string
+		timestamp int64
+	}
+
+	// samples of all the series at the same time for a check from a location
+	// make a metric.
+	byKey := map[key]*config.Metric{}
+	for _, r := range qr.Data.Result {
+		checkID := r.Metric[labelCheckID]
+		location := r.Metric[labelLocation]
+
+		for _, v := range r.Values {
+			ts, ok := v[0].(float64)
+			if !ok {
+				continue
+			}
+
+			str, ok := v[1].(string)
+			if !ok {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				continue
+			}
+
+			k := key{checkID: checkID, location: location, timestamp: int64(math.Round(ts * 1000))}
+			metric, ok := byKey[k]
+			if !ok {
+				metric = &config.Metric{
+					CheckID:   checkID,
+					CheckName: r.Metric[labelCheckName],
+					Location:  location,
+					StartTime: time.Unix(0, k.timestamp*int64(time.Millisecond)),
+				}
+				byKey[k] = metric
+			}
+
+			switch r.Metric[labelName] {
+			case metricUp:
+				metric.Successful = value == 1
+			case metricDegraded:
+				metric.Degraded = value == 1
+			case metricTimeout:
+				metric.Timeout = value == 1
+			case metricDuration:
+				metric.Duration = time.Duration(value * float64(time.Second))
+			}
+		}
+	}
+
+	metrics := map[string][]checker.Metric{}
+	for _, metric := range byKey {
+		metrics[metric.CheckID] = append(metrics[metric.CheckID], metric)
+	}
+
+	for _, ms := range metrics {
+		sort.Slice(ms, func(i, j int) bool {
+			return ms[i].GetStartTime().After(ms[j].GetStartTime())
+		})
+	}
+
+	return metrics, nil
+}
+
+// GetAggregates aggregates the metrics of the given checks after reading
+// the raw samples back from the query endpoint.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	start, width := exporter.BucketStart(duration, buckets)
+	metrics, err := e.GetMetrics(ctx, time.Since(start), checkIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.AggregateMetrics(metrics, start, width), nil
+}
+
+// authorize sets the credentials and the tenant on the request, if any.
+func (e *Exporter) authorize(req *http.Request) {
+	switch {
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	case e.password != "":
+		req.Header.Set("Authorization", "Bearer "+e.password) // token authentication
+	}
+
+	if e.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", e.tenant)
+	}
+}
+
+// regexMatchIDs creates a regex which matches all the given check IDs.
+func regexMatchIDs(checkIDs []string) string {
+	quoted := make([]string, len(checkIDs))
+	for i := range checkIDs {
+		quoted[i] = regexp.QuoteMeta(checkIDs[i])
+	}
+
+	return strings.Join(quoted, "|")
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// Interface guard.
+var _ exporter.Exporter = (*Exporter)(nil)
@@ -0,0 +1,216 @@
+package exporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+)
+
+// Intervals the metrics are rolled up into.
+const (
+	RollupHourly = time.Hour
+	RollupDaily  = 24 * time.Hour
+)
+
+const (
+	// rawDuration is the longest duration for which the raw metrics are read.
+	// Longer durations are read from the rollups.
+	rawDuration = 24 * time.Hour
+
+	// hourlyDuration is the longest duration for which the hourly rollups
+	// are read. Longer durations are read from the daily rollups.
+	hourlyDuration = 31 * 24 * time.Hour
+)
+
+// RollupInterval returns the interval of the rollups the metrics should be
+// read from for the given duration. It returns 0 if the raw metrics should
+// be read instead.
+func RollupInterval(duration time.Duration) time.Duration {
+	switch {
+	case duration <= rawDuration:
+		return 0
+	case duration <= hourlyDuration:
+		return RollupHourly
+	default:
+		return RollupDaily
+	}
+}
+
+// Rollup is the aggregate of all the runs of a check that started within an
+// interval of time.
+//
+// A rollup is a metric in itself, so it can be returned along with the raw
+// metrics. It is successful only if all the runs were successful and its
+// duration is the longest of all the runs, so that a failure or a slow run
+// is not hidden by the aggregation.
+type Rollup struct {
+	CheckID   string
+	CheckName string
+
+	StartTime time.Time     // Start of the interval.
+	Interval  time.Duration // Length of the interval.
+
+	Runs         int64 // Number of runs in the interval.
+	Successes    int64 // Number of successful runs.
+	Timeouts     int64 // Number of runs that timed out.
+	Degradations int64 // Number of runs that were degraded.
+
+	MeanDuration time.Duration // Mean of the time taken by the runs.
+	MaxDuration  time.Duration // Longest time taken by a run.
+}
+
+// GetCheckID returns the check ID.
+func (r *Rollup) GetCheckID() string {
+	return r.CheckID
+}
+
+// GetCheckName returns the check name.
+func (r *Rollup) GetCheckName() string {
+	return r.CheckName
+}
+
+// GetLocation returns an empty location since the runs from all the
+// locations are rolled up together.
+func (r *Rollup) GetLocation() string {
+	return ""
+}
+
+// GetStartTime returns the start of the interval.
+func (r *Rollup) GetStartTime() time.Time {
+	return r.StartTime
+}
+
+// GetDuration returns the longest time taken by a run.
+func (r *Rollup) GetDuration() time.Duration {
+	return r.MaxDuration
+}
+
+// IsTimeout tells if any of the runs timed out.
+func (r *Rollup) IsTimeout() bool {
+	return r.Timeouts > 0
+}
+
+// IsDegraded tells if any of the runs was degraded.
+func (r *Rollup) IsDegraded() bool {
+	return r.Degradations > 0
+}
+
+// IsSuccessful tells if all the runs were successful.
+func (r *Rollup) IsSuccessful() bool {
+	return r.Successes == r.Runs
+}
+
+// GetFailureCode returns an empty string since the runs can fail for
+// different reasons.
+func (r *Rollup) GetFailureCode() string {
+	return ""
+}
+
+// GetFailureMessage returns an empty string since the runs can fail for
+// different reasons.
+func (r *Rollup) GetFailureMessage() string {
+	return ""
+}
+
+// GetMeasurements returns the counts and the mean duration of the runs.
+func (r *Rollup) GetMeasurements() map[string]interface{} {
+	return map[string]interface{}{
+		"runs":          r.Runs,
+		"successes":     r.Successes,
+		"timeouts":      r.Timeouts,
+		"degradations":  r.Degradations,
+		"mean_duration": r.MeanDuration,
+	}
+}
+
+// GetRuns returns the number of runs in the interval.
+func (r *Rollup) GetRuns() int64 {
+	return r.Runs
+}
+
+// GetSuccessfulRuns returns the number of successful runs in the interval.
+func (r *Rollup) GetSuccessfulRuns() int64 {
+	return r.Successes
+}
+
+// RollUp aggregates the metrics into rollups of the given interval. The
+// intervals are aligned to the UTC calendar, so hourly and daily rollups
+// begin at the start of an hour and a day respectively.
+func RollUp(metrics []checker.Metric, interval time.Duration) []*Rollup {
+	type key struct {
+		checkID string
+		start   int64
+	}
+
+	var (
+		rollups []*Rollup
+		totals  []time.Duration
+	)
+
+	index := map[key]int{}
+	for _, m := range metrics {
+		start := m.GetStartTime().UTC().Truncate(interval)
+		k := key{checkID: m.GetCheckID(), start: start.UnixNano()}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(rollups)
+			index[k] = i
+			rollups = append(rollups, &Rollup{
+				CheckID:   m.GetCheckID(),
+				CheckName: m.GetCheckName(),
+				StartTime: start,
+				Interval:  interval,
+			})
+			totals = append(totals, 0)
+		}
+
+		r := rollups[i]
+		r.Runs++
+		if m.IsSuccessful() {
+			r.Successes++
+		}
+		if m.IsTimeout() {
+			r.Timeouts++
+		}
+		if m.IsDegraded() {
+			r.Degradations++
+		}
+
+		totals[i] += m.GetDuration()
+		if m.GetDuration() > r.MaxDuration {
+			r.MaxDuration = m.GetDuration()
+		}
+	}
+
+	for i, r := range rollups {
+		r.MeanDuration = totals[i] / time.Duration(r.Runs)
+	}
+
+	return rollups
+}
+
+// MergeRollups adds the rollups after the raw metrics of each check and
+// sorts them in descending order of their start times, as expected from
+// `GetMetrics`.
+func MergeRollups(metrics map[string][]checker.Metric, rollups []*Rollup) map[string][]checker.Metric {
+	if metrics == nil {
+		metrics = map[string][]checker.Metric{}
+	}
+
+	for _, r := range rollups {
+		metrics[r.CheckID] = append(metrics[r.CheckID], r)
+	}
+
+	for _, ms := range metrics {
+		sort.SliceStable(ms, func(i, j int) bool {
+			return ms[i].GetStartTime().After(ms[j].GetStartTime())
+		})
+	}
+
+	return metrics
+}
+
+// Interface guard.
+var _ checker.Metric = (*Rollup)(nil)
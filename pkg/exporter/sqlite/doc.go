@@ -0,0 +1,10 @@
+// Package sqlite implements the sqlite exporter.
+//
+// The metrics are stored in a SQLite database file embedded in the agent,
+// so the status page can be deployed without running any other service.
+// Metrics older than the retention are deleted periodically.
+//
+// The exporter requires the agent to be built with cgo enabled. Metrics that
+// the database rejects, such as those violating a constraint, are dropped
+// instead of being retried.
+package sqlite
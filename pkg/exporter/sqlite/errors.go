@@ -0,0 +1,29 @@
+//go:build cgo
+// +build cgo
+
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/sdslabs/pinger/pkg/exporter"
+)
+
+// classifyError marks the errors of the metrics that the database can never
+// store, such as constraint violations, as permanent so that the batch is
+// dropped instead of being retried.
+func classifyError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code {
+	case sqlite3.ErrConstraint, sqlite3.ErrMismatch, sqlite3.ErrTooBig:
+		return exporter.Permanent(err)
+	default:
+		return err
+	}
+}
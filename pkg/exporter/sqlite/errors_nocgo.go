@@ -0,0 +1,10 @@
+//go:build !cgo
+// +build !cgo
+
+package sqlite
+
+// classifyError returns the error as-is since, without cgo, the database
+// cannot be opened in the first place.
+func classifyError(err error) error {
+	return err
+}
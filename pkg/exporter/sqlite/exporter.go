@@ -0,0 +1,413 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const exporterName = "sqlite"
+
+const (
+	// defaultPath is the path of the database file if none is provided.
+	defaultPath = "pinger.db"
+
+	// defaultRetention is the duration metrics are retained for if none is
+	// provided. It is the maximum duration the status page shows.
+	defaultRetention = 7 * 24 * time.Hour
+
+	// cleanupInterval is the interval after which metrics older than the
+	// retention are deleted.
+	cleanupInterval = time.Hour
+)
+
+func init() {
+	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
+}
+
+// Exporter for exporting metrics to a sqlite database.
+type Exporter struct {
+	connection *gorm.DB
+	retention  time.Duration
+}
+
+// Metric model.
+//
+// The start time is stored as nanoseconds since the epoch since SQLite does
+// not have a type for time and integers are compared correctly.
+type Metric struct {
+	CheckID   string `gorm:"NOT NULL"`
+	CheckName string
+	Location  string
+
+	StartTime int64         `gorm:"NOT NULL"`
+	Duration  time.Duration `gorm:"NOT NULL"`
+	Timeout   bool          `gorm:"NOT NULL"`
+	Success   bool          `gorm:"NOT NULL"`
+	Degraded  bool          `gorm:"NOT NULL;DEFAULT:false"`
+
+	FailureCode    string
+	FailureMessage string
+
+	Measurements Measurements `gorm:"TYPE:text"`
+}
+
+// Measurements are the measurements of the metric stored as JSON text.
+type Measurements map[string]interface{}
+
+// Value encodes the measurements into JSON.
+func (m Measurements) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan decodes the measurements from JSON.
+func (m *Measurements) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+
+	case []byte:
+		b = v
+
+	case string:
+		b = []byte(v)
+
+	default:
+		return fmt.Errorf("cannot scan %T into measurements", src)
+	}
+
+	return json.Unmarshal(b, m)
+}
+
+// GetCheckID returns the check ID.
+func (m Metric) GetCheckID() string {
+	return m.CheckID
+}
+
+// GetCheckName returns the check name.
+func (m Metric) GetCheckName() string {
+	return m.CheckName
+}
+
+// GetLocation returns the location of the agent the check ran from.
+func (m Metric) GetLocation() string {
+	return m.Location
+}
+
+// GetStartTime returns the start time.
+func (m Metric) GetStartTime() time.Time {
+	return time.Unix(0, m.StartTime)
+}
+
+// GetDuration returns the duration.
+func (m Metric) GetDuration() time.Duration {
+	return m.Duration
+}
+
+// IsTimeout tells if the check timed out.
+func (m Metric) IsTimeout() bool {
+	return m.Timeout
+}
+
+// IsDegraded tells if the check took longer than the latency threshold.
+func (m Metric) IsDegraded() bool {
+	return m.Degraded
+}
+
+// IsSuccessful tells if the check was successful.
+func (m Metric) IsSuccessful() bool {
+	return m.Success
+}
+
+// GetFailureCode returns the reason of failure.
+func (m Metric) GetFailureCode() string {
+	return m.FailureCode
+}
+
+// GetFailureMessage returns the detailed message of failure.
+func (m Metric) GetFailureMessage() string {
+	return m.FailureMessage
+}
+
+// GetMeasurements returns the measurements recorded during the check.
+func (m Metric) GetMeasurements() map[string]interface{} {
+	return m.Measurements
+}
+
+// newConn opens the database file, creating it if it does not exist.
+func newConn(ctx *appcontext.Context, path string) (*gorm.DB, error) {
+	// WAL journal lets the status page read while metrics are written, and
+	// the busy timeout makes the writer wait instead of failing right away.
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000", path)
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.WithContext(ctx).AutoMigrate(
+		&Metric{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.WithContext(ctx).Exec(
+		"CREATE INDEX IF NOT EXISTS idx_metrics_check_id_start_time ON metrics (check_id, start_time DESC);",
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// for deleting metrics older than the retention.
+	err = db.WithContext(ctx).Exec(
+		"CREATE INDEX IF NOT EXISTS idx_metrics_start_time ON metrics (start_time);",
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// createMetrics inserts multiple metrics into the metrics table.
+func (e *Exporter) createMetrics(ctx context.Context, metrics []checker.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	toInsert := make([]Metric, 0, len(metrics))
+	for i := range metrics {
+		m := metrics[i]
+		toInsert = append(toInsert, Metric{
+			CheckID:   m.GetCheckID(),
+			CheckName: m.GetCheckName(),
+			Location:  m.GetLocation(),
+			StartTime: m.GetStartTime().UnixNano(),
+			Duration:  m.GetDuration(),
+			Timeout:   m.IsTimeout(),
+			Success:   m.IsSuccessful(),
+			Degraded:  m.IsDegraded(),
+
+			FailureCode:    m.GetFailureCode(),
+			FailureMessage: m.GetFailureMessage(),
+
+			Measurements: m.GetMeasurements(),
+		})
+	}
+
+	return e.connection.WithContext(ctx).Create(&toInsert).Error
+}
+
+// getMetricsByChecksAndDuration fetches metrics from the metrics table for
+// the given check IDs. It accepts a `duration` parameter that fetches
+// metrics for the check in the past `duration time.Duration`.
+func (e *Exporter) getMetricsByChecksAndDuration(
+	ctx context.Context,
+	checkIDs []string,
+	duration time.Duration,
+) (map[string][]checker.Metric, error) {
+	startTime := time.Now().Add(-1 * duration).UnixNano()
+	var fetched []Metric
+	tx := e.connection.WithContext(ctx).Where("check_id IN (?) AND start_time > ?", checkIDs, startTime).
+		Order("start_time DESC").
+		Find(&fetched)
+
+	metrics := map[string][]checker.Metric{}
+	for i := range fetched {
+		m := fetched[i]
+		metrics[m.CheckID] = append(metrics[m.CheckID], m)
+	}
+
+	return metrics, tx.Error
+}
+
+// cleanup deletes the metrics older than the retention periodically till
+// the context is canceled.
+func (e *Exporter) cleanup(ctx *appcontext.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		before := time.Now().Add(-1 * e.retention).UnixNano()
+		err := e.connection.WithContext(ctx).Where("start_time < ?", before).Delete(&Metric{}).Error
+		if err != nil {
+			ctx.Logger().WithError(err).Errorln("cannot delete old metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Provision sets e's configuration.
+func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider) error {
+	if provider.GetBackend() != exporterName {
+		return fmt.Errorf(
+			"invalid exporter name: expected '%s'; got '%s'",
+			exporterName,
+			provider.GetBackend(),
+		)
+	}
+
+	path := provider.GetDBName()
+	if path == "" {
+		path = defaultPath
+	}
+
+	e.retention = provider.GetRetention().GetDuration()
+	if e.retention < 0 {
+		return fmt.Errorf("retention should be >= 0")
+	}
+	if e.retention == 0 {
+		e.retention = defaultRetention
+	}
+
+	conn, err := newConn(ctx, path)
+	if err != nil {
+		return err
+	}
+	e.connection = conn
+
+	go e.cleanup(ctx)
+
+	return nil
+}
+
+// Export exports the metrics to the exporter.
+func (e *Exporter) Export(ctx context.Context, metrics []checker.Metric) error {
+	return classifyError(e.createMetrics(ctx, metrics))
+}
+
+// GetMetrics get the metrics of the given checks.
+func (e *Exporter) GetMetrics(
+	ctx context.Context,
+	time time.Duration,
+	checkIDs ...string,
+) (map[string][]checker.Metric, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	return e.getMetricsByChecksAndDuration(ctx, checkIDs, time)
+}
+
+// aggregateRow is a row of the aggregated metrics.
+type aggregateRow struct {
+	CheckID      string
+	Location     string
+	Bucket       int64
+	Runs         int64
+	Failures     int64
+	Timeouts     int64
+	Degradations int64
+	P50          int64
+	P95          int64
+	P99          int64
+}
+
+// aggregateQuery aggregates the metrics of each location into buckets. SQLite does not have
+// percentile functions, so the runs in each bucket are ranked by their
+// duration and the percentiles are picked using the nearest-rank method.
+const aggregateQuery = `
+WITH bucketed AS (
+	SELECT check_id, coalesce(location, '') AS location, (start_time - @start) / @width AS bucket, duration, success, timeout, degraded
+	FROM metrics
+	WHERE check_id IN @ids AND start_time >= @start
+), ranked AS (
+	SELECT *,
+		ROW_NUMBER() OVER (PARTITION BY check_id, location, bucket ORDER BY duration) AS pos,
+		COUNT(*) OVER (PARTITION BY check_id, location, bucket) AS n
+	FROM bucketed
+)
+SELECT
+	check_id,
+	location,
+	bucket,
+	COUNT(*) AS runs,
+	SUM(CASE WHEN success THEN 0 ELSE 1 END) AS failures,
+	SUM(CASE WHEN timeout THEN 1 ELSE 0 END) AS timeouts,
+	SUM(CASE WHEN degraded THEN 1 ELSE 0 END) AS degradations,
+	MIN(CASE WHEN pos >= n * 0.50 THEN duration END) AS p50,
+	MIN(CASE WHEN pos >= n * 0.95 THEN duration END) AS p95,
+	MIN(CASE WHEN pos >= n * 0.99 THEN duration END) AS p99
+FROM ranked
+GROUP BY check_id, location, bucket
+ORDER BY bucket DESC;`
+
+// GetRetention returns how long the metrics are retained for.
+func (e *Exporter) GetRetention() time.Duration {
+	return e.retention
+}
+
+// GetAggregates aggregates the metrics of the given checks in the database.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	start, width := exporter.BucketStart(duration, buckets)
+
+	var rows []aggregateRow
+	err := e.connection.WithContext(ctx).Raw(aggregateQuery, map[string]interface{}{
+		"start": start.UnixNano(),
+		"width": int64(width),
+		"ids":   checkIDs,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := map[string][]exporter.Aggregate{}
+	for i := range rows {
+		r := rows[i]
+		aggregates[r.CheckID] = append(aggregates[r.CheckID], exporter.Aggregate{
+			StartTime:    start.Add(time.Duration(r.Bucket) * width),
+			Location:     r.Location,
+			Runs:         r.Runs,
+			Failures:     r.Failures,
+			Timeouts:     r.Timeouts,
+			Degradations: r.Degradations,
+			P50:          time.Duration(r.P50),
+			P95:          time.Duration(r.P95),
+			P99:          time.Duration(r.P99),
+		})
+	}
+
+	return aggregates, nil
+}
+
+// Interface guards.
+var (
+	_ exporter.Exporter = (*Exporter)(nil)
+	_ exporter.Retainer = (*Exporter)(nil)
+	_ checker.Metric    = Metric{}
+)
@@ -2,6 +2,8 @@ package timescale
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -15,24 +17,91 @@ import (
 
 const exporterName = "timescale"
 
+const (
+	// rollupStartOffset is how far back the rollups are refreshed from. The
+	// raw metrics must be retained for at least this long since refreshing
+	// the rollups after the raw metrics are dropped drops them as well.
+	rollupStartOffset = 3 * exporter.RollupDaily
+
+	// rollupSchedule is the interval after which the rollups are refreshed.
+	rollupSchedule = time.Hour
+)
+
+// rollupViews are the continuous aggregates of the metrics for each interval.
+var rollupViews = map[time.Duration]string{
+	exporter.RollupHourly: "metrics_hourly",
+	exporter.RollupDaily:  "metrics_daily",
+}
+
 func init() {
 	exporter.Register(exporterName, func() exporter.Exporter { return new(Exporter) })
 }
 
 // Exporter for exporting metrics to timescale db.
+//
+// The metrics older than the retention are dropped by a retention policy of
+// TimescaleDB. When rollups are enabled, the metrics are also aggregated into
+// hourly and daily continuous aggregates which are read for long durations.
 type Exporter struct {
 	connection *gorm.DB
+	retention  time.Duration
+	rollups    bool
 }
 
 // Metric model.
 type Metric struct {
 	CheckID   string
 	CheckName string
+	Location  string
 
 	StartTime time.Time     `gorm:"NOT NULL"`
 	Duration  time.Duration `gorm:"NOT NULL"`
 	Timeout   bool          `gorm:"NOT NULL"`
 	Success   bool          `gorm:"NOT NULL"`
+	Degraded  bool          `gorm:"NOT NULL;DEFAULT:false"`
+
+	FailureCode    string
+	FailureMessage string `gorm:"TYPE:text"`
+
+	Measurements Measurements `gorm:"TYPE:jsonb"`
+}
+
+// Measurements are the measurements of the metric stored as JSONB.
+type Measurements map[string]interface{}
+
+// Value encodes the measurements into JSON.
+func (m Measurements) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan decodes the measurements from JSON.
+func (m *Measurements) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+
+	case []byte:
+		b = v
+
+	case string:
+		b = []byte(v)
+
+	default:
+		return fmt.Errorf("cannot scan %T into measurements", src)
+	}
+
+	return json.Unmarshal(b, m)
 }
 
 // GetCheckID returns the check ID.
@@ -45,6 +114,11 @@ func (m Metric) GetCheckName() string {
 	return m.CheckName
 }
 
+// GetLocation returns the location of the agent the check ran from.
+func (m Metric) GetLocation() string {
+	return m.Location
+}
+
 // GetStartTime returns the start time.
 func (m Metric) GetStartTime() time.Time {
 	return m.StartTime
@@ -60,11 +134,31 @@ func (m Metric) IsTimeout() bool {
 	return m.Timeout
 }
 
+// IsDegraded tells if the check took longer than the latency threshold.
+func (m Metric) IsDegraded() bool {
+	return m.Degraded
+}
+
 // IsSuccessful tells if the check was successful.
 func (m Metric) IsSuccessful() bool {
 	return m.Success
 }
 
+// GetFailureCode returns the reason of failure.
+func (m Metric) GetFailureCode() string {
+	return m.FailureCode
+}
+
+// GetFailureMessage returns the detailed message of failure.
+func (m Metric) GetFailureMessage() string {
+	return m.FailureMessage
+}
+
+// GetMeasurements returns the measurements recorded during the check.
+func (m Metric) GetMeasurements() map[string]interface{} {
+	return m.Measurements
+}
+
 // newConn creates a new connection with the database.
 func newConn(ctx *appcontext.Context, provider exporter.Provider) (*gorm.DB, error) {
 	connStr := fmt.Sprintf(
@@ -115,9 +209,98 @@ func newConn(ctx *appcontext.Context, provider exporter.Provider) (*gorm.DB, err
 		return nil, err
 	}
 
+	retention := provider.GetRetention()
+	if err := setRetention(ctx, db, "metrics", retention.GetDuration()); err != nil {
+		return nil, err
+	}
+
+	if !retention.HasRollups() {
+		return db, nil
+	}
+
+	for interval, view := range rollupViews {
+		if err := createRollup(ctx, db, view, interval); err != nil {
+			return nil, err
+		}
+
+		if err := setRetention(ctx, db, view, retention.GetRollupDuration()); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
+// setRetention replaces the retention policy of the hypertable or the
+// continuous aggregate. Retention of 0 removes the policy.
+func setRetention(ctx *appcontext.Context, db *gorm.DB, relation string, retention time.Duration) error {
+	err := db.WithContext(ctx).Exec(
+		"SELECT remove_retention_policy(?, if_exists => TRUE);",
+		relation,
+	).Error
+	if err != nil {
+		return fmt.Errorf("cannot remove retention policy of %s: %w", relation, err)
+	}
+
+	if retention == 0 {
+		return nil
+	}
+
+	err = db.WithContext(ctx).Exec(
+		"SELECT add_retention_policy(?, ?::interval);",
+		relation, interval(retention),
+	).Error
+	if err != nil {
+		return fmt.Errorf("cannot add retention policy to %s: %w", relation, err)
+	}
+
+	return nil
+}
+
+// createRollup creates the continuous aggregate of the metrics for the
+// interval along with the policy that refreshes it. Real-time aggregation is
+// enabled so that the metrics yet to be refreshed are aggregated as well.
+func createRollup(ctx *appcontext.Context, db *gorm.DB, view string, every time.Duration) error {
+	err := db.WithContext(ctx).Exec(fmt.Sprintf(`
+CREATE MATERIALIZED VIEW IF NOT EXISTS %s
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+	check_id,
+	time_bucket(INTERVAL '%s', start_time) AS bucket,
+	max(check_name) AS check_name,
+	count(*) AS runs,
+	sum(CASE WHEN success THEN 1 ELSE 0 END) AS successes,
+	sum(CASE WHEN timeout THEN 1 ELSE 0 END) AS timeouts,
+	sum(CASE WHEN degraded THEN 1 ELSE 0 END) AS degradations,
+	avg(duration) AS mean_duration,
+	max(duration) AS max_duration
+FROM metrics
+GROUP BY check_id, bucket
+WITH NO DATA;`, view, interval(every))).Error
+	if err != nil {
+		return fmt.Errorf("cannot create rollup %s: %w", view, err)
+	}
+
+	err = db.WithContext(ctx).Exec(
+		`SELECT add_continuous_aggregate_policy(?,
+	start_offset => ?::interval,
+	end_offset => ?::interval,
+	schedule_interval => ?::interval,
+	if_not_exists => TRUE);`,
+		view, interval(rollupStartOffset), interval(every), interval(rollupSchedule),
+	).Error
+	if err != nil {
+		return fmt.Errorf("cannot add refresh policy to %s: %w", view, err)
+	}
+
+	return nil
+}
+
+// interval formats the duration as a postgres interval.
+func interval(d time.Duration) string {
+	return fmt.Sprintf("%d seconds", int64(d.Seconds()))
+}
+
 // createMetrics inserts multiple metrics into TimescaleDB Hypertable.
 func (e *Exporter) createMetrics(ctx context.Context, metrics []checker.Metric) error {
 	if len(metrics) == 0 {
@@ -130,10 +313,17 @@ func (e *Exporter) createMetrics(ctx context.Context, metrics []checker.Metric)
 		toInsert = append(toInsert, Metric{
 			CheckID:   m.GetCheckID(),
 			CheckName: m.GetCheckName(),
+			Location:  m.GetLocation(),
 			StartTime: m.GetStartTime(),
 			Duration:  m.GetDuration(),
 			Timeout:   m.IsTimeout(),
 			Success:   m.IsSuccessful(),
+			Degraded:  m.IsDegraded(),
+
+			FailureCode:    m.GetFailureCode(),
+			FailureMessage: m.GetFailureMessage(),
+
+			Measurements: m.GetMeasurements(),
 		})
 	}
 
@@ -168,6 +358,65 @@ func (e *Exporter) getMetricsByChecksAndDuration(
 	return metrics, tx.Error
 }
 
+// rollupRow is a row of the continuous aggregates.
+type rollupRow struct {
+	CheckID      string
+	Bucket       time.Time
+	CheckName    string
+	Runs         int64
+	Successes    int64
+	Timeouts     int64
+	Degradations int64
+	MeanDuration float64
+	MaxDuration  int64
+}
+
+// getRollupsByChecksAndDuration fetches the rollups of the given interval
+// that start in the past `duration` and end before the start of the current
+// interval. The metrics after that are fetched raw.
+func (e *Exporter) getRollupsByChecksAndDuration(
+	ctx context.Context,
+	checkIDs []string,
+	duration time.Duration,
+	every time.Duration,
+) (map[string][]checker.Metric, error) {
+	now := time.Now()
+	boundary := now.UTC().Truncate(every)
+
+	metrics, err := e.getMetricsByChecksAndDuration(ctx, checkIDs, now.Sub(boundary))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []rollupRow
+	err = e.connection.WithContext(ctx).Raw(
+		fmt.Sprintf(`SELECT * FROM %s WHERE check_id IN (?) AND bucket >= ? AND bucket < ?`, rollupViews[every]),
+		checkIDs, now.Add(-1*duration), boundary,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rollups := make([]*exporter.Rollup, 0, len(rows))
+	for i := range rows {
+		r := rows[i]
+		rollups = append(rollups, &exporter.Rollup{
+			CheckID:      r.CheckID,
+			CheckName:    r.CheckName,
+			StartTime:    r.Bucket,
+			Interval:     every,
+			Runs:         r.Runs,
+			Successes:    r.Successes,
+			Timeouts:     r.Timeouts,
+			Degradations: r.Degradations,
+			MeanDuration: time.Duration(r.MeanDuration),
+			MaxDuration:  time.Duration(r.MaxDuration),
+		})
+	}
+
+	return exporter.MergeRollups(metrics, rollups), nil
+}
+
 // Provision sets e's configuration.
 func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider) error {
 	if provider.GetBackend() != exporterName {
@@ -178,6 +427,17 @@ func (e *Exporter) Provision(ctx *appcontext.Context, provider exporter.Provider
 		)
 	}
 
+	retention := provider.GetRetention()
+	if retention.GetDuration() < 0 || retention.GetRollupDuration() < 0 {
+		return fmt.Errorf("retention should be >= 0")
+	}
+
+	e.retention = retention.GetDuration()
+	e.rollups = retention.HasRollups()
+	if e.rollups && retention.GetDuration() != 0 && retention.GetDuration() < rollupStartOffset {
+		return fmt.Errorf("retention should be >= %s when rollups are enabled", rollupStartOffset)
+	}
+
 	conn, err := newConn(ctx, provider)
 	if err != nil {
 		return err
@@ -202,11 +462,96 @@ func (e *Exporter) GetMetrics(
 		return nil, nil
 	}
 
+	if every := exporter.RollupInterval(time); e.rollups && every != 0 {
+		return e.getRollupsByChecksAndDuration(ctx, checkIDs, time, every)
+	}
+
 	return e.getMetricsByChecksAndDuration(ctx, checkIDs, time)
 }
 
+// aggregateRow is a row of the aggregated metrics.
+type aggregateRow struct {
+	CheckID      string
+	Location     string
+	Bucket       int64
+	Runs         int64
+	Failures     int64
+	Timeouts     int64
+	Degradations int64
+	P50          int64
+	P95          int64
+	P99          int64
+}
+
+// aggregateQuery aggregates the metrics of each location into buckets.
+const aggregateQuery = `
+SELECT
+	check_id,
+	coalesce(location, '') AS location,
+	floor(extract(epoch FROM start_time - @start::timestamptz) / @width)::bigint AS bucket,
+	count(*) AS runs,
+	sum(CASE WHEN success THEN 0 ELSE 1 END) AS failures,
+	sum(CASE WHEN timeout THEN 1 ELSE 0 END) AS timeouts,
+	sum(CASE WHEN degraded THEN 1 ELSE 0 END) AS degradations,
+	percentile_disc(0.50) WITHIN GROUP (ORDER BY duration) AS p50,
+	percentile_disc(0.95) WITHIN GROUP (ORDER BY duration) AS p95,
+	percentile_disc(0.99) WITHIN GROUP (ORDER BY duration) AS p99
+FROM metrics
+WHERE check_id IN @ids AND start_time >= @start
+GROUP BY check_id, location, bucket
+ORDER BY bucket DESC;`
+
+// GetRetention returns how long the metrics are retained for. The rollups are
+// not aggregated, so it is the retention of the raw metrics.
+func (e *Exporter) GetRetention() time.Duration {
+	return e.retention
+}
+
+// GetAggregates aggregates the metrics of the given checks in the database.
+func (e *Exporter) GetAggregates(
+	ctx context.Context,
+	duration time.Duration,
+	buckets int,
+	checkIDs ...string,
+) (map[string][]exporter.Aggregate, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	start, width := exporter.BucketStart(duration, buckets)
+
+	var rows []aggregateRow
+	err := e.connection.WithContext(ctx).Raw(aggregateQuery, map[string]interface{}{
+		"start": start,
+		"width": int64(width.Seconds()),
+		"ids":   checkIDs,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := map[string][]exporter.Aggregate{}
+	for i := range rows {
+		r := rows[i]
+		aggregates[r.CheckID] = append(aggregates[r.CheckID], exporter.Aggregate{
+			StartTime:    start.Add(time.Duration(r.Bucket) * width),
+			Location:     r.Location,
+			Runs:         r.Runs,
+			Failures:     r.Failures,
+			Timeouts:     r.Timeouts,
+			Degradations: r.Degradations,
+			P50:          time.Duration(r.P50),
+			P95:          time.Duration(r.P95),
+			P99:          time.Duration(r.P99),
+		})
+	}
+
+	return aggregates, nil
+}
+
 // Interface guards.
 var (
 	_ exporter.Exporter = (*Exporter)(nil)
+	_ exporter.Retainer = (*Exporter)(nil)
 	_ checker.Metric    = Metric{}
 )
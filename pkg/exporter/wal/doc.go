@@ -0,0 +1,17 @@
+// Package wal implements a write-ahead buffer that sits between the agent and
+// the metrics exporter.
+//
+// Metrics are first written into the buffer and then exported in the same
+// order they were written. If the exporter fails, for example, when the
+// metrics backend is unavailable, the metrics stay in the buffer and the
+// export is retried with an exponential backoff till it succeeds. Since a
+// batch is removed from the buffer only after it is exported, a batch may
+// be exported more than once if the agent stops in between.
+//
+// The buffer does not grow without bound: batches rejected by the exporter
+// as permanent errors are dropped, and so are the oldest batches once the
+// buffer is over its maximum size or they are older than its maximum age.
+//
+// The buffer is kept on the disk when a directory is provided so that the
+// metrics also survive the restarts of the agent.
+package wal
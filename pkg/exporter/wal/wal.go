@@ -0,0 +1,630 @@
+package wal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/config"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const (
+	// segmentExt is the extension of the files that store a batch of
+	// metrics each.
+	segmentExt = ".wal"
+
+	// DefaultMinBackoff is the time to wait before the first retry.
+	DefaultMinBackoff = time.Second
+
+	// DefaultMaxBackoff is the maximum time to wait between the retries.
+	DefaultMaxBackoff = time.Minute
+
+	// DefaultMaxSize is the maximum size of the batches in the buffer.
+	DefaultMaxSize int64 = 256 << 20 // 256 MiB
+
+	// DefaultMaxAge is the time after which a batch yet to be exported is
+	// dropped.
+	DefaultMaxAge = 24 * time.Hour
+)
+
+// errCorruptSegment is returned when a segment cannot be decoded.
+var errCorruptSegment = errors.New("corrupt buffer segment")
+
+// Options are the options for creating the buffer.
+type Options struct {
+	// Name identifies the buffer in the logs.
+	Name string
+
+	// Dir is the directory where the batches are stored. If empty, the
+	// batches are only kept in memory.
+	Dir string
+
+	// MinBackoff is the time to wait before the first retry. It is doubled
+	// after each failed retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum time to wait between the retries.
+	MaxBackoff time.Duration
+
+	// MaxSize is the maximum size in bytes of the batches in the buffer. The
+	// oldest batches are dropped to make space for the new ones.
+	MaxSize int64
+
+	// MaxAge is the time after which a batch that is yet to be exported is
+	// dropped, so that a batch failing to export is not retried forever.
+	MaxAge time.Duration
+}
+
+// segment indexes a batch in the buffer so that the batch is only read when
+// it is exported or has metrics of the checks asked for.
+type segment struct {
+	seq       uint64
+	size      int64
+	createdAt time.Time
+
+	latest time.Time           // latest start time of the metrics
+	checks map[string]struct{} // checks with metrics in the batch
+}
+
+// newSegment indexes the batch.
+func newSegment(seq uint64, size int64, createdAt time.Time, batch []*config.Metric) *segment {
+	seg := &segment{
+		seq:       seq,
+		size:      size,
+		createdAt: createdAt,
+		checks:    map[string]struct{}{},
+	}
+
+	for _, m := range batch {
+		seg.checks[m.CheckID] = struct{}{}
+		if m.StartTime.After(seg.latest) {
+			seg.latest = m.StartTime
+		}
+	}
+
+	return seg
+}
+
+// Buffer is the write-ahead buffer for the metrics. Each batch of metrics
+// appended is stored as a segment identified by an increasing sequence
+// number.
+type Buffer struct {
+	ctx  *appcontext.Context
+	name string
+
+	dir        string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	maxSize    int64
+	maxAge     time.Duration
+
+	mu   sync.Mutex
+	segs []*segment        // pending segments, in order
+	size int64             // total size of the pending segments
+	mem  map[uint64][]byte // segments when not stored on the disk
+	next uint64            // sequence number of the next segment
+
+	notify chan struct{}
+}
+
+// Open creates a new buffer. If a directory is provided, the segments left
+// from the previous run are loaded so that they are exported first.
+func Open(ctx *appcontext.Context, opts *Options) (*Buffer, error) {
+	if opts.MinBackoff < 0 || opts.MaxBackoff < 0 {
+		return nil, fmt.Errorf("backoff should be >= 0")
+	}
+
+	if opts.MaxSize < 0 || opts.MaxAge < 0 {
+		return nil, fmt.Errorf("maximum size and age should be >= 0")
+	}
+
+	b := &Buffer{
+		ctx:        ctx,
+		name:       opts.Name,
+		dir:        opts.Dir,
+		minBackoff: opts.MinBackoff,
+		maxBackoff: opts.MaxBackoff,
+		maxSize:    opts.MaxSize,
+		maxAge:     opts.MaxAge,
+		notify:     make(chan struct{}, 1),
+	}
+
+	if b.minBackoff == 0 {
+		b.minBackoff = DefaultMinBackoff
+	}
+
+	if b.maxBackoff == 0 {
+		b.maxBackoff = DefaultMaxBackoff
+	}
+
+	if b.maxBackoff < b.minBackoff {
+		b.maxBackoff = b.minBackoff
+	}
+
+	if b.maxSize == 0 {
+		b.maxSize = DefaultMaxSize
+	}
+
+	if b.maxAge == 0 {
+		b.maxAge = DefaultMaxAge
+	}
+
+	if b.dir == "" {
+		b.mem = map[uint64][]byte{}
+		return b, nil
+	}
+
+	if err := os.MkdirAll(b.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create buffer directory: %w", err)
+	}
+
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read buffer directory: %w", err)
+	}
+
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || filepath.Ext(name) != segmentExt {
+			continue
+		}
+
+		seq, er := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if er != nil {
+			continue
+		}
+
+		// a corrupt segment is indexed without metrics, it is dropped when
+		// its turn to be exported comes.
+		batch, er := b.read(seq)
+		if er != nil && !errors.Is(er, errCorruptSegment) {
+			return nil, er
+		}
+
+		b.segs = append(b.segs, newSegment(seq, f.Size(), f.ModTime(), batch))
+		b.size += f.Size()
+	}
+
+	sort.Slice(b.segs, func(i, j int) bool { return b.segs[i].seq < b.segs[j].seq })
+	if len(b.segs) > 0 {
+		b.next = b.segs[len(b.segs)-1].seq + 1
+		b.prune(time.Now())
+		ctx.Logger().
+			WithField("buffer", b.name).
+			WithField("batches", len(b.segs)).
+			Infoln("replaying metrics left in the buffer")
+	}
+
+	return b, nil
+}
+
+// Name returns the name of the buffer.
+func (b *Buffer) Name() string {
+	return b.name
+}
+
+// Len returns the number of batches yet to be exported.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.segs)
+}
+
+// Append writes the metrics into the buffer as a single batch. The oldest
+// batches are dropped if the buffer grows over its maximum size.
+func (b *Buffer) Append(metrics []checker.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	batch := make([]*config.Metric, len(metrics))
+	for i := range metrics {
+		batch[i] = config.NewMetric(metrics[i])
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("cannot encode metrics: %w", err)
+	}
+
+	now := time.Now()
+
+	b.mu.Lock()
+	seq := b.next
+	if err := b.write(seq, data); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+
+	b.next++
+	b.segs = append(b.segs, newSegment(seq, int64(len(data)), now, batch))
+	b.size += int64(len(data))
+	b.prune(now)
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// prune drops the oldest segments while they are older than the maximum age
+// or the buffer is over its maximum size. The latest segment is always kept.
+// It should be called with the lock held.
+func (b *Buffer) prune(now time.Time) {
+	dropped := 0
+	for len(b.segs) > 0 {
+		seg := b.segs[0]
+		expired := now.Sub(seg.createdAt) > b.maxAge
+		if !expired && (b.size <= b.maxSize || len(b.segs) == 1) {
+			break
+		}
+
+		if err := b.removeSegment(seg.seq); err != nil {
+			b.ctx.Logger().
+				WithError(err).
+				WithField("buffer", b.name).
+				Errorln("cannot drop buffer segment")
+			break
+		}
+
+		dropped++
+	}
+
+	if dropped > 0 {
+		b.ctx.Logger().
+			WithField("buffer", b.name).
+			WithField("dropped_batches", dropped).
+			Warnln("dropping metrics over the size or age limit of the buffer")
+	}
+}
+
+// Run exports the batches in the order they were appended till the context
+// is canceled. If the export fails, it is retried with an exponential
+// backoff and the batches appended in the meantime wait for their turn.
+func (b *Buffer) Run(export exporter.ExportFunc) {
+	backoff := time.Duration(0)
+
+	for {
+		wait := b.notify
+		var retry <-chan time.Time
+
+		if err := b.flush(export); err != nil {
+			if backoff == 0 {
+				backoff = b.minBackoff
+			} else if backoff *= 2; backoff > b.maxBackoff {
+				backoff = b.maxBackoff
+			}
+
+			b.ctx.Logger().
+				WithError(err).
+				WithField("buffer", b.name).
+				WithField("pending_batches", b.Len()).
+				WithField("retry_in", backoff).
+				Errorln("error exporting metrics")
+
+			// new batches should not trigger a retry before the backoff
+			wait = nil
+			retry = time.After(backoff)
+		} else {
+			backoff = 0
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-wait:
+		case <-retry:
+		}
+	}
+}
+
+// flush exports the pending batches one by one in order. It stops at the
+// first batch that fails to export, unless the exporter says that it can
+// never be exported, in which case the batch is dropped.
+func (b *Buffer) flush(export exporter.ExportFunc) error {
+	for {
+		b.mu.Lock()
+		b.prune(time.Now())
+		if len(b.segs) == 0 {
+			b.mu.Unlock()
+			return nil
+		}
+
+		seq := b.segs[0].seq
+		b.mu.Unlock()
+
+		batch, err := b.read(seq)
+		if errors.Is(err, os.ErrNotExist) {
+			// dropped while being read.
+			continue
+		} else if errors.Is(err, errCorruptSegment) {
+			// a corrupt segment can never be exported, so it is dropped
+			// instead of blocking the rest of the batches.
+			b.ctx.Logger().
+				WithError(err).
+				WithField("buffer", b.name).
+				Warnln("dropping corrupt buffer segment")
+			batch = nil
+		} else if err != nil {
+			return err
+		}
+
+		metrics := make([]checker.Metric, len(batch))
+		for i := range batch {
+			metrics[i] = batch[i]
+		}
+
+		if len(metrics) > 0 {
+			if err := export(b.ctx, metrics); err != nil {
+				if !exporter.IsPermanent(err) {
+					return err
+				}
+
+				b.ctx.Logger().
+					WithError(err).
+					WithField("buffer", b.name).
+					WithField("metrics", len(metrics)).
+					Errorln("dropping metrics rejected by the exporter")
+			}
+		}
+
+		b.mu.Lock()
+		err = b.removeSegment(seq)
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Getter wraps the getter of the exporter so that the metrics still waiting
+// in the buffer are also returned. This keeps the status page without gaps
+// while the metrics backend is unavailable.
+//
+// If the getter fails while there are batches waiting to be exported, the
+// backend is most likely down, so only the metrics in the buffer are
+// returned instead of the error.
+func (b *Buffer) Getter(get exporter.GetterFunc) exporter.GetterFunc {
+	return func(
+		ctx context.Context,
+		duration time.Duration,
+		checkIDs ...string,
+	) (map[string][]checker.Metric, error) {
+		metrics, err := get(ctx, duration, checkIDs...)
+		if err != nil {
+			if b.Len() == 0 {
+				return nil, err
+			}
+
+			b.ctx.Logger().
+				WithError(err).
+				WithField("buffer", b.name).
+				Warnln("serving metrics from the buffer")
+			metrics = nil
+		}
+
+		pending, err := b.pending(time.Now().Add(-duration), checkIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if metrics == nil {
+			metrics = map[string][]checker.Metric{}
+		}
+
+		for id, ms := range pending {
+			merged := append(metrics[id], ms...)
+			sort.SliceStable(merged, func(i, j int) bool {
+				return merged[i].GetStartTime().After(merged[j].GetStartTime())
+			})
+			metrics[id] = merged
+		}
+
+		return metrics, nil
+	}
+}
+
+// Aggregator wraps the aggregator of the exporter so that the metrics in the
+// buffer are aggregated along with the exported ones. Like `Getter`, only the
+// metrics in the buffer are aggregated if the aggregator fails while there
+// are batches waiting to be exported.
+func (b *Buffer) Aggregator(aggregate exporter.AggregatorFunc) exporter.AggregatorFunc {
+	return func(
+		ctx context.Context,
+		duration time.Duration,
+		buckets int,
+		checkIDs ...string,
+	) (map[string][]exporter.Aggregate, error) {
+		aggregates, err := aggregate(ctx, duration, buckets, checkIDs...)
+		if err != nil {
+			if b.Len() == 0 {
+				return nil, err
+			}
+
+			b.ctx.Logger().
+				WithError(err).
+				WithField("buffer", b.name).
+				Warnln("serving aggregates from the buffer")
+			aggregates = nil
+		}
+
+		start, width := exporter.BucketStart(duration, buckets)
+		pending, err := b.pending(start, checkIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		return exporter.MergeAggregates(aggregates, exporter.AggregateMetrics(pending, start, width)), nil
+	}
+}
+
+// pending returns the metrics in the buffer for the checks that started
+// after the given time. Only the segments with metrics of the checks after
+// the time are read, and they are read without holding the lock so that the
+// buffer is not blocked meanwhile.
+func (b *Buffer) pending(after time.Time, checkIDs []string) (map[string][]checker.Metric, error) {
+	ids := make(map[string]struct{}, len(checkIDs))
+	for _, id := range checkIDs {
+		ids[id] = struct{}{}
+	}
+
+	b.mu.Lock()
+	var seqs []uint64
+	for _, seg := range b.segs {
+		if seg.latest.Before(after) {
+			continue
+		}
+
+		for id := range ids {
+			if _, ok := seg.checks[id]; ok {
+				seqs = append(seqs, seg.seq)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	metrics := map[string][]checker.Metric{}
+	for _, seq := range seqs {
+		batch, err := b.read(seq)
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, errCorruptSegment) {
+			// exported or dropped since the segments were listed.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, m := range batch {
+			if _, ok := ids[m.CheckID]; !ok || m.StartTime.Before(after) {
+				continue
+			}
+
+			metrics[m.CheckID] = append(metrics[m.CheckID], m)
+		}
+	}
+
+	return metrics, nil
+}
+
+// path returns the path of the file of the segment.
+func (b *Buffer) path(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+// write stores the segment. The segment is first written into a temporary
+// file which is then renamed so that a segment is never partially written.
+// It should be called with the lock held.
+func (b *Buffer) write(seq uint64, data []byte) error {
+	if b.dir == "" {
+		b.mem[seq] = data
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile(b.dir, "segment.*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create buffer segment: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("cannot write buffer segment: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("cannot write buffer segment: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write buffer segment: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), b.path(seq)); err != nil {
+		return fmt.Errorf("cannot write buffer segment: %w", err)
+	}
+
+	return nil
+}
+
+// read loads the metrics of the segment. It should be called without the
+// lock held. If the segment was removed, the error wraps `os.ErrNotExist`.
+func (b *Buffer) read(seq uint64) ([]*config.Metric, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if b.dir == "" {
+		b.mu.Lock()
+		d, ok := b.mem[seq]
+		b.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("cannot read buffer segment %d: %w", seq, os.ErrNotExist)
+		}
+
+		data = d
+	} else {
+		data, err = ioutil.ReadFile(b.path(seq))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read buffer segment: %w", err)
+		}
+	}
+
+	var batch []*config.Metric
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("%w %d: %v", errCorruptSegment, seq, err)
+	}
+
+	return batch, nil
+}
+
+// removeSegment deletes the segment and removes it from the index, if it is
+// still there. It should be called with the lock held.
+func (b *Buffer) removeSegment(seq uint64) error {
+	for i, seg := range b.segs {
+		if seg.seq != seq {
+			continue
+		}
+
+		if err := b.remove(seq); err != nil {
+			return err
+		}
+
+		b.size -= seg.size
+		b.segs = append(b.segs[:i], b.segs[i+1:]...)
+		return nil
+	}
+
+	return nil
+}
+
+// remove deletes the segment.
+func (b *Buffer) remove(seq uint64) error {
+	if b.dir == "" {
+		delete(b.mem, seq)
+		return nil
+	}
+
+	if err := os.Remove(b.path(seq)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove buffer segment: %w", err)
+	}
+
+	return nil
+}
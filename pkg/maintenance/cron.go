@@ -0,0 +1,228 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronYears is the number of years searched for the next time a cron
+// expression matches, after which it is considered to never match, like
+// "0 0 30 2 *".
+const maxCronYears = 5
+
+// cronMacros are the shorthands for common cron expressions.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField is the range and names of a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// 7 is also Sunday and is folded into 0 after parsing.
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// cron is a recurrence described by a cron expression with the fields
+// minute, hour, day of month, month and day of week. Each field is a set of
+// bits, one for each value that matches.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// a day matches either of the day of month and the day of week when
+	// both are restricted, as in cron.
+	domStar, dowStar bool
+
+	loc *time.Location
+}
+
+// parseCron parses the cron expression for the times in the location.
+func parseCron(expr string, loc *time.Location) (*cron, error) {
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields; got %d", len(fields))
+	}
+
+	c := &cron{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+		loc:     loc,
+	}
+
+	var err error
+	for i, f := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&c.minute, cronMinute},
+		{&c.hour, cronHour},
+		{&c.dom, cronDom},
+		{&c.month, cronMonth},
+		{&c.dow, cronDow},
+	} {
+		*f.bits, err = parseCronField(fields[i], f.field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	return c, nil
+}
+
+// parseCronField parses a field which is a comma separated list of values,
+// ranges like "1-5" or "*", each with an optional step like "*/15".
+func parseCronField(s string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s %q", field.name, part)
+			}
+			part = part[:i]
+		}
+
+		lo, hi := field.min, field.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], field); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], field); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s %q", field.name, part)
+			}
+		default:
+			var err error
+			if lo, err = parseCronValue(part, field); err != nil {
+				return 0, err
+			}
+			// a single value with a step, like "5/15", runs till the end.
+			hi = lo
+			if step > 1 {
+				hi = field.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a number or a name of the field.
+func parseCronValue(s string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("invalid %s %q", field.name, s)
+	}
+
+	return v, nil
+}
+
+// has tells if the value is set in the bits.
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// matchesDay tells if the day of t matches the expression.
+func (c *cron) matchesDay(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+
+	if !c.domStar && !c.dowStar {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// next returns the first time after t that matches the expression. It is
+// zero if no time matches in the next few years.
+func (c *cron) next(t time.Time) time.Time {
+	t = t.In(c.loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, c.loc).Add(time.Minute)
+
+	limit := t.Year() + maxCronYears
+	for t.Year() <= limit {
+		y, m, d := t.Date()
+
+		if !has(c.month, int(m)) {
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, c.loc)
+			continue
+		}
+
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) each(from, to time.Time, fn func(time.Time) bool) {
+	// next is strictly after the time, so that from is matched as well.
+	t := c.next(from.Add(-1 * time.Nanosecond))
+	for !t.IsZero() && t.Before(to) {
+		if !fn(t) {
+			return
+		}
+
+		t = c.next(t)
+	}
+}
@@ -0,0 +1,3 @@
+// Package maintenance schedules the maintenance windows of checks, during
+// which alerts are not sent and the time is left out of the uptime.
+package maintenance
@@ -0,0 +1,231 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxOccurrences is the maximum number of occurrences returned at once so
+// that a schedule that recurs too often cannot hold up the caller.
+const maxOccurrences = 10000
+
+// Window is the configuration of a maintenance window.
+//
+// A window occurs once at its start unless it has a recurrence, which is
+// either a cron expression like "0 2 * * SUN" or a recurrence rule like
+// "RRULE:FREQ=WEEKLY;BYDAY=SU", in which case the start is the earliest time
+// it can occur at.
+type Window interface {
+	GetName() string            // Name of the maintenance.
+	GetDescription() string     // Description shown on the status page.
+	GetStart() time.Time        // Start of the first occurrence.
+	GetDuration() time.Duration // Length of each occurrence.
+	GetRecurrence() string      // Cron expression or recurrence rule.
+	GetUntil() time.Time        // No occurrence starts after this, if set.
+	GetTimezone() string        // Time zone the recurrence is in.
+	GetChecks() []string        // Checks under maintenance, all if empty.
+}
+
+// recurrence tells when a window recurs.
+type recurrence interface {
+	// each calls fn with the start of each occurrence from `from` till `to`
+	// in order, till it returns false.
+	each(from, to time.Time, fn func(time.Time) bool)
+}
+
+// once is the recurrence of a window that occurs once.
+type once time.Time
+
+func (o once) each(from, to time.Time, fn func(time.Time) bool) {
+	t := time.Time(o)
+	if !t.Before(from) && t.Before(to) {
+		fn(t)
+	}
+}
+
+// Schedule is the schedule of a maintenance window.
+type Schedule struct {
+	name        string
+	description string
+
+	start    time.Time
+	duration time.Duration
+	until    time.Time
+	checks   map[string]struct{}
+
+	recurrence recurrence
+}
+
+// Occurrence is a single occurrence of a maintenance window.
+type Occurrence struct {
+	*Schedule
+
+	Start time.Time
+	End   time.Time
+}
+
+// NewSchedule creates the schedule of the maintenance window.
+func NewSchedule(w Window) (*Schedule, error) {
+	if w.GetName() == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	if w.GetStart().IsZero() {
+		return nil, fmt.Errorf("start cannot be empty")
+	}
+
+	if w.GetDuration() <= 0 {
+		return nil, fmt.Errorf("duration should be > 0")
+	}
+
+	if !w.GetUntil().IsZero() && w.GetUntil().Before(w.GetStart()) {
+		return nil, fmt.Errorf("until cannot be before start")
+	}
+
+	loc := time.UTC
+	if tz := w.GetTimezone(); tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	s := &Schedule{
+		name:        w.GetName(),
+		description: w.GetDescription(),
+		start:       w.GetStart(),
+		duration:    w.GetDuration(),
+		until:       w.GetUntil(),
+		checks:      make(map[string]struct{}, len(w.GetChecks())),
+	}
+
+	for _, id := range w.GetChecks() {
+		s.checks[id] = struct{}{}
+	}
+
+	rec := strings.TrimSpace(w.GetRecurrence())
+	switch {
+	case rec == "":
+		s.recurrence = once(s.start)
+
+	case strings.HasPrefix(strings.ToUpper(rec), rrulePrefix), strings.Contains(strings.ToUpper(rec), "FREQ="):
+		r, err := parseRRule(rec, s.start.In(loc))
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence rule: %w", err)
+		}
+		s.recurrence = r
+
+	default:
+		c, err := parseCron(rec, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+		s.recurrence = c
+	}
+
+	return s, nil
+}
+
+// Name returns the name of the maintenance.
+func (s *Schedule) Name() string {
+	return s.name
+}
+
+// Description returns the description of the maintenance.
+func (s *Schedule) Description() string {
+	return s.description
+}
+
+// Checks returns the IDs of the checks under maintenance. It is empty if
+// all the checks are.
+func (s *Schedule) Checks() []string {
+	checks := make([]string, 0, len(s.checks))
+	for id := range s.checks {
+		checks = append(checks, id)
+	}
+
+	return checks
+}
+
+// Applies tells if the check is under the maintenance.
+func (s *Schedule) Applies(checkID string) bool {
+	if len(s.checks) == 0 {
+		return true
+	}
+
+	_, ok := s.checks[checkID]
+	return ok
+}
+
+// Occurrences returns the occurrences that overlap with the time from `from`
+// till `to`, in the order of their start.
+func (s *Schedule) Occurrences(from, to time.Time) []Occurrence {
+	// occurrences that start a duration before `from` still overlap with it.
+	from = from.Add(-1 * s.duration).Add(time.Nanosecond)
+	if from.Before(s.start) {
+		from = s.start
+	}
+
+	if !s.until.IsZero() && s.until.Before(to) {
+		to = s.until.Add(time.Nanosecond)
+	}
+
+	var occurrences []Occurrence
+	s.recurrence.each(from, to, func(t time.Time) bool {
+		occurrences = append(occurrences, Occurrence{
+			Schedule: s,
+			Start:    t,
+			End:      t.Add(s.duration),
+		})
+		return len(occurrences) < maxOccurrences
+	})
+
+	return occurrences
+}
+
+// Active returns the occurrence at the time, if any.
+func (s *Schedule) Active(t time.Time) (Occurrence, bool) {
+	occurrences := s.Occurrences(t, t.Add(time.Nanosecond))
+	if len(occurrences) == 0 {
+		return Occurrence{}, false
+	}
+
+	return occurrences[len(occurrences)-1], true
+}
+
+// Schedules are the schedules of multiple maintenance windows.
+type Schedules []*Schedule
+
+// Active returns the occurrence of a maintenance the check is under at the
+// time, if any.
+func (ss Schedules) Active(checkID string, t time.Time) (Occurrence, bool) {
+	for _, s := range ss {
+		if !s.Applies(checkID) {
+			continue
+		}
+
+		if o, ok := s.Active(t); ok {
+			return o, true
+		}
+	}
+
+	return Occurrence{}, false
+}
+
+// Occurrences returns the occurrences of the maintenance windows that apply
+// to the check and overlap with the time from `from` till `to`. Pass an
+// empty check ID for the occurrences of all the windows.
+func (ss Schedules) Occurrences(checkID string, from, to time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, s := range ss {
+		if checkID != "" && !s.Applies(checkID) {
+			continue
+		}
+
+		occurrences = append(occurrences, s.Occurrences(from, to)...)
+	}
+
+	return occurrences
+}
@@ -0,0 +1,369 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrulePrefix is the optional prefix of a recurrence rule.
+const rrulePrefix = "RRULE:"
+
+// Frequencies of a recurrence rule.
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+	freqYearly  = "YEARLY"
+)
+
+// rruleWeekdays are the days of the week in a recurrence rule.
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// weekday is a day of the week in a recurrence rule, optionally the nth
+// such day of the month, like "2TU" or "-1FR" for the last Friday.
+type weekday struct {
+	n   int // 0 if every such day.
+	day time.Weekday
+}
+
+// rrule is a recurrence described by a recurrence rule from RFC 5545. The
+// rule parts FREQ (DAILY, WEEKLY, MONTHLY and YEARLY), INTERVAL, COUNT,
+// UNTIL, BYMONTH, BYMONTHDAY, BYDAY, BYHOUR and BYMINUTE are supported,
+// along with the week starting on Monday.
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+
+	byMonth    []int
+	byMonthDay []int
+	byDay      []weekday
+	byHour     []int
+	byMinute   []int
+
+	dtstart time.Time
+}
+
+// parseRRule parses the recurrence rule starting at dtstart.
+func parseRRule(s string, dtstart time.Time) (*rrule, error) {
+	if strings.HasPrefix(strings.ToUpper(s), rrulePrefix) {
+		s = s[len(rrulePrefix):]
+	}
+
+	r := &rrule{interval: 1, dtstart: dtstart}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case freqDaily, freqWeekly, freqMonthly, freqYearly:
+				r.freq = value
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval <= 0 {
+				err = fmt.Errorf("should be > 0")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count <= 0 {
+				err = fmt.Errorf("should be > 0")
+			}
+		case "UNTIL":
+			r.until, err = parseRRuleTime(value, dtstart.Location())
+		case "BYMONTH":
+			r.byMonth, err = parseInts(value, 1, 12, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(value, 1, 31, true)
+		case "BYHOUR":
+			r.byHour, err = parseInts(value, 0, 23, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseInts(value, 0, 59, false)
+		case "BYDAY":
+			r.byDay, err = parseWeekdays(value)
+		case "WKST":
+			if value != "MO" {
+				err = fmt.Errorf("only weeks starting on MO are supported")
+			}
+		default:
+			err = fmt.Errorf("unsupported rule part")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+
+	r.setDefaults()
+	return r, nil
+}
+
+// setDefaults sets the rule parts that are not specified from dtstart, so
+// that a weekly rule recurs on the day of the week dtstart is on, and so on.
+func (r *rrule) setDefaults() {
+	if len(r.byHour) == 0 {
+		r.byHour = []int{r.dtstart.Hour()}
+	}
+
+	if len(r.byMinute) == 0 {
+		r.byMinute = []int{r.dtstart.Minute()}
+	}
+
+	noDay := len(r.byDay) == 0 && len(r.byMonthDay) == 0
+	switch r.freq {
+	case freqWeekly:
+		if len(r.byDay) == 0 {
+			r.byDay = []weekday{{day: r.dtstart.Weekday()}}
+		}
+	case freqMonthly:
+		if noDay {
+			r.byMonthDay = []int{r.dtstart.Day()}
+		}
+	case freqYearly:
+		if len(r.byMonth) == 0 && noDay {
+			r.byMonth = []int{int(r.dtstart.Month())}
+		}
+		if noDay {
+			r.byMonthDay = []int{r.dtstart.Day()}
+		}
+	}
+
+	sort.Ints(r.byHour)
+	sort.Ints(r.byMinute)
+}
+
+// parseRRuleTime parses UNTIL which is either a date or a date-time, in UTC
+// if it ends with "Z".
+func parseRRuleTime(s string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(s, "Z") {
+		return time.Parse("20060102T150405Z", s)
+	}
+
+	if len(s) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", s, loc)
+		// a date includes the whole day.
+		return t.Add(24*time.Hour - time.Nanosecond), err
+	}
+
+	return time.ParseInLocation("20060102T150405", s, loc)
+}
+
+// parseInts parses the comma separated list of integers within the range,
+// or negative within the range if allowed.
+func parseInts(s string, min, max int, negative bool) ([]int, error) {
+	var ints []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if negative && n < 0 {
+			if -n < min || -n > max {
+				return nil, fmt.Errorf("%d out of range", n)
+			}
+		} else if n < min || n > max {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+
+		ints = append(ints, n)
+	}
+
+	return ints, nil
+}
+
+// parseWeekdays parses the comma separated list of the days of the week.
+func parseWeekdays(s string) ([]weekday, error) {
+	var days []weekday
+	for _, v := range strings.Split(s, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("invalid day %q", v)
+		}
+
+		day, ok := rruleWeekdays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", v)
+		}
+
+		var n int
+		if prefix := v[:len(v)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid day %q", v)
+			}
+		}
+
+		days = append(days, weekday{n: n, day: day})
+	}
+
+	return days, nil
+}
+
+// inPeriod tells if the day falls in a period that recurs, i.e., a multiple
+// of the interval of periods since dtstart.
+func (r *rrule) inPeriod(day time.Time) bool {
+	if r.interval == 1 {
+		return true
+	}
+
+	start := r.dtstart
+	var periods int
+	switch r.freq {
+	case freqDaily:
+		periods = daysBetween(start, day)
+	case freqWeekly:
+		// weeks start on Monday.
+		periods = daysBetween(weekStart(start), weekStart(day)) / 7
+	case freqMonthly:
+		periods = (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+	case freqYearly:
+		periods = day.Year() - start.Year()
+	}
+
+	return periods%r.interval == 0
+}
+
+// matchesDay tells if the occurrences can be on the day.
+func (r *rrule) matchesDay(day time.Time) bool {
+	if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(day.Month())) {
+		return false
+	}
+
+	lastDay := daysIn(day)
+	if len(r.byMonthDay) > 0 {
+		matched := false
+		for _, d := range r.byMonthDay {
+			if d == day.Day() || (d < 0 && lastDay+d+1 == day.Day()) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.byDay) > 0 {
+		matched := false
+		for _, wd := range r.byDay {
+			if wd.day != day.Weekday() {
+				continue
+			}
+
+			// the nth such day of the month, counting from the end if negative.
+			if wd.n == 0 ||
+				(wd.n > 0 && (day.Day()-1)/7+1 == wd.n) ||
+				(wd.n < 0 && (lastDay-day.Day())/7+1 == -wd.n) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *rrule) each(from, to time.Time, fn func(time.Time) bool) {
+	loc := r.dtstart.Location()
+
+	// the occurrences are counted from dtstart when the count is limited,
+	// otherwise the days before `from` can be skipped.
+	day := r.dtstart
+	if r.count == 0 && from.After(day) {
+		day = from.In(loc)
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+
+	var count int
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !r.inPeriod(day) || !r.matchesDay(day) {
+			continue
+		}
+
+		for _, h := range r.byHour {
+			for _, m := range r.byMinute {
+				t := time.Date(day.Year(), day.Month(), day.Day(), h, m, r.dtstart.Second(), 0, loc)
+				if t.Before(r.dtstart) {
+					continue
+				}
+
+				if !r.until.IsZero() && t.After(r.until) {
+					return
+				}
+
+				count++
+				if r.count > 0 && count > r.count {
+					return
+				}
+
+				if t.Before(from) {
+					continue
+				}
+
+				if !t.Before(to) || !fn(t) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// daysBetween returns the number of days from a till b, ignoring the time.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / (24 * time.Hour))
+}
+
+// weekStart returns the Monday of the week of t.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+// daysIn returns the number of days in the month of t.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// containsInt tells if the slice contains v.
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+
+	return false
+}
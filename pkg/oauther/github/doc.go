@@ -0,0 +1,2 @@
+// Package github implements the GitHub oauther.
+package github
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sdslabs/pinger/pkg/oauther"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// providerName is the name of the provider.
+const providerName = "github"
+
+const (
+	authURL   = "https://github.com/login/oauth/authorize"
+	tokenURL  = "https://github.com/login/oauth/access_token" // nolint:gosec
+	userURL   = "https://api.github.com/user"
+	emailsURL = "https://api.github.com/user/emails"
+)
+
+// defaultScopes are the scopes requested if none are configured.
+var defaultScopes = []string{"read:user", "user:email"}
+
+func init() {
+	oauther.Register(providerName, func() oauther.Oauther { return new(Oauther) })
+}
+
+// user is the user returned from the API.
+type user struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// email is an email of the user returned from the API.
+type email struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// provider is the provider with the default scopes.
+type provider struct {
+	oauther.Provider
+	scopes []string
+}
+
+// GetScopes returns the scopes.
+func (p *provider) GetScopes() []string {
+	return p.scopes
+}
+
+// Oauther authenticates users through GitHub.
+type Oauther struct {
+	provider oauther.Provider
+}
+
+// Provision initializes required fields for o's execution.
+func (o *Oauther) Provision(_ *appcontext.Context, p oauther.Provider) error {
+	if p.GetClientID() == "" || p.GetClientSecret() == "" {
+		return fmt.Errorf("client ID and secret cannot be empty")
+	}
+
+	scopes := p.GetScopes()
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	o.provider = &provider{Provider: p, scopes: scopes}
+	return nil
+}
+
+// AuthURL returns the URL where the user authorizes the application.
+func (o *Oauther) AuthURL(session *oauther.Session) string {
+	return oauther.AuthCodeURL(authURL, o.provider, session, nil)
+}
+
+// FetchUser exchanges the code and fetches the user. The email of the user is
+// their primary verified email, since the public email may be unset or
+// unverified.
+func (o *Oauther) FetchUser(ctx context.Context, code string, session *oauther.Session) (json.RawMessage, int, error) {
+	token, sc, err := oauther.ExchangeCode(ctx, tokenURL, o.provider, code, session)
+	if err != nil {
+		return nil, sc, err
+	}
+
+	var u user
+	if sc, err = oauther.GetJSON(ctx, userURL, token.AccessToken, &u); err != nil {
+		return nil, sc, fmt.Errorf("cannot fetch user: %w", err)
+	}
+
+	var emails []email
+	if sc, err = oauther.GetJSON(ctx, emailsURL, token.AccessToken, &emails); err != nil {
+		return nil, sc, fmt.Errorf("cannot fetch emails: %w", err)
+	}
+
+	info := oauther.User{Name: u.Name}
+	if info.Name == "" {
+		info.Name = u.Login
+	}
+
+	for i := range emails {
+		if emails[i].Primary && emails[i].Verified {
+			info.Email = emails[i].Email
+			break
+		}
+	}
+
+	if info.Email == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("user does not have a primary verified email")
+	}
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("cannot marshal user: %w", err)
+	}
+
+	return raw, 0, nil
+}
+
+// Interface guard.
+var _ oauther.Oauther = (*Oauther)(nil)
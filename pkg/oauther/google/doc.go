@@ -0,0 +1,3 @@
+// Package google implements the Google oauther, which is an OpenID Connect
+// provider.
+package google
@@ -0,0 +1,16 @@
+package google
+
+import (
+	"github.com/sdslabs/pinger/pkg/oauther"
+	"github.com/sdslabs/pinger/pkg/oauther/oidc"
+)
+
+// providerName is the name of the provider.
+const providerName = "google"
+
+// issuer is the issuer of the ID tokens of Google.
+const issuer = "https://accounts.google.com"
+
+func init() {
+	oauther.Register(providerName, func() oauther.Oauther { return oidc.New(issuer) })
+}
@@ -0,0 +1,156 @@
+package oauther
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// requestTimeout is the timeout of each request to the provider.
+const requestTimeout = 10 * time.Second
+
+// maxResponseSize is the maximum size of the response read from the
+// provider.
+const maxResponseSize = 1 << 20 // 1 MiB
+
+// client is the HTTP client used for requests to the providers.
+var client = &http.Client{Timeout: requestTimeout}
+
+// Token is the response of the provider on exchanging the code.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// AuthCodeURL returns the URL to authenticate the application with the
+// authorization code flow, using PKCE, at the endpoint of the provider.
+// Extra parameters, if any, are added to the URL.
+func AuthCodeURL(endpoint string, provider Provider, session *Session, extra url.Values) string {
+	params := url.Values{}
+	for k, v := range extra {
+		params[k] = v
+	}
+
+	params.Set("response_type", "code")
+	params.Set("client_id", provider.GetClientID())
+	params.Set("redirect_uri", provider.GetRedirectURL())
+	params.Set("scope", strings.Join(provider.GetScopes(), " "))
+	params.Set("state", session.State)
+	params.Set("code_challenge", session.Challenge())
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+
+	return endpoint + sep + params.Encode()
+}
+
+// ExchangeCode exchanges the code, along with the PKCE verifier of the
+// session, for a token at the endpoint of the provider. Like `FetchUser`, it
+// returns a non-zero status code with the error.
+func ExchangeCode(
+	ctx context.Context,
+	endpoint string,
+	provider Provider,
+	code string,
+	session *Session,
+) (*Token, int, error) {
+	if code == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("code cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.GetRedirectURL())
+	form.Set("client_id", provider.GetClientID())
+	form.Set("client_secret", provider.GetClientSecret())
+	form.Set("code_verifier", session.Verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("cannot create token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token Token
+	sc, err := doJSON(req, &token)
+	if token.Error != "" {
+		// The provider rejected the code, or the verifier, so the request to
+		// redirect was bad.
+		if token.ErrorDescription != "" {
+			token.Error += ": " + token.ErrorDescription
+		}
+
+		return nil, http.StatusBadRequest, fmt.Errorf("cannot exchange code: %s", token.Error)
+	}
+
+	if err != nil {
+		return nil, sc, fmt.Errorf("cannot exchange code: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, http.StatusInternalServerError, fmt.Errorf("cannot exchange code: no access token in response")
+	}
+
+	return &token, 0, nil
+}
+
+// GetJSON gets the JSON at the URL into v. The access token, if not empty,
+// is sent as the bearer token. Like `FetchUser`, it returns a non-zero status
+// code with the error.
+func GetJSON(ctx context.Context, u, accessToken string, v interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return doJSON(req, v)
+}
+
+// doJSON sends the request and decodes the JSON response into v. The
+// response is decoded even if the status is not OK, so that the errors sent
+// by the provider can be read.
+func doJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("cannot send request: %w", err)
+	}
+
+	defer resp.Body.Close() // nolint:errcheck
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("cannot read response: %w", err)
+	}
+
+	decodeErr := json.Unmarshal(body, v)
+
+	if resp.StatusCode != http.StatusOK {
+		return http.StatusInternalServerError, fmt.Errorf("%s: unexpected status: %s", req.URL.Host, resp.Status)
+	}
+
+	if decodeErr != nil {
+		return http.StatusInternalServerError, fmt.Errorf("cannot parse response: %w", decodeErr)
+	}
+
+	return 0, nil
+}
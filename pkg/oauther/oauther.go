@@ -49,15 +49,16 @@ type Oauther interface {
 	Provision(*appcontext.Context, Provider) error
 
 	// AuthURL returns the URL where the user authenticates the application.
-	// It takes the state parameter and generates the authorization URL with
-	// other required parameters.
-	AuthURL(state string) string
-
-	// FetchUser fetches the user information from the provider API. The info
-	// should be in valid JSON format. The method should return a non-zero status
-	// code in case of an error to specify what kind of error it was during the
-	// API call, example, 400 is bad request and 500 is an internal server error.
-	FetchUser(ctx context.Context, code string) (json.RawMessage, int, error)
+	// It takes the login session and generates the authorization URL with its
+	// state, PKCE challenge and nonce, along with other required parameters.
+	AuthURL(session *Session) string
+
+	// FetchUser exchanges the code for the session and fetches the user
+	// information from the provider API. The info should be a `User` in valid
+	// JSON format. The method should return a non-zero status code in case of
+	// an error to specify what kind of error it was during the API call,
+	// example, 400 is bad request and 500 is an internal server error.
+	FetchUser(ctx context.Context, code string, session *Session) (json.RawMessage, int, error)
 }
 
 // Initialize adds the required routes like the login and redirect routes to
@@ -75,27 +76,95 @@ func Initialize(ctx *appcontext.Context, provider Provider, opts *Opts) error {
 	}
 
 	groupRoute := fmt.Sprintf("/%s", provider.GetProvider())
+	cookieName := fmt.Sprintf("%s_%s", stateCookiePrefix, provider.GetProvider())
+	store := newStateStore()
+
 	oautherGroup := opts.Router.Group(groupRoute)
-	oautherGroup.GET("/login", loginRoute(oauther, opts))
-	oautherGroup.GET("/redirect", redirectRoute(oauther, opts))
+	oautherGroup.GET("/login", loginRoute(oauther, store, cookieName, opts))
+	oautherGroup.GET("/redirect", redirectRoute(oauther, store, cookieName, opts))
 
 	return nil
 }
 
-// loginRoute returns the login route for the provider.
-func loginRoute(oauther Oauther, opts *Opts) func(*gin.Context) {
+// stateCookiePrefix is the prefix of the name of the cookie which binds the
+// login to the browser. The name of the provider is added to it.
+const stateCookiePrefix = "pinger_oauth"
+
+// setStateCookie sets the cookie which binds the login to the browser. An
+// empty value deletes the cookie.
+func setStateCookie(ctx *gin.Context, name, value string, opts *Opts) {
+	maxAge := int(stateTTL.Seconds())
+	if value == "" {
+		maxAge = -1
+	}
+
+	sameSite := http.SameSiteLaxMode
+	if opts.SecureCookie {
+		// The client may be on a different site than the server, which is
+		// only allowed for secure cookies.
+		sameSite = http.SameSiteNoneMode
+	}
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   opts.SecureCookie,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
+
+// loginRoute returns the login route for the provider. It starts a login
+// session, which the redirect has to come back with, from the same browser,
+// within the expiry of the state.
+func loginRoute(oauther Oauther, store *stateStore, cookieName string, opts *Opts) func(*gin.Context) {
 	return func(ctx *gin.Context) {
-		state := randomToken()
-		authURL := oauther.AuthURL(state)
+		session := &Session{
+			State:    randomToken(),
+			Verifier: randomToken(),
+			Nonce:    randomToken(),
+		}
+		binding := randomToken()
+
+		if session.State == "" || session.Verifier == "" || session.Nonce == "" || binding == "" {
+			err := fmt.Errorf("cannot generate login session")
+			ctx.PureJSON(http.StatusInternalServerError, opts.ErrorResponse(err))
+			return
+		}
+
+		if err := store.add(session, binding); err != nil {
+			ctx.PureJSON(http.StatusServiceUnavailable, opts.ErrorResponse(err))
+			return
+		}
+
+		setStateCookie(ctx, cookieName, binding, opts)
+		authURL := oauther.AuthURL(session)
 		ctx.PureJSON(http.StatusOK, opts.LoginResponse(authURL))
 	}
 }
 
-// redirectRoute returns the redirect route for the provider.
-func redirectRoute(oauther Oauther, opts *Opts) func(*gin.Context) {
+// redirectRoute returns the redirect route for the provider. The redirect is
+// rejected if its state does not match a login session started from the
+// same browser, has expired, or has already been used.
+func redirectRoute(oauther Oauther, store *stateStore, cookieName string, opts *Opts) func(*gin.Context) {
 	return func(ctx *gin.Context) {
+		binding, err := ctx.Cookie(cookieName)
+		if err != nil {
+			binding = ""
+		}
+
+		session, err := store.take(ctx.Query("state"), binding)
+		if err != nil {
+			ctx.PureJSON(http.StatusBadRequest, opts.ErrorResponse(err))
+			return
+		}
+
+		setStateCookie(ctx, cookieName, "", opts)
+
 		code := ctx.Query("code")
-		user, sc, err := oauther.FetchUser(ctx.Request.Context(), code)
+		user, sc, err := oauther.FetchUser(ctx.Request.Context(), code, session)
 		if err != nil {
 			ctx.PureJSON(sc, opts.ErrorResponse(err))
 			return
@@ -111,11 +180,11 @@ func redirectRoute(oauther Oauther, opts *Opts) func(*gin.Context) {
 	}
 }
 
-// randomToken generates a random string.
+// randomToken generates a random string that is safe to use in URLs.
 func randomToken() string {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
 		return ""
 	}
-	return base64.StdEncoding.EncodeToString(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
@@ -0,0 +1,4 @@
+// Package oidc implements the oauther for any OpenID Connect provider. The
+// endpoints of the provider are discovered from its issuer and the user is
+// read from the verified ID token.
+package oidc
@@ -0,0 +1,177 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/oauther"
+)
+
+// minRefreshInterval is the minimum interval between fetching the keys of
+// the provider again, when a token is signed with an unknown key.
+const minRefreshInterval = time.Minute
+
+// jwk is a JSON web key of the provider.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey parses the public key from the JSON web key.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes the base64url encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// key is a parsed key of the provider.
+type key struct {
+	alg string
+	pub crypto.PublicKey
+}
+
+// keySet is the set of keys of the provider which sign the ID tokens. The
+// keys are fetched when first needed and again when a token is signed with
+// an unknown key, since providers rotate their keys.
+type keySet struct {
+	uri string
+
+	mu        sync.Mutex
+	keys      map[string]key
+	fetchedAt time.Time
+}
+
+// get returns the key with the ID.
+func (s *keySet) get(ctx context.Context, kid string) (key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+
+	if time.Since(s.fetchedAt) < minRefreshInterval {
+		return key{}, fmt.Errorf("unknown key: %q", kid)
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		return key{}, err
+	}
+
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+
+	return key{}, fmt.Errorf("unknown key: %q", kid)
+}
+
+// fetch fetches the keys of the provider. Keys that cannot be parsed, or are
+// not for signatures, are skipped.
+func (s *keySet) fetch(ctx context.Context) error {
+	var resp struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if _, err := oauther.GetJSON(ctx, s.uri, "", &resp); err != nil {
+		return fmt.Errorf("cannot fetch keys: %w", err)
+	}
+
+	keys := make(map[string]key, len(resp.Keys))
+	for i := range resp.Keys {
+		k := &resp.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = key{alg: k.Alg, pub: pub}
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
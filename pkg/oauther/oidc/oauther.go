@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sdslabs/pinger/pkg/oauther"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+// providerName is the name of the generic provider.
+const providerName = "oidc"
+
+// discoveryPath is the path, relative to the issuer, of the configuration of
+// the provider.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// discoveryTimeout is the timeout to discover the configuration.
+const discoveryTimeout = 30 * time.Second
+
+// clockSkew is the difference allowed between the clocks of the provider and
+// the app while validating the times in the ID token.
+const clockSkew = time.Minute
+
+// defaultScopes are the scopes requested if none are configured.
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// signingMethods are the algorithms of the ID tokens that are accepted.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+func init() {
+	oauther.Register(providerName, func() oauther.Oauther { return New("") })
+}
+
+// discovery is the configuration of the provider.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// provider is the provider with the default scopes.
+type provider struct {
+	oauther.Provider
+	scopes []string
+}
+
+// GetScopes returns the scopes.
+func (p *provider) GetScopes() []string {
+	return p.scopes
+}
+
+// Oauther authenticates users through an OpenID Connect provider.
+type Oauther struct {
+	issuer   string
+	provider oauther.Provider
+	config   discovery
+	keys     *keySet
+}
+
+// New creates the oauther for the provider with the issuer. If the issuer is
+// empty, it is read from the configuration of the provider.
+func New(issuer string) *Oauther {
+	return &Oauther{issuer: issuer}
+}
+
+// Provision discovers the configuration of the provider.
+func (o *Oauther) Provision(ctx *appcontext.Context, p oauther.Provider) error {
+	if p.GetClientID() == "" || p.GetClientSecret() == "" {
+		return fmt.Errorf("client ID and secret cannot be empty")
+	}
+
+	if o.issuer == "" {
+		o.issuer = p.GetIssuer()
+	}
+
+	if o.issuer == "" {
+		return fmt.Errorf("issuer cannot be empty")
+	}
+
+	scopes := p.GetScopes()
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	o.provider = &provider{Provider: p, scopes: scopes}
+
+	discoverCtx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	discoveryURL := strings.TrimSuffix(o.issuer, "/") + discoveryPath
+	if _, err := oauther.GetJSON(discoverCtx, discoveryURL, "", &o.config); err != nil {
+		return fmt.Errorf("cannot discover provider: %w", err)
+	}
+
+	if o.config.Issuer != o.issuer {
+		return fmt.Errorf("issuer %q does not match the discovered issuer %q", o.issuer, o.config.Issuer)
+	}
+
+	if o.config.AuthorizationEndpoint == "" || o.config.TokenEndpoint == "" || o.config.JWKSURI == "" {
+		return fmt.Errorf("provider does not have the required endpoints")
+	}
+
+	o.keys = &keySet{uri: o.config.JWKSURI}
+	return nil
+}
+
+// AuthURL returns the URL where the user authenticates the application.
+func (o *Oauther) AuthURL(session *oauther.Session) string {
+	extra := url.Values{}
+	extra.Set("nonce", session.Nonce)
+	return oauther.AuthCodeURL(o.config.AuthorizationEndpoint, o.provider, session, extra)
+}
+
+// FetchUser exchanges the code and reads the user from the verified ID token.
+// If the token does not have the email of the user, or whether it is
+// verified, the user is fetched from the user info endpoint.
+func (o *Oauther) FetchUser(ctx context.Context, code string, session *oauther.Session) (json.RawMessage, int, error) {
+	token, sc, err := oauther.ExchangeCode(ctx, o.config.TokenEndpoint, o.provider, code, session)
+	if err != nil {
+		return nil, sc, err
+	}
+
+	if token.IDToken == "" {
+		return nil, http.StatusInternalServerError, fmt.Errorf("no ID token in response")
+	}
+
+	claims, err := o.verify(ctx, token.IDToken, session)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if (claims.Email == "" || !claims.EmailVerified.set) && o.config.UserinfoEndpoint != "" {
+		var info userClaims
+		if sc, err = oauther.GetJSON(ctx, o.config.UserinfoEndpoint, token.AccessToken, &info); err != nil {
+			return nil, sc, fmt.Errorf("cannot fetch user info: %w", err)
+		}
+
+		// The user info is only trusted for the user the ID token is for.
+		if info.Subject != claims.Subject {
+			return nil, http.StatusBadRequest, fmt.Errorf("user info is not for the user of the ID token")
+		}
+
+		claims.userClaims = info
+	}
+
+	if claims.Email == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("user does not have an email")
+	}
+
+	if !claims.EmailVerified.verified() {
+		return nil, http.StatusBadRequest, fmt.Errorf("email of the user is not verified")
+	}
+
+	user := oauther.User{Email: claims.Email, Name: claims.Name}
+	if user.Name == "" {
+		user.Name = claims.PreferredUsername
+	}
+
+	raw, err := json.Marshal(user)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("cannot marshal user: %w", err)
+	}
+
+	return raw, 0, nil
+}
+
+// verify verifies the signature and claims of the ID token for the session.
+func (o *Oauther) verify(ctx context.Context, raw string, session *oauther.Session) (*idClaims, error) {
+	var claims idClaims
+	parser := jwt.Parser{ValidMethods: signingMethods}
+	_, err := parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			kid = ""
+		}
+
+		k, err := o.keys.get(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if k.alg != "" && k.alg != t.Method.Alg() {
+			return nil, fmt.Errorf("key %q is not for %s", kid, t.Method.Alg())
+		}
+
+		return k.pub, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != o.config.Issuer {
+		return nil, fmt.Errorf("unexpected issuer: %q", claims.Issuer)
+	}
+
+	clientID := o.provider.GetClientID()
+	if !claims.Audience.contains(clientID) {
+		return nil, fmt.Errorf("token is not for the client")
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != clientID {
+		return nil, fmt.Errorf("token is not authorized for the client")
+	}
+
+	if claims.Nonce != session.Nonce {
+		return nil, fmt.Errorf("nonce does not match the session")
+	}
+
+	return &claims, nil
+}
+
+// userClaims are the claims about the user, in the ID token or the user
+// info.
+type userClaims struct {
+	Subject           string        `json:"sub"`
+	Email             string        `json:"email"`
+	EmailVerified     emailVerified `json:"email_verified"`
+	Name              string        `json:"name"`
+	PreferredUsername string        `json:"preferred_username"`
+}
+
+// idClaims are the claims in the ID token.
+type idClaims struct {
+	userClaims
+
+	Issuer          string   `json:"iss"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+}
+
+// Valid validates the times in the claims.
+func (c *idClaims) Valid() error {
+	now := time.Now()
+
+	if c.ExpiresAt == 0 {
+		return errors.New("token does not expire")
+	}
+
+	if now.Add(-clockSkew).After(time.Unix(c.ExpiresAt, 0)) {
+		return errors.New("token is expired")
+	}
+
+	if c.IssuedAt != 0 && now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("token is issued in the future")
+	}
+
+	return nil
+}
+
+// audience is the audience of the token, which is either a string or an
+// array of strings.
+type audience []string
+
+// UnmarshalJSON unmarshals the audience.
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("invalid audience: %w", err)
+	}
+
+	*a = list
+	return nil
+}
+
+// contains tells if the audience contains the client.
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emailVerified tells if the email is verified. Some providers send it as a
+// string instead of a boolean.
+type emailVerified struct {
+	set   bool
+	value bool
+}
+
+// UnmarshalJSON unmarshals the claim.
+func (e *emailVerified) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case bool:
+		e.set, e.value = true, v
+	case string:
+		e.set, e.value = true, v == "true"
+	}
+
+	return nil
+}
+
+// verified tells if the email is verified. Users are linked by their email,
+// so the email is not trusted unless the provider says it is verified.
+func (e emailVerified) verified() bool {
+	return e.set && e.value
+}
+
+// Interface guard.
+var _ oauther.Oauther = (*Oauther)(nil)
@@ -0,0 +1,104 @@
+package oauther
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+)
+
+// stateTTL is the time within which the user has to be redirected back after
+// starting to log in.
+const stateTTL = 10 * time.Minute
+
+// maxPendingLogins is the maximum number of logins that can be pending at a
+// time for a provider, so that the store cannot grow without bound.
+const maxPendingLogins = 10000
+
+// Errors returned while taking the session of a state.
+var (
+	errTooManyLogins = errors.New("too many pending logins, try again later")
+	errUnknownState  = errors.New("unknown or already used state")
+	errExpiredState  = errors.New("state has expired, log in again")
+	errStateMismatch = errors.New("state does not belong to this login")
+)
+
+// pendingLogin is a login which is waiting for the redirect from the
+// provider.
+type pendingLogin struct {
+	session   *Session
+	binding   [sha256.Size]byte
+	expiresAt time.Time
+}
+
+// stateStore stores the sessions of the pending logins by their state. Each
+// session is bound to the browser that started the login, through a random
+// value set in its cookie, and can only be taken once before it expires.
+type stateStore struct {
+	mu     sync.Mutex
+	logins map[string]pendingLogin
+}
+
+// newStateStore creates an empty state store.
+func newStateStore() *stateStore {
+	return &stateStore{logins: map[string]pendingLogin{}}
+}
+
+// add stores the session bound to the value of the cookie.
+func (s *stateStore) add(session *Session, binding string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.logins) >= maxPendingLogins {
+		s.prune(now)
+	}
+
+	if len(s.logins) >= maxPendingLogins {
+		return errTooManyLogins
+	}
+
+	s.logins[session.State] = pendingLogin{
+		session:   session,
+		binding:   sha256.Sum256([]byte(binding)),
+		expiresAt: now.Add(stateTTL),
+	}
+
+	return nil
+}
+
+// take removes and returns the session of the state if it has not expired
+// and is bound to the value of the cookie. Since the session is removed even
+// if it is rejected, a state can never be used twice.
+func (s *stateStore) take(state, binding string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login, ok := s.logins[state]
+	if !ok || state == "" {
+		return nil, errUnknownState
+	}
+
+	delete(s.logins, state)
+
+	if time.Now().After(login.expiresAt) {
+		return nil, errExpiredState
+	}
+
+	sum := sha256.Sum256([]byte(binding))
+	if binding == "" || subtle.ConstantTimeCompare(sum[:], login.binding[:]) != 1 {
+		return nil, errStateMismatch
+	}
+
+	return login.session, nil
+}
+
+// prune removes the expired logins.
+func (s *stateStore) prune(now time.Time) {
+	for state, login := range s.logins {
+		if now.After(login.expiresAt) {
+			delete(s.logins, state)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package oauther
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +16,36 @@ type Provider interface {
 	GetClientSecret() string // ClientSecret of provider
 	GetRedirectURL() string  // RedirectURL of provider
 	GetScopes() []string     // Scopes of provider
+	GetIssuer() string       // Issuer URL of OpenID Connect provider
+}
+
+// Session is the login session of a user. It binds the redirect from the
+// provider to the login that started it.
+type Session struct {
+	// State is sent to the provider while logging in and is returned back
+	// with the redirect.
+	State string
+
+	// Verifier is the PKCE code verifier. The challenge derived from it is
+	// sent while logging in and the verifier while exchanging the code.
+	Verifier string
+
+	// Nonce is sent to OpenID Connect providers while logging in and is
+	// returned back in the ID token.
+	Nonce string
+}
+
+// Challenge returns the S256 PKCE code challenge of the session.
+func (s *Session) Challenge() string {
+	sum := sha256.Sum256([]byte(s.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// User is the user information fetched from the provider. Oauthers should
+// return the user in this form from `FetchUser`.
+type User struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
 }
 
 // Opts configures the gin Router to enable authentication.
@@ -41,4 +73,9 @@ type Opts struct {
 
 	// ErrorResponse when an error is received.
 	ErrorResponse func(error) interface{}
+
+	// SecureCookie makes the cookie, which binds the login to the browser,
+	// secure. This is required when the client is on a different site than
+	// the server, which also needs to allow credentials in requests.
+	SecureCookie bool
 }
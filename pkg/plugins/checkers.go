@@ -6,6 +6,7 @@ import (
 	_ "github.com/sdslabs/pinger/pkg/checker/http"
 	_ "github.com/sdslabs/pinger/pkg/checker/icmp"
 	_ "github.com/sdslabs/pinger/pkg/checker/tcp"
+	_ "github.com/sdslabs/pinger/pkg/checker/tls"
 	_ "github.com/sdslabs/pinger/pkg/checker/udp"
 	_ "github.com/sdslabs/pinger/pkg/checker/ws"
 )
@@ -1,5 +1,5 @@
 /*
 Package plugins contains all the standard plugins which includes all the
-alerters, exporters, checkers, and oauthers.
+alerters, exporters, checkers, oauthers and state stores.
 */
 package plugins
@@ -2,8 +2,13 @@ package plugins
 
 import (
 	// Register all the metrics exporters here.
+	_ "github.com/sdslabs/pinger/pkg/exporter/file"
 	_ "github.com/sdslabs/pinger/pkg/exporter/influxdb"
 	_ "github.com/sdslabs/pinger/pkg/exporter/log"
+	_ "github.com/sdslabs/pinger/pkg/exporter/otlp"
+	_ "github.com/sdslabs/pinger/pkg/exporter/prometheus"
 	_ "github.com/sdslabs/pinger/pkg/exporter/questdb"
+	_ "github.com/sdslabs/pinger/pkg/exporter/remotewrite"
+	_ "github.com/sdslabs/pinger/pkg/exporter/sqlite"
 	_ "github.com/sdslabs/pinger/pkg/exporter/timescale"
 )
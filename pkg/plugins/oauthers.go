@@ -1 +1,8 @@
 package plugins
+
+import (
+	// Register all the oauthers here.
+	_ "github.com/sdslabs/pinger/pkg/oauther/github"
+	_ "github.com/sdslabs/pinger/pkg/oauther/google"
+	_ "github.com/sdslabs/pinger/pkg/oauther/oidc"
+)
@@ -0,0 +1,8 @@
+package plugins
+
+import (
+	// Register all the alert state stores here.
+	_ "github.com/sdslabs/pinger/pkg/statestore/file"
+	_ "github.com/sdslabs/pinger/pkg/statestore/memory"
+	_ "github.com/sdslabs/pinger/pkg/statestore/postgres"
+)
@@ -0,0 +1,4 @@
+// Package statestore defines a store interface that is used by an agent to
+// persist the alert state of checks so that alerts are not sent again when
+// the agent restarts.
+package statestore
@@ -0,0 +1,144 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sdslabs/pinger/pkg/statestore"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const storeName = "file"
+
+func init() {
+	statestore.Register(storeName, func() statestore.Store { return new(Store) })
+}
+
+// Store keeps the state in a JSON file on the local disk.
+//
+// The states are kept in memory and the whole file is re-written each time a
+// state changes. Since the state of a check changes rarely, this is cheap
+// enough and keeps the file human readable.
+type Store struct {
+	path string
+
+	mu     sync.RWMutex
+	states map[string]string
+}
+
+// Provision sets s's configuration and loads the states from the file, if
+// it exists.
+func (s *Store) Provision(_ *appcontext.Context, provider statestore.Provider) error {
+	if provider.GetBackend() != storeName && provider.GetBackend() != "" {
+		return fmt.Errorf(
+			"invalid state store name: expected '%s'; got '%s'",
+			storeName,
+			provider.GetBackend(),
+		)
+	}
+
+	s.path = provider.GetPath()
+	if s.path == "" {
+		s.path = statestore.DefaultPath
+	}
+	s.states = map[string]string{}
+
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("cannot read state file: %w", err)
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(b, &s.states); err != nil {
+		return fmt.Errorf("cannot parse state file: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the state for the key.
+func (s *Store) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[key]
+	return state, ok, nil
+}
+
+// Set sets the state for the key.
+func (s *Store) Set(_ context.Context, key, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.states[key]; ok && prev == state {
+		return nil
+	}
+
+	s.states[key] = state
+	return s.write()
+}
+
+// Delete removes the state for the key.
+func (s *Store) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.states[key]; !ok {
+		return nil
+	}
+
+	delete(s.states, key)
+	return s.write()
+}
+
+// write writes the states into the file. The states are first written into
+// a temporary file which then replaces the original file so that the file is
+// never left partially written.
+func (s *Store) write() error {
+	b, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create state file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("cannot write state file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("cannot write state file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("cannot write state file: %w", err)
+	}
+
+	return nil
+}
+
+// Interface guard.
+var _ statestore.Store = (*Store)(nil)
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sdslabs/kiwi"
+	"github.com/sdslabs/kiwi/stdkiwi"
+	"github.com/sdslabs/kiwi/values/hash"
+
+	"github.com/sdslabs/pinger/pkg/statestore"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const (
+	storeName = "memory"
+
+	// stateKey is the key of the hash that stores the states.
+	stateKey = "state"
+)
+
+func init() {
+	statestore.Register(storeName, func() statestore.Store { return new(Store) })
+}
+
+// Store keeps the state in memory.
+//
+// Internally, it uses a kiwi hash. The state is lost once the agent stops
+// hence this is only useful when alerts on restarts are acceptable.
+type Store struct {
+	hash *stdkiwi.Hash
+}
+
+// Provision sets s's configuration.
+func (s *Store) Provision(_ *appcontext.Context, provider statestore.Provider) error {
+	if provider.GetBackend() != storeName {
+		return fmt.Errorf(
+			"invalid state store name: expected '%s'; got '%s'",
+			storeName,
+			provider.GetBackend(),
+		)
+	}
+
+	store, err := stdkiwi.NewStoreFromSchema(kiwi.Schema{
+		stateKey: hash.Type,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.hash = store.Hash(stateKey)
+	return nil
+}
+
+// Get returns the state for the key.
+func (s *Store) Get(_ context.Context, key string) (string, bool, error) {
+	has, err := s.hash.Has(key)
+	if err != nil || !has {
+		return "", false, err
+	}
+
+	v, err := s.hash.Get(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	return v[0], true, nil
+}
+
+// Set sets the state for the key.
+func (s *Store) Set(_ context.Context, key, state string) error {
+	return s.hash.Insert(key, state)
+}
+
+// Delete removes the state for the key.
+func (s *Store) Delete(_ context.Context, key string) error {
+	return s.hash.Remove(key)
+}
+
+// Interface guard.
+var _ statestore.Store = (*Store)(nil)
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sdslabs/pinger/pkg/database"
+	"github.com/sdslabs/pinger/pkg/statestore"
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const storeName = "postgres"
+
+func init() {
+	statestore.Register(storeName, func() statestore.Store { return new(Store) })
+}
+
+// Store keeps the state in the postgres database used by pinger.
+//
+// This is useful when the agent does not have a persistent disk or multiple
+// agents are deployed using the same database.
+type Store struct {
+	conn *database.Conn
+}
+
+// Provision sets s's configuration and connects to the database.
+func (s *Store) Provision(ctx *appcontext.Context, provider statestore.Provider) error {
+	if provider.GetBackend() != storeName {
+		return fmt.Errorf(
+			"invalid state store name: expected '%s'; got '%s'",
+			storeName,
+			provider.GetBackend(),
+		)
+	}
+
+	conn, err := database.NewAlertStateConn(ctx, provider.GetDatabase())
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	s.conn = conn
+	return nil
+}
+
+// Get returns the state for the key.
+func (s *Store) Get(ctx context.Context, key string) (string, bool, error) {
+	state, err := s.conn.GetAlertState(ctx, key)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return state.State, true, nil
+}
+
+// Set sets the state for the key.
+func (s *Store) Set(ctx context.Context, key, state string) error {
+	return s.conn.SetAlertState(ctx, key, state)
+}
+
+// Delete removes the state for the key.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.conn.DeleteAlertState(ctx, key)
+}
+
+// Interface guard.
+var _ statestore.Store = (*Store)(nil)
@@ -0,0 +1,12 @@
+package statestore
+
+import "github.com/sdslabs/pinger/pkg/database"
+
+// Provider is anything that can be used to configure and create a state
+// store.
+type Provider interface {
+	GetBackend() string // Returns the store backend name.
+
+	GetPath() string              // Returns the path of the file for file based stores.
+	GetDatabase() database.Config // Returns the database config for database based stores.
+}
@@ -0,0 +1,84 @@
+package statestore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sdslabs/pinger/pkg/util/appcontext"
+)
+
+const (
+	// DefaultBackend is the backend used when none is configured. The state
+	// is persisted in a file so that the alerts are not sent again on
+	// restarts unless a backend is configured otherwise.
+	DefaultBackend = "file"
+
+	// DefaultPath is the path of the state file, relative to the working
+	// directory, when none is configured for the file based store.
+	DefaultPath = "pinger-state.json"
+)
+
+// This map stores all the stores. The only way to add a new store in this
+// map is to use the `Register` method.
+var stores = map[string]newFunc{}
+
+// newFunc is an alias for the function that can create a new store.
+type newFunc = func() Store
+
+// Register adds a new store to the package. This does not throw an error,
+// rather panics if the store with the same name is already registered,
+// hence a store should be registered inside the init method of the package.
+func Register(name string, fn newFunc) {
+	if _, ok := stores[name]; ok {
+		panic(fmt.Errorf("state store with same name already exists: %s", name))
+	}
+
+	stores[name] = fn
+}
+
+// List returns a list of all the enabled stores.
+func List() []string {
+	list := make([]string, 0, len(stores))
+	for name := range stores {
+		list = append(list, name)
+	}
+	return list
+}
+
+// Store is anything that can persist the state for a key.
+type Store interface {
+	// Provision sets the store's configuration.
+	Provision(*appcontext.Context, Provider) error
+
+	// Get returns the state for the key. The boolean tells if the state for
+	// the key exists or not.
+	Get(_ context.Context, key string) (string, bool, error)
+
+	// Set sets the state for the key.
+	Set(_ context.Context, key, state string) error
+
+	// Delete removes the state for the key.
+	Delete(_ context.Context, key string) error
+}
+
+// Initialize method initializes the store from the provider. If the backend
+// of the provider is empty, the default backend is used.
+func Initialize(ctx *appcontext.Context, provider Provider) (Store, error) {
+	name := provider.GetBackend()
+	if name == "" {
+		name = DefaultBackend
+	}
+
+	newStore, ok := stores[name]
+	if !ok {
+		return nil, fmt.Errorf("state store with name does not exist: %s", name)
+	}
+
+	store := newStore()
+
+	if err := store.Provision(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
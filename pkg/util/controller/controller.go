@@ -14,17 +14,26 @@ type RunnerFunc func(context.Context) (interface{}, error)
 type RunStat struct {
 	ID   string
 	Name string
+	Time time.Time
 
 	Err error
 	Res interface{}
 }
 
 // Opts are the options required to create a new controller.
+//
+// Capacity is the maximum number of stats the controller keeps until they
+// are pulled. Once the capacity is reached, the stats are dropped or
+// summarized depending on the overflow policy.
 type Opts struct {
 	ID       string
 	Name     string
 	Interval time.Duration
 	Func     RunnerFunc
+
+	Capacity  int
+	Overflow  OverflowPolicy
+	Summarize SummarizeFunc
 }
 
 // Controller runs a specific operation infinitely until the context is
@@ -36,8 +45,7 @@ type Controller struct {
 	mutex sync.RWMutex
 	wg    sync.WaitGroup
 
-	stats     map[time.Time]*RunStat
-	latestRun time.Time
+	stats *statRing
 
 	interval time.Duration
 	update   chan struct{}
@@ -68,8 +76,7 @@ func NewController(ctx context.Context, opts *Opts) (*Controller, error) {
 		mutex: sync.RWMutex{},
 		wg:    sync.WaitGroup{},
 
-		stats:     make(map[time.Time]*RunStat),
-		latestRun: time.Time{},
+		stats: newStatRing(opts.Capacity, opts.Overflow, opts.Summarize),
 
 		interval: opts.Interval,
 		update:   make(chan struct{}, 1),
@@ -92,6 +99,18 @@ func validateControllerOpts(opts *Opts) error {
 		return fmt.Errorf("controller function cannot be nil")
 	}
 
+	if opts.Capacity < 0 {
+		return fmt.Errorf("controller capacity should be >= 0")
+	}
+
+	if err := ValidateOverflowPolicy(opts.Overflow); err != nil {
+		return err
+	}
+
+	if opts.Overflow == OverflowSummarize && opts.Summarize == nil {
+		return fmt.Errorf("controller summarize function cannot be nil for %s policy", opts.Overflow)
+	}
+
 	return nil
 }
 
@@ -179,18 +198,16 @@ func (c *Controller) runFunc() {
 		ctrl.mutex.RUnlock()
 
 		res, err := fn(ctrl.ctx)
-		stat := &RunStat{
+
+		ctrl.mutex.Lock()
+		ctrl.stats.push(&RunStat{
 			ID:   ctrl.id,
 			Name: ctrl.name,
+			Time: time.Now(),
 
 			Err: err,
 			Res: res,
-		}
-
-		ctrl.mutex.Lock()
-		tnow := time.Now()
-		ctrl.latestRun = tnow
-		ctrl.stats[tnow] = stat
+		})
 		ctrl.mutex.Unlock()
 	}(c)
 }
@@ -235,15 +252,13 @@ func (c *Controller) Stop() {
 	c.cancel()
 }
 
-// PullAllStats fetches the stats for the controller and also cleans up the
-// stats.
-func (c *Controller) PullAllStats() map[time.Time]*RunStat {
+// PullAllStats fetches the stats for the controller, ordered by the time of
+// the run, and also cleans up the stats.
+func (c *Controller) PullAllStats() []*RunStat {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	stats := c.stats
-	c.stats = make(map[time.Time]*RunStat)
-	return stats
+	return c.stats.drain()
 }
 
 // PullLatestStat fetches only the latest stat from the controller and
@@ -252,11 +267,16 @@ func (c *Controller) PullLatestStat() *RunStat {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	stat, ok := c.stats[c.latestRun]
-	if !ok {
-		return nil
-	}
-
-	c.stats = make(map[time.Time]*RunStat)
+	stat := c.stats.latest()
+	c.stats.clear()
 	return stat
 }
+
+// OverflowStats returns the counters for the stats that overflowed the
+// capacity of the controller.
+func (c *Controller) OverflowStats() OverflowStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.stats.overflow
+}
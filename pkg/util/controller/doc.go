@@ -2,7 +2,10 @@
 //
 // Controllers can be used to run a specific task repetitively at regular
 // intervals of time. A controller stores the resulting stats of runs with
-// itself until pulled from it.
+// itself until pulled from it. The stats are kept in a ring buffer of fixed
+// capacity so that a controller does not grow indefinitely when the stats
+// are not pulled; on overflow the oldest stats are either dropped or
+// summarized.
 //
 // A manager is used to orchestrate multiple controllers running
 // concurrently.
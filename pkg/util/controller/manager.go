@@ -189,7 +189,7 @@ func (m *Manager) Close() {
 }
 
 // PullAllStats gets all the stats for all the controllers registered with
-// the manager.
+// the manager. Stats of each controller are ordered by the time of the run.
 func (m *Manager) PullAllStats() map[string][]*RunStat {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -197,14 +197,7 @@ func (m *Manager) PullAllStats() map[string][]*RunStat {
 	stats := make(map[string][]*RunStat)
 
 	for id, ctrl := range m.controllers {
-		cstats := []*RunStat{}
-
-		pull := ctrl.PullAllStats()
-		for _, p := range pull {
-			cstats = append(cstats, p)
-		}
-
-		stats[id] = cstats
+		stats[id] = ctrl.PullAllStats()
 	}
 
 	return stats
@@ -223,3 +216,18 @@ func (m *Manager) PullLatestStats() map[string]*RunStat {
 
 	return stats
 }
+
+// OverflowStats gets the overflow counters for each controller in the
+// manager.
+func (m *Manager) OverflowStats() map[string]OverflowStats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats := make(map[string]OverflowStats)
+
+	for id, ctrl := range m.controllers {
+		stats[id] = ctrl.OverflowStats()
+	}
+
+	return stats
+}
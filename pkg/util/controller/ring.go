@@ -0,0 +1,137 @@
+package controller
+
+import "fmt"
+
+// DefaultCapacity is the number of stats a controller keeps when no
+// capacity is provided in the options.
+const DefaultCapacity = 1024
+
+// OverflowPolicy tells what a controller does when a new stat is recorded
+// and the stats have reached their capacity.
+type OverflowPolicy string
+
+// Various overflow policies.
+const (
+	// OverflowDropOldest drops the oldest stat to make space for the new
+	// one. This is the default policy.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+
+	// OverflowSummarize merges the two oldest stats into one using the
+	// summarize function of the controller to make space for the new one.
+	OverflowSummarize OverflowPolicy = "summarize"
+)
+
+// SummarizeFunc merges two consecutive stats into a single stat. It gets
+// the older stat first.
+type SummarizeFunc func(older, newer *RunStat) *RunStat
+
+// OverflowStats are the counters for the stats that overflowed the capacity
+// of a controller since it was created.
+type OverflowStats struct {
+	Dropped    uint64 // Number of stats dropped.
+	Summarized uint64 // Number of stats merged into another stat.
+}
+
+// ValidateOverflowPolicy validates if the policy is one of the supported
+// ones. An empty policy is valid and defaults to drop-oldest.
+func ValidateOverflowPolicy(policy OverflowPolicy) error {
+	switch policy {
+	case "", OverflowDropOldest, OverflowSummarize:
+	default:
+		return fmt.Errorf("invalid overflow policy: %s", policy)
+	}
+
+	return nil
+}
+
+// statRing is a fixed size ring buffer of stats. It is not safe for
+// concurrent use.
+type statRing struct {
+	buf   []*RunStat
+	start int // index of the oldest stat
+	size  int // number of stats in the buffer
+
+	policy    OverflowPolicy
+	summarize SummarizeFunc
+
+	overflow OverflowStats
+}
+
+// newStatRing creates a new ring buffer of the given capacity.
+func newStatRing(capacity int, policy OverflowPolicy, summarize SummarizeFunc) *statRing {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	if policy == "" {
+		policy = OverflowDropOldest
+	}
+
+	return &statRing{
+		buf:       make([]*RunStat, capacity),
+		policy:    policy,
+		summarize: summarize,
+	}
+}
+
+// at returns the i-th oldest stat in the buffer.
+func (r *statRing) at(i int) *RunStat {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// push adds the stat into the buffer making space for it according to the
+// overflow policy if the buffer is full.
+func (r *statRing) push(stat *RunStat) {
+	if r.size == len(r.buf) {
+		r.evict()
+	}
+
+	r.buf[(r.start+r.size)%len(r.buf)] = stat
+	r.size++
+}
+
+// evict frees up the space for one stat.
+func (r *statRing) evict() {
+	if r.policy == OverflowSummarize && r.summarize != nil && r.size >= 2 {
+		merged := r.summarize(r.at(0), r.at(1))
+		r.buf[(r.start+1)%len(r.buf)] = merged
+		r.overflow.Summarized++
+	} else {
+		r.overflow.Dropped++
+	}
+
+	r.buf[r.start] = nil
+	r.start = (r.start + 1) % len(r.buf)
+	r.size--
+}
+
+// latest returns the latest stat in the buffer, if any.
+func (r *statRing) latest() *RunStat {
+	if r.size == 0 {
+		return nil
+	}
+
+	return r.at(r.size - 1)
+}
+
+// drain returns all the stats ordered from the oldest to the latest and
+// empties the buffer.
+func (r *statRing) drain() []*RunStat {
+	stats := make([]*RunStat, r.size)
+	for i := range stats {
+		stats[i] = r.at(i)
+	}
+
+	r.clear()
+	return stats
+}
+
+// clear empties the buffer.
+func (r *statRing) clear() {
+	for i := range r.buf {
+		r.buf[i] = nil
+	}
+
+	r.start = 0
+	r.size = 0
+}
@@ -16,25 +16,76 @@ type ErrorResponse struct {
 
 // MetricResponse is the JSON response for returning metrics.
 type MetricResponse struct {
+	Location   string        `json:"location,omitempty"`
 	Successful bool          `json:"successful"`
 	Timeout    bool          `json:"timeout"`
+	Degraded   bool          `json:"degraded"`
 	StartTime  time.Time     `json:"start_time"`
 	Duration   time.Duration `json:"duration"`
+
+	FailureCode    string `json:"failure_code,omitempty"`
+	FailureMessage string `json:"failure_message,omitempty"`
+
+	Measurements map[string]interface{} `json:"measurements,omitempty"`
+
+	// Set when the response is of an aggregate of the runs in a bucket.
+	Runs     int64         `json:"runs,omitempty"`
+	Failures int64         `json:"failures,omitempty"`
+	Uptime   float64       `json:"uptime,omitempty"`
+	P50      time.Duration `json:"p50,omitempty"`
+	P95      time.Duration `json:"p95,omitempty"`
+	P99      time.Duration `json:"p99,omitempty"`
 }
 
 // PageCheckMetricsResponse is the JSON response for all the metrics related
 // to a particular check.
 type PageCheckMetricsResponse struct {
-	Metrics     []MetricResponse `json:"metrics"`
-	Uptime      int              `json:"uptime"`
-	Operational bool             `json:"operational"`
+	Metrics []MetricResponse `json:"metrics"`
+
+	// Set when the check runs from multiple locations, with the latest
+	// metric of each of them along with its runs and uptime over the
+	// window. The first of the metrics is their verdict.
+	Locations []MetricResponse `json:"locations,omitempty"`
+
+	Uptime      float64       `json:"uptime"`
+	Operational bool          `json:"operational"`
+	Degraded    bool          `json:"degraded"`
+	Maintenance bool          `json:"maintenance"`
+	SLA         []SLAResponse `json:"sla,omitempty"`
+}
+
+// SLAResponse is the JSON response for the availability of a check over a
+// window of time.
+type SLAResponse struct {
+	Window       string    `json:"window"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Availability float64   `json:"availability"`
+
+	Up       time.Duration `json:"up"`
+	Down     time.Duration `json:"down"`
+	Missing  time.Duration `json:"missing"`
+	Excluded time.Duration `json:"excluded"`
 }
 
 // PageMetricsResponse is the JSON response for returning all the metrics
 // related information which is fetched once the page is loaded.
 type PageMetricsResponse struct {
-	ChecksDown int                                 `json:"checks_down"`
-	Checks     map[string]PageCheckMetricsResponse `json:"checks"`
+	ChecksDown     int                                 `json:"checks_down"`
+	ChecksDegraded int                                 `json:"checks_degraded"`
+	Checks         map[string]PageCheckMetricsResponse `json:"checks"`
+	Maintenance    []MaintenanceResponse               `json:"maintenance,omitempty"`
+}
+
+// MaintenanceResponse is the JSON response for an ongoing or upcoming
+// maintenance window.
+type MaintenanceResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Checks      []string  `json:"checks,omitempty"`
+	Active      bool      `json:"active"`
 }
 
 // PageResponse is the data passed into the template.
@@ -48,6 +99,29 @@ type PageResponse struct {
 	WebsiteURL string
 }
 
+// CentralStatusResponse is the JSON response for the status of the agents
+// registered with the central server, along with the checks that are not
+// assigned to any agent and the ones that run from fewer locations than
+// required.
+type CentralStatusResponse struct {
+	Agents     []AgentStatusResponse `json:"agents"`
+	Unassigned []string              `json:"unassigned,omitempty"`
+	Partial    []string              `json:"partial,omitempty"`
+}
+
+// AgentStatusResponse is the JSON response for the status of an agent and
+// the number of checks assigned to it.
+type AgentStatusResponse struct {
+	Name     string    `json:"name"`
+	Address  string    `json:"address"`
+	Location string    `json:"location,omitempty"`
+	Healthy  bool      `json:"healthy"`
+	Capacity int       `json:"capacity"`
+	Checks   int       `json:"checks"`
+	LastSeen time.Time `json:"last_seen"`
+	Error    string    `json:"error,omitempty"`
+}
+
 // RespondError writes an error response to the request.
 func RespondError(ctx *appcontext.Context, c *gin.Context, statusCode int, err error) {
 	resp := err.Error()
@@ -72,3 +146,107 @@ func RespondErrorNotFound(ctx *appcontext.Context, c *gin.Context, err error) {
 func RespondOK(_ *appcontext.Context, c *gin.Context, resp interface{}) {
 	c.JSON(http.StatusOK, resp)
 }
+
+// TokenResponse is the JSON response with the token to authenticate the user
+// with the app server.
+type TokenResponse struct {
+	Token string `json:"token"`
+}
+
+// LoginResponse is the JSON response with the URL where the user logs in
+// with the OAuth provider.
+type LoginResponse struct {
+	URL string `json:"url"`
+}
+
+// UserResponse is the JSON response for a user of the app.
+type UserResponse struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// ComponentResponse is the JSON response for a component of a check, such
+// as its input or its outputs.
+type ComponentResponse struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// PayloadResponse is the JSON response for a payload of a check.
+type PayloadResponse struct {
+	ID      uint   `json:"id"`
+	CheckID string `json:"check_id"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+}
+
+// CheckResponse is the JSON response for a check of the app.
+type CheckResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout"`
+
+	Retries          int           `json:"retries"`
+	RetryInterval    time.Duration `json:"retry_interval"`
+	FailureThreshold int           `json:"failure_threshold"`
+	SuccessThreshold int           `json:"success_threshold"`
+
+	Input    ComponentResponse   `json:"input"`
+	Outputs  []ComponentResponse `json:"outputs,omitempty"`
+	Operator string              `json:"operator"`
+	Target   ComponentResponse   `json:"target"`
+	Payloads []PayloadResponse   `json:"payloads,omitempty"`
+}
+
+// IncidentResponse is the JSON response for an incident of a status page.
+type IncidentResponse struct {
+	ID          uint          `json:"id"`
+	PageID      uint          `json:"page_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Resolved    bool          `json:"resolved"`
+	TimeStamp   time.Time     `json:"timestamp"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// StatusPageMaintenanceResponse is the JSON response for a maintenance of a
+// status page of the app.
+type StatusPageMaintenanceResponse struct {
+	ID          uint          `json:"id"`
+	PageID      uint          `json:"page_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Start       time.Time     `json:"start"`
+	Duration    time.Duration `json:"duration"`
+	Recurrence  string        `json:"recurrence,omitempty"`
+	Until       *time.Time    `json:"until,omitempty"`
+	Timezone    string        `json:"timezone,omitempty"`
+	Checks      []string      `json:"checks,omitempty"`
+}
+
+// TeamMemberResponse is the JSON response for a member of the team of a
+// status page.
+type TeamMemberResponse struct {
+	User UserResponse `json:"user"`
+	Role string       `json:"role"`
+}
+
+// StatusPageResponse is the JSON response for a status page of the app.
+type StatusPageResponse struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+
+	Checks       []CheckResponse                 `json:"checks,omitempty"`
+	Incidents    []IncidentResponse              `json:"incidents,omitempty"`
+	Team         []TeamMemberResponse            `json:"team,omitempty"`
+	Maintenances []StatusPageMaintenanceResponse `json:"maintenances,omitempty"`
+}
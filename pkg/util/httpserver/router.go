@@ -17,6 +17,9 @@ func init() {
 type RouterOpts struct {
 	AllowedOrigins []string // use "*" to allow all origins.
 	AllowedMethods []string // GET and POST are allowed by default.
+	AllowedHeaders []string // non-simple headers, like Authorization.
+
+	AllowCredentials bool // allow requests with cookies.
 
 	allowAllOrigins bool
 }
@@ -43,7 +46,10 @@ func NewRouter(ctx *appcontext.Context, opts RouterOpts) *gin.Engine {
 		AllowMethods:    opts.AllowedMethods,
 		AllowOrigins:    opts.AllowedOrigins,
 		AllowWildcard:   true,
+
+		AllowCredentials: opts.AllowCredentials,
 	}
+	corsConf.AddAllowHeaders(opts.AllowedHeaders...)
 
 	router := gin.Default()
 	router.Use(cors.New(corsConf))
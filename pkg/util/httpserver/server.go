@@ -14,15 +14,31 @@ import (
 // HTTP handler. It honors context and hence exits gracefully when the
 // context is canceled.
 func ListenAndServe(ctx *appcontext.Context, port uint16, h http.Handler) error {
-	addr := net.JoinHostPort("0.0.0.0", fmt.Sprint(port))
+	lst, err := Listen(port)
+	if err != nil {
+		return err
+	}
+
+	return Serve(ctx, lst, h)
+}
+
+// Listen listens on :port so that the errors in binding the port can be
+// reported before the requests are served.
+func Listen(port uint16) (net.Listener, error) {
+	return net.Listen("tcp", net.JoinHostPort("0.0.0.0", fmt.Sprint(port)))
+}
+
+// Serve serves the requests accepted by the listener using the HTTP handler.
+// Like `ListenAndServe`, it exits gracefully when the context is canceled.
+func Serve(ctx *appcontext.Context, lst net.Listener, h http.Handler) error {
 	server := http.Server{
-		Addr:    addr,
+		Addr:    lst.Addr().String(),
 		Handler: h,
 	}
 
 	errChan := make(chan error)
 	go func() {
-		errChan <- server.ListenAndServe()
+		errChan <- server.Serve(lst)
 	}()
 
 	select {
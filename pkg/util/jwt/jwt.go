@@ -59,7 +59,7 @@ func (t *JWT) RefreshToken(token string) (refreshToken string, statusCode int, _
 		return "", http.StatusBadRequest, err
 	}
 
-	if time.Now().Unix() > cl.ExpiresAt+int64(t.RefreshInterval) {
+	if time.Now().Unix() > cl.ExpiresAt+int64(t.RefreshInterval.Seconds()) {
 		return "", http.StatusUnauthorized, errors.New("time exceeds max refresh time, login again")
 	}
 
@@ -117,10 +117,10 @@ func (t *JWT) GetTokenFromHeader(ctx *gin.Context) (string, error) {
 		return "", errors.New("missing Authorization Header")
 	}
 
-	authTypeLen := len(t.AuthType)
-	if authHeader[:authTypeLen] != t.AuthType {
+	prefix := t.AuthType + " "
+	if !strings.HasPrefix(authHeader, prefix) {
 		return "", fmt.Errorf("missing '%s' authorization type", t.AuthType)
 	}
 
-	return authHeader[authTypeLen+1:], nil
+	return authHeader[len(prefix):], nil
 }
@@ -0,0 +1,194 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/exporter"
+	"github.com/sdslabs/pinger/pkg/util/httpserver"
+)
+
+// PrepareAggregatesResponse creates a page metrics response for each of the
+// check from the aggregates of the metrics.
+//
+// The first metric of each check is its latest metric, if any, which tells
+// if the check is operational, followed by the aggregates of each bucket.
+// An aggregate is successful only if none of the runs failed and its
+// duration is the 99th percentile of the runs, so that a failure or a slow
+// run is not hidden by the aggregation.
+//
+// The aggregates of the locations of each check are combined into a bucket
+// each by the consensus, so a bucket is only down for as long as the quorum
+// of the locations were down.
+//
+// The uptime is weighted by time over the window, considering each bucket of
+// the given width with any run to be up for the fraction of successful runs.
+// The excluded windows of each check, such as its maintenance, are left out
+// along with the ones in the options.
+//
+// When the latest metric is a verdict of multiple locations, the latest
+// metric of each of the locations is included along with it, with the runs
+// and uptime of the location over the window.
+func PrepareAggregatesResponse(
+	latest map[string][]checker.Metric,
+	aggregates map[string][]exporter.Aggregate,
+	consensus checker.Consensus,
+	window Window,
+	width time.Duration,
+	opts *SLAOptions,
+	excluded map[string][]Window,
+) httpserver.PageMetricsResponse {
+	resp := map[string]httpserver.PageCheckMetricsResponse{}
+	var checksDown, checksDegraded int
+	for cid, byLocation := range aggregates {
+		as := exporter.CombineAggregates(byLocation, consensus)
+		if len(as) == 0 {
+			continue
+		}
+
+		serialized := make([]httpserver.MetricResponse, 0, len(as)+1)
+		var locations []httpserver.MetricResponse
+		if ms := latest[cid]; len(ms) > 0 {
+			serialized = append(serialized, metricResponse(ms[0]))
+
+			if v, ok := ms[0].(*checker.Verdict); ok {
+				locations = make([]httpserver.MetricResponse, len(v.Locations))
+				for i := range v.Locations {
+					locations[i] = metricResponse(v.Locations[i])
+				}
+
+				addLocationUptimes(locations, byLocation, window, width, withExcluded(opts, excluded[cid]))
+			}
+		}
+
+		for i := range as {
+			a := &as[i]
+			serialized = append(serialized, httpserver.MetricResponse{
+				Successful: a.Failures == 0,
+				Timeout:    a.Timeouts > 0,
+				Degraded:   a.Degradations > 0,
+				StartTime:  a.StartTime,
+				Duration:   a.P99,
+
+				Runs:     a.Runs,
+				Failures: a.Failures,
+				Uptime:   a.Uptime(),
+				P50:      a.P50,
+				P95:      a.P95,
+				P99:      a.P99,
+			})
+		}
+
+		segments := SegmentsFromAggregates(as, width)
+		uptime := ComputeSLA(window, segments, withExcluded(opts, excluded[cid])).Availability
+
+		operational := serialized[0].Successful
+		degraded := serialized[0].Degraded
+		resp[cid] = httpserver.PageCheckMetricsResponse{
+			Metrics:     serialized,
+			Locations:   locations,
+			Uptime:      uptime,
+			Operational: operational,
+			Degraded:    degraded,
+		}
+		if !operational {
+			checksDown++
+		} else if degraded {
+			checksDegraded++
+		}
+	}
+	return httpserver.PageMetricsResponse{
+		ChecksDown:     checksDown,
+		ChecksDegraded: checksDegraded,
+		Checks:         resp,
+	}
+}
+
+// addLocationUptimes sets the runs, failures and uptime over the window of
+// each of the locations from their aggregates.
+func addLocationUptimes(
+	locations []httpserver.MetricResponse,
+	aggregates []exporter.Aggregate,
+	window Window,
+	width time.Duration,
+	opts *SLAOptions,
+) {
+	byLocation := exporter.SplitAggregates(aggregates)
+	for i := range locations {
+		l := &locations[i]
+		for j := range byLocation[l.Location] {
+			l.Runs += byLocation[l.Location][j].Runs
+			l.Failures += byLocation[l.Location][j].Failures
+		}
+
+		segments := SegmentsFromAggregates(byLocation[l.Location], width)
+		l.Uptime = ComputeSLA(window, segments, opts).Availability
+	}
+}
+
+// metricResponse serializes the metric of a single run.
+func metricResponse(m checker.Metric) httpserver.MetricResponse {
+	return httpserver.MetricResponse{
+		Location:   m.GetLocation(),
+		Successful: m.IsSuccessful(),
+		Timeout:    m.IsTimeout(),
+		Degraded:   m.IsDegraded(),
+		StartTime:  m.GetStartTime(),
+		Duration:   m.GetDuration(),
+
+		FailureCode:    m.GetFailureCode(),
+		FailureMessage: m.GetFailureMessage(),
+
+		Measurements: m.GetMeasurements(),
+	}
+}
+
+// PrepareSLAResponse computes the availability of each of the checks over the
+// window from the aggregates of their metrics, each of which spans a bucket
+// of the given width. The period is the window as it was requested. The
+// aggregates of the locations are combined and the excluded windows of each
+// check are left out as in PrepareAggregatesResponse.
+func PrepareSLAResponse(
+	period string,
+	window Window,
+	width time.Duration,
+	aggregates map[string][]exporter.Aggregate,
+	consensus checker.Consensus,
+	opts *SLAOptions,
+	excluded map[string][]Window,
+	checkIDs ...string,
+) map[string]httpserver.SLAResponse {
+	resp := make(map[string]httpserver.SLAResponse, len(checkIDs))
+	for _, cid := range checkIDs {
+		segments := SegmentsFromAggregates(exporter.CombineAggregates(aggregates[cid], consensus), width)
+		sla := ComputeSLA(window, segments, withExcluded(opts, excluded[cid]))
+		resp[cid] = httpserver.SLAResponse{
+			Window:       period,
+			Start:        sla.Start,
+			End:          sla.End,
+			Availability: sla.Availability,
+
+			Up:       sla.Up,
+			Down:     sla.Down,
+			Missing:  sla.Missing,
+			Excluded: sla.Excluded,
+		}
+	}
+
+	return resp
+}
+
+// withExcluded returns the options with the windows excluded as well.
+func withExcluded(opts *SLAOptions, excluded []Window) *SLAOptions {
+	if len(excluded) == 0 {
+		return opts
+	}
+
+	o := SLAOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	o.Excluded = append(append([]Window{}, o.Excluded...), excluded...)
+	return &o
+}
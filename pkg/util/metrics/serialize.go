@@ -12,6 +12,7 @@ import (
 //
 // The following rules are applied to each batch:
 // 	- Failed metric is prioritized over successful.
+// 	- Degraded metric is prioritized over non-degraded successful.
 // 	- Metric with highest latency is considered.
 //  - If number of batches are more than number of metrics, this is probably
 // 	  recent addition of check. In this case, The first metric should be
@@ -20,9 +21,12 @@ import (
 //
 // Minimum number of batches accepted is 2 since only 1 would mean just
 // getting the latest metric which doesn't reflect any history.
+//
+// The uptime is the percentage of time the check was up, rounded down to a
+// whole number.
 func SerializeMetrics(
 	batches int, metrics []checker.Metric,
-) (serialized []httpserver.MetricResponse, uptime int) {
+) (serialized []httpserver.MetricResponse, uptime float64) {
 	if batches < 2 || len(metrics) == 0 {
 		return
 	}
@@ -31,23 +35,25 @@ func SerializeMetrics(
 
 	serialized = make([]httpserver.MetricResponse, 0, batches)
 	numEachBatch := (len(metrics) / batches) + 1
-	var upNum int
-
-	if metrics[0].IsSuccessful() {
-		upNum++
-	}
 	serialized = append(serialized, httpserver.MetricResponse{
 		Successful: metrics[0].IsSuccessful(),
 		Timeout:    metrics[0].IsTimeout(),
+		Degraded:   metrics[0].IsDegraded(),
 		StartTime:  metrics[0].GetStartTime(),
 		Duration:   metrics[0].GetDuration(),
+
+		FailureCode:    metrics[0].GetFailureCode(),
+		FailureMessage: metrics[0].GetFailureMessage(),
+
+		Measurements: metrics[0].GetMeasurements(),
 	})
 
 	for i := 1; i < len(metrics); i += numEachBatch {
 		var (
-			metric  checker.Metric
-			latency time.Duration
-			failed  bool
+			metric   checker.Metric
+			latency  time.Duration
+			failed   bool
+			degraded bool
 		)
 
 		for j := i; j < i+numEachBatch; j++ {
@@ -62,16 +68,24 @@ func SerializeMetrics(
 				failed = true
 			}
 
-			if m.IsSuccessful() {
-				upNum++
+			if failed {
+				continue
 			}
 
-			if failed {
-				continue // don't break because we need to calculate uptime
+			if m.IsDegraded() && !degraded {
+				metric = m
+				latency = m.GetDuration()
+				degraded = true
+				continue
+			}
+
+			if degraded && !m.IsDegraded() {
+				continue
 			}
 
 			if latency < m.GetDuration() {
 				metric = m
+				latency = m.GetDuration()
 			}
 		}
 
@@ -82,8 +96,14 @@ func SerializeMetrics(
 		serialized = append(serialized, httpserver.MetricResponse{
 			Successful: metric.IsSuccessful(),
 			Timeout:    metric.IsTimeout(),
+			Degraded:   metric.IsDegraded(),
 			StartTime:  metric.GetStartTime(),
 			Duration:   metric.GetDuration(),
+
+			FailureCode:    metric.GetFailureCode(),
+			FailureMessage: metric.GetFailureMessage(),
+
+			Measurements: metric.GetMeasurements(),
 		})
 	}
 
@@ -97,18 +117,42 @@ func SerializeMetrics(
 		}
 	}
 
-	uptime = (upNum * 100) / len(metrics) // percentage
+	// uptime is weighted by the time each run holds for, since the oldest
+	// metric till now.
+	window := Window{Start: metrics[len(metrics)-1].GetStartTime(), End: time.Now()}
+	uptime = ComputeSLA(window, SegmentsFromMetrics(metrics, 0), nil).Availability
 
 	return
 }
 
+// aggregated is implemented by the metrics that aggregate multiple runs of
+// a check, such as the rollups.
+type aggregated interface {
+	GetRuns() int64
+	GetSuccessfulRuns() int64
+}
+
+// countRuns returns the number of successful runs and the total number of
+// runs the metric represents.
+func countRuns(m checker.Metric) (up, total int64) {
+	if a, ok := m.(aggregated); ok {
+		return a.GetSuccessfulRuns(), a.GetRuns()
+	}
+
+	if m.IsSuccessful() {
+		return 1, 1
+	}
+
+	return 0, 1
+}
+
 // PrepareMetricsResponse creates a page metrics response for each of the
 // check after serializing the metrics.
 func PrepareMetricsResponse(
 	batches int, metrics map[string][]checker.Metric,
 ) httpserver.PageMetricsResponse {
 	resp := map[string]httpserver.PageCheckMetricsResponse{}
-	var checksDown int
+	var checksDown, checksDegraded int
 	for cid := range metrics {
 		// NB: This shouldn't take that long but since this request is long enough
 		// in general, one optimization can be to serialize the metrics in different
@@ -119,17 +163,22 @@ func PrepareMetricsResponse(
 			continue
 		}
 		operational := serialized[0].Successful
+		degraded := serialized[0].Degraded
 		resp[cid] = httpserver.PageCheckMetricsResponse{
 			Metrics:     serialized,
 			Uptime:      uptime,
 			Operational: operational,
+			Degraded:    degraded,
 		}
 		if !operational {
 			checksDown++
+		} else if degraded {
+			checksDegraded++
 		}
 	}
 	return httpserver.PageMetricsResponse{
-		ChecksDown: checksDown,
-		Checks:     resp,
+		ChecksDown:     checksDown,
+		ChecksDegraded: checksDegraded,
+		Checks:         resp,
 	}
 }
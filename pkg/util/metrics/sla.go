@@ -0,0 +1,366 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdslabs/pinger/pkg/checker"
+	"github.com/sdslabs/pinger/pkg/exporter"
+)
+
+// MissingPolicy is how the time for which there are no metrics of a check
+// is counted in its availability.
+type MissingPolicy string
+
+// Policies for the time without metrics.
+const (
+	// MissingIgnore leaves the time out of the availability.
+	MissingIgnore MissingPolicy = "ignore"
+
+	// MissingDown counts the time as downtime.
+	MissingDown MissingPolicy = "down"
+
+	// MissingUp counts the time as uptime.
+	MissingUp MissingPolicy = "up"
+)
+
+// ValidateMissingPolicy returns an error if the policy is not one of the
+// known policies. An empty policy is the same as "ignore".
+func ValidateMissingPolicy(policy MissingPolicy) error {
+	switch policy {
+	case "", MissingIgnore, MissingDown, MissingUp:
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid missing policy: expected '%s', '%s' or '%s'; got '%s'",
+			MissingIgnore, MissingDown, MissingUp, policy,
+		)
+	}
+}
+
+// Calendar periods that can be used as windows.
+const (
+	PeriodDay     = "day"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// Window is a period of time from Start till End.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// ParseWindow returns the window for the period that ends at `now`.
+//
+// The period is either a calendar period, i.e., "day", "month", "quarter" or
+// "year", which starts at the beginning of the current period in UTC, or a
+// duration like "720h" or "30d", which is the rolling window of the duration.
+func ParseWindow(period string, now time.Time) (Window, error) {
+	now = now.UTC()
+	year, month, day := now.Date()
+
+	var start time.Time
+	switch period {
+	case PeriodDay:
+		start = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	case PeriodMonth:
+		start = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodQuarter:
+		start = time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodYear:
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		duration, err := parseDuration(period)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid window %q: %w", period, err)
+		}
+
+		if duration <= 0 {
+			return Window{}, fmt.Errorf("invalid window %q: duration should be > 0", period)
+		}
+
+		start = now.Add(-1 * duration)
+	}
+
+	return Window{Start: start, End: now}, nil
+}
+
+// LongestWindow returns the longest the window of the period can be, which
+// for a calendar period is its longest, like 31 days of a month.
+func LongestWindow(period string) (time.Duration, error) {
+	const day = 24 * time.Hour
+
+	switch period {
+	case PeriodDay:
+		return day, nil
+	case PeriodMonth:
+		return 31 * day, nil
+	case PeriodQuarter:
+		return 92 * day, nil
+	case PeriodYear:
+		return 366 * day, nil
+	}
+
+	window, err := ParseWindow(period, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return window.Duration(), nil
+}
+
+// parseDuration parses the duration which can also be in days, like "30d".
+func parseDuration(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// Segment is a period of time for which the availability of a check is
+// known from its metrics.
+type Segment struct {
+	Window
+	Up float64 // Fraction of the time the check was up.
+}
+
+// gapFactor is the number of intervals between the runs of a check after
+// which the time is considered missing, when the maximum gap is inferred.
+const gapFactor = 2
+
+// SegmentsFromMetrics creates the segments from the metrics of a check. The
+// result of each run holds till the next run, unless the next run is more
+// than `maxGap` later, in which case the time after `maxGap` is missing.
+//
+// If `maxGap` is 0, it is inferred as twice the median interval between the
+// runs, so that changes in the interval of the check are accounted for. When
+// it cannot be inferred, i.e., there is a single run, its result holds till
+// now.
+func SegmentsFromMetrics(metrics []checker.Metric, maxGap time.Duration) []Segment {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	sorted := make([]checker.Metric, len(metrics))
+	copy(sorted, metrics)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetStartTime().Before(sorted[j].GetStartTime())
+	})
+
+	if maxGap <= 0 {
+		maxGap = gapFactor * medianInterval(sorted)
+	}
+
+	segments := make([]Segment, 0, len(sorted))
+	for i, m := range sorted {
+		start := m.GetStartTime()
+		end := start.Add(maxGap)
+		if maxGap <= 0 {
+			end = time.Now()
+		}
+		if i+1 < len(sorted) && sorted[i+1].GetStartTime().Before(end) {
+			end = sorted[i+1].GetStartTime()
+		}
+
+		up, total := countRuns(m)
+		segments = append(segments, Segment{
+			Window: Window{Start: start, End: end},
+			Up:     float64(up) / float64(total),
+		})
+	}
+
+	return segments
+}
+
+// medianInterval returns the median of the intervals between the sorted
+// metrics.
+func medianInterval(sorted []checker.Metric) time.Duration {
+	if len(sorted) < 2 {
+		return 0
+	}
+
+	intervals := make([]time.Duration, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		intervals = append(intervals, sorted[i].GetStartTime().Sub(sorted[i-1].GetStartTime()))
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+// SegmentsFromAggregates creates the segments from the aggregates of a check,
+// each of which spans the bucket of the given width. Buckets without any run
+// are missing.
+func SegmentsFromAggregates(aggregates []exporter.Aggregate, width time.Duration) []Segment {
+	segments := make([]Segment, 0, len(aggregates))
+	for i := range aggregates {
+		a := &aggregates[i]
+		if a.Runs == 0 {
+			continue
+		}
+
+		segments = append(segments, Segment{
+			Window: Window{Start: a.StartTime, End: a.StartTime.Add(width)},
+			Up:     float64(a.Runs-a.Failures) / float64(a.Runs),
+		})
+	}
+
+	return segments
+}
+
+// SLAOptions are the options for computing the availability.
+type SLAOptions struct {
+	// Excluded are the windows left out of the availability, such as the
+	// scheduled maintenance.
+	Excluded []Window
+
+	// Missing is how the time without metrics is counted.
+	Missing MissingPolicy
+
+	// Precision is the number of decimal places of the availability.
+	Precision int
+}
+
+// SLA is the availability of a check over a window.
+type SLA struct {
+	Window
+
+	// Availability is the percentage of the time the check was up. It is
+	// rounded down to the precision so that 99.995 is not reported as 100.
+	// It is 100 when there is no time to compute it from.
+	Availability float64
+
+	// Up and Down include the missing time if it is counted as either.
+	Up       time.Duration // Time the check was up.
+	Down     time.Duration // Time the check was down.
+	Missing  time.Duration // Time without any metrics.
+	Excluded time.Duration // Time left out, such as scheduled maintenance.
+}
+
+// ComputeSLA computes the availability of a check over the window from its
+// segments, weighted by the time each of the segments spans.
+func ComputeSLA(window Window, segments []Segment, opts *SLAOptions) SLA {
+	if opts == nil {
+		opts = &SLAOptions{}
+	}
+
+	sla := SLA{Window: window}
+	excluded := clipWindows(opts.Excluded, window)
+	for _, w := range excluded {
+		sla.Excluded += w.Duration()
+	}
+
+	var up, down float64
+	var known time.Duration
+	for _, s := range segments {
+		clipped := clip(s.Window, window)
+		if clipped.Duration() <= 0 {
+			continue
+		}
+
+		d := clipped.Duration()
+		for _, w := range excluded {
+			d -= clip(w, clipped).Duration()
+		}
+
+		known += d
+		up += float64(d) * s.Up
+		down += float64(d) * (1 - s.Up)
+	}
+
+	sla.Missing = window.Duration() - sla.Excluded - known
+	if sla.Missing < 0 {
+		sla.Missing = 0
+	}
+
+	switch opts.Missing {
+	case MissingDown:
+		down += float64(sla.Missing)
+	case MissingUp:
+		up += float64(sla.Missing)
+	}
+
+	sla.Up = time.Duration(up)
+	sla.Down = time.Duration(down)
+
+	sla.Availability = 100
+	if up+down > 0 {
+		sla.Availability = floorTo(up*100/(up+down), opts.Precision)
+	}
+
+	return sla
+}
+
+// clip returns the part of the window `w` within `within`.
+func clip(w, within Window) Window {
+	if w.Start.Before(within.Start) {
+		w.Start = within.Start
+	}
+
+	if w.End.After(within.End) {
+		w.End = within.End
+	}
+
+	if w.End.Before(w.Start) {
+		w.End = w.Start
+	}
+
+	return w
+}
+
+// clipWindows clips the windows within `within` and merges the ones that
+// overlap so that no time is counted twice.
+func clipWindows(windows []Window, within Window) []Window {
+	clipped := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		if c := clip(w, within); c.Duration() > 0 {
+			clipped = append(clipped, c)
+		}
+	}
+
+	sort.Slice(clipped, func(i, j int) bool { return clipped[i].Start.Before(clipped[j].Start) })
+
+	merged := make([]Window, 0, len(clipped))
+	for _, w := range clipped {
+		if n := len(merged); n > 0 && !w.Start.After(merged[n-1].End) {
+			if w.End.After(merged[n-1].End) {
+				merged[n-1].End = w.End
+			}
+			continue
+		}
+
+		merged = append(merged, w)
+	}
+
+	return merged
+}
+
+// floorTo rounds the value down to the given number of decimal places.
+func floorTo(value float64, precision int) float64 {
+	if precision < 0 {
+		precision = 0
+	}
+
+	p := math.Pow(10, float64(precision))
+
+	// the epsilon makes up for the error in representing the value, so that
+	// 99.95 is not rounded down to 99.94.
+	return math.Floor(value*p+1e-6) / p
+}